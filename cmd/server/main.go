@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/cache"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/objectstore"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/queue"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/server"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/ports"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	appConfig := config.NewConfig()
+	redisAddress, redisPassword, redisDB := appConfig.GetRedisParams()
+	redisCache := cache.NewRedisCache(redisAddress, redisPassword, redisDB)
+
+	store := openStorage(ctx, appConfig)
+	defer store.close()
+
+	linkService := services.NewLinkService(store.links, redisCache, store.history)
+	statsService := services.NewStatsService(store.stats, store.counters, redisCache)
+	apiKeyService := services.NewAPIKeyService(store.apiKeys, appConfig.GetAdminAPIKey())
+	policyService := services.NewPolicyService(openPolicyRules(ctx, appConfig), appConfig.GetShortDomains(), config.PolicyReloadInterval)
+	rateLimitService := services.NewRateLimitService(cache.NewRedisRateLimiter(redisCache, cache.NewMemoryRateLimiter()))
+	createLimit, redirectLimit := appConfig.GetRateLimitParams()
+
+	// Bulk jobs are processed in-process instead of by the SQS worker function
+	bulkQueue := queue.NewLocalBulkQueue()
+	bulkHandler := handlers.NewBulkGenerateFunctionHandler(linkService, policyService, services.NewBulkJobService(store.bulkJobs, bulkQueue, config.BulkJobRetention))
+	go bulkQueue.Run(ctx, bulkHandler.Process)
+
+	// Exports are written in-process too, to the export bucket when one is set
+	exportQueue := queue.NewLocalExportQueue()
+	exportHandler := handlers.NewExportFunctionHandler(linkService, statsService, services.NewExportService(openExportStore(appConfig), exportQueue, config.ExportRetention, config.ExportURLExpiry))
+	go exportQueue.Run(ctx, exportHandler.Process)
+
+	router := server.NewAPIRouter(server.Handlers{
+		Generate: handlers.NewGenerateLinkFunctionHandler(linkService, statsService, policyService),
+		Bulk:     bulkHandler,
+		Import:   handlers.NewImportFunctionHandler(linkService, policyService),
+		Redirect: handlers.NewRedirectFunctionHandler(linkService, statsService),
+		Stats:    handlers.NewStatsFunctionHandler(linkService, statsService, appConfig.GetCursorSecret()),
+		Export:   exportHandler,
+		Update:   handlers.NewUpdateLinkFunctionHandler(linkService, policyService),
+		History:  handlers.NewHistoryFunctionHandler(linkService),
+		Delete:   handlers.NewDeleteFunctionHandler(linkService, appConfig.GetDeletedLinkRetention()),
+		APIKeys:  handlers.NewAPIKeyFunctionHandler(apiKeyService),
+		Auth:     handlers.NewAuthenticator(apiKeyService),
+
+		RateLimiter:   handlers.NewRateLimiter(rateLimitService),
+		CreateLimit:   domain.RateLimit{Requests: createLimit, Window: config.RateLimitWindow},
+		RedirectLimit: domain.RateLimit{Requests: redirectLimit, Window: config.RateLimitWindow},
+		BulkLimit:     domain.RateLimit{Requests: appConfig.GetBulkRateLimit(), Window: config.BulkRateLimitWindow},
+	})
+
+	go runPurge(ctx, handlers.NewPurgeFunctionHandler(linkService, statsService, appConfig.GetDeletedLinkRetention()))
+
+	httpServer := &http.Server{
+		Addr:              appConfig.GetServerAddress(),
+		Handler:           router,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		log.Printf("Listening on %s", httpServer.Addr)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("server failed: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Print("Shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.MaxTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("server shutdown failed: %v", err)
+	}
+}
+
+// runPurge purges deleted links every PurgeInterval until ctx is done, like the scheduled purge function
+func runPurge(ctx context.Context, purge *handlers.PurgeFunctionHandler) {
+	ticker := time.NewTicker(config.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := purge.Purge(ctx)
+			if err != nil {
+				log.Printf("purge failed: %v", err)
+			}
+			if result.PurgedLinks > 0 || result.FailedLinks > 0 {
+				log.Printf("Purged %d deleted links and %d stats rows, %d failed", result.PurgedLinks, result.DeletedStats, result.FailedLinks)
+			}
+		}
+	}
+}
+
+// storage holds the repositories of the configured backend
+type storage struct {
+	links    ports.LinkPort
+	history  ports.LinkHistoryPort
+	stats    ports.StatsPort
+	counters ports.CounterPort
+	apiKeys  ports.APIKeyPort
+	bulkJobs ports.BulkJobPort
+	close    func()
+}
+
+// openStorage builds the repositories for the configured backend
+func openStorage(ctx context.Context, appConfig *config.AppConfig) storage {
+	backend, path := appConfig.GetStorageParams()
+
+	switch backend {
+	case config.StorageMemory:
+		log.Print("Using in-memory storage, data is lost on exit")
+		return storage{
+			links:    repository.NewMemoryLinkRepository(),
+			history:  repository.NewMemoryLinkHistoryRepository(),
+			stats:    repository.NewMemoryStatsRepository(),
+			counters: repository.NewMemoryCounterRepository(),
+			apiKeys:  repository.NewMemoryAPIKeyRepository(),
+			bulkJobs: repository.NewMemoryBulkJobRepository(),
+			close:    func() {},
+		}
+
+	case config.StorageFile:
+		store, err := repository.OpenFileStore(path)
+		if err != nil {
+			log.Fatalf("failed to open file store: %v", err)
+		}
+		log.Printf("Using file storage at %s", path)
+		return storage{
+			links:    store.LinkRepository(),
+			history:  store.LinkHistoryRepository(),
+			stats:    store.StatsRepository(),
+			counters: store.CounterRepository(),
+			apiKeys:  store.APIKeyRepository(),
+			bulkJobs: store.BulkJobRepository(),
+			close: func() {
+				if err := store.Close(); err != nil {
+					log.Printf("failed to close file store: %v", err)
+				}
+			},
+		}
+
+	case config.StorageDynamoDB:
+		linkRepo, err := repository.NewLinkRepository(ctx, appConfig.GetLinkTableName())
+		if err != nil {
+			log.Fatalf("failed to create link repository: %v", err)
+		}
+		historyRepo, err := repository.NewLinkHistoryRepository(ctx, appConfig.GetLinkHistoryTableName())
+		if err != nil {
+			log.Fatalf("failed to create link history repository: %v", err)
+		}
+		statsRepo, err := repository.NewStatsRepository(ctx, appConfig.GetStatsTableName())
+		if err != nil {
+			log.Fatalf("failed to create stats repository: %v", err)
+		}
+		counterRepo, err := repository.NewCounterRepository(ctx, appConfig.GetCounterTableName())
+		if err != nil {
+			log.Fatalf("failed to create counter repository: %v", err)
+		}
+		apiKeyRepo, err := repository.NewAPIKeyRepository(ctx, appConfig.GetAPIKeyTableName())
+		if err != nil {
+			log.Fatalf("failed to create API key repository: %v", err)
+		}
+		bulkJobRepo, err := repository.NewBulkJobRepository(ctx, appConfig.GetBulkJobTableName())
+		if err != nil {
+			log.Fatalf("failed to create bulk job repository: %v", err)
+		}
+		return storage{links: linkRepo, history: historyRepo, stats: statsRepo, counters: counterRepo, apiKeys: apiKeyRepo, bulkJobs: bulkJobRepo, close: func() {}}
+
+	default:
+		log.Fatalf("unknown storage backend %q", backend)
+		return storage{}
+	}
+}
+
+// openPolicyRules reads the URL policy from PolicyFile when set, otherwise from the policy
+// table with the DynamoDB backend. Local backends without a file have no rules
+func openPolicyRules(ctx context.Context, appConfig *config.AppConfig) ports.PolicyRulePort {
+	if policyFile := appConfig.GetPolicyFile(); policyFile != "" {
+		log.Printf("Using policy rules from %s", policyFile)
+		return repository.NewFilePolicyRuleRepository(policyFile)
+	}
+
+	if backend, _ := appConfig.GetStorageParams(); backend != config.StorageDynamoDB {
+		return repository.NewMemoryPolicyRuleRepository()
+	}
+	policyRepo, err := repository.NewPolicyRuleRepository(ctx, appConfig.GetPolicyTableName())
+	if err != nil {
+		log.Fatalf("failed to create policy rule repository: %v", err)
+	}
+	return policyRepo
+}
+
+// openExportStore writes export files to ExportBucket when set, otherwise under ExportDir
+func openExportStore(appConfig *config.AppConfig) ports.ObjectStore {
+	bucket, endpoint, dir := appConfig.GetExportStoreParams()
+	if bucket == "" {
+		log.Printf("Using export directory %s", dir)
+		return objectstore.NewLocalObjectStore(dir)
+	}
+
+	store, err := objectstore.NewS3ObjectStore(bucket, endpoint)
+	if err != nil {
+		log.Fatalf("failed to create export store: %v", err)
+	}
+	return store
+}
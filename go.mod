@@ -15,6 +15,7 @@ require (
 	github.com/joho/godotenv v1.5.1
 	github.com/slack-go/slack v0.12.3
 	github.com/stretchr/testify v1.7.5
+	golang.org/x/crypto v0.17.0
 )
 
 require (
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+)
+
+// MemoryRateLimiter is a sliding-window log kept in process memory. It's the fallback
+// when Redis is down, and limits only the requests served by the current process.
+type MemoryRateLimiter struct {
+	mu        sync.Mutex
+	windows   map[string]*slidingWindow
+	lastSweep time.Time
+}
+
+type slidingWindow struct {
+	hits   []time.Time
+	window time.Duration
+}
+
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{windows: make(map[string]*slidingWindow), lastSweep: time.Now()}
+}
+
+func (m *MemoryRateLimiter) Allow(ctx context.Context, key string, limit domain.RateLimit) (bool, time.Duration, error) {
+	if limit.IsUnlimited() {
+		return true, 0, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.sweep(now)
+
+	w, ok := m.windows[key]
+	if !ok {
+		w = &slidingWindow{}
+		m.windows[key] = w
+	}
+	w.window = limit.Window
+
+	// Drop the hits that left the window
+	start := now.Add(-limit.Window)
+	kept := w.hits[:0]
+	for _, hit := range w.hits {
+		if hit.After(start) {
+			kept = append(kept, hit)
+		}
+	}
+	w.hits = kept
+
+	if len(w.hits) >= limit.Requests {
+		return false, w.hits[len(w.hits)-limit.Requests].Add(limit.Window).Sub(now), nil
+	}
+	w.hits = append(w.hits, now)
+	return true, 0, nil
+}
+
+// sweep forgets clients without hits in their window, at most once a minute
+func (m *MemoryRateLimiter) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < time.Minute {
+		return
+	}
+	m.lastSweep = now
+	for key, w := range m.windows {
+		if len(w.hits) == 0 || !w.hits[len(w.hits)-1].Add(w.window).After(now) {
+			delete(m.windows, key)
+		}
+	}
+}
@@ -2,12 +2,23 @@ package cache
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 	"github.com/go-redis/redis/v8"
 )
 
+// incrementScript adds one to a counter, setting its expiry (ms) only when it's created
+var incrementScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`)
+
 type RedisCache struct {
 	client *redis.Client
 	ttl    time.Duration
@@ -45,11 +56,20 @@ func (r *RedisCache) Set(ctx context.Context, key string, val string) error {
 	return r.client.Set(ctx, fullKey, val, r.ttl).Err()
 }
 
+// SetWithTTL stores the value for at most ttl, capped by the cache's default TTL
+func (r *RedisCache) SetWithTTL(ctx context.Context, key string, val string, ttl time.Duration) error {
+	if ttl <= 0 || ttl > r.ttl {
+		ttl = r.ttl
+	}
+	fullKey := config.CacheKeyPrefix + key
+	return r.client.Set(ctx, fullKey, val, ttl).Err()
+}
+
 func (r *RedisCache) Get(ctx context.Context, key string) (string, error) {
 	fullKey := config.CacheKeyPrefix + key
 	val, err := r.client.Get(ctx, fullKey).Result()
 	if err == redis.Nil {
-		return "", nil
+		return "", fmt.Errorf("key '%s': %w", key, domain.ErrNotFound)
 	}
 	return val, err
 }
@@ -59,6 +79,11 @@ func (r *RedisCache) Delete(ctx context.Context, key string) error {
 	return r.client.Del(ctx, fullKey).Err()
 }
 
+func (r *RedisCache) Increment(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	fullKey := config.CacheKeyPrefix + key
+	return incrementScript.Run(ctx, r.client, []string{fullKey}, ttl.Milliseconds()).Int64()
+}
+
 func (r *RedisCache) Ping(ctx context.Context) error {
 	return r.client.Ping(ctx).Err()
 }
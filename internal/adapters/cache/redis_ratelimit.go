@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/ports"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// slidingWindowScript keeps one sorted set of request timestamps (ms) per client, atomically
+// dropping the expired ones and adding the new one if the client is under the limit.
+// It returns {1, 0} when allowed and {0, ms until a slot frees up} otherwise.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	redis.call('PEXPIRE', key, window)
+	return {1, 0}
+end
+
+local oldest = redis.call('ZRANGE', key, count - limit, count - limit, 'WITHSCORES')
+return {0, tonumber(oldest[2]) + window - now}
+`)
+
+// RedisRateLimiter is a sliding-window limiter shared by every function through Redis,
+// falling back to a per-process limiter while Redis is unreachable
+type RedisRateLimiter struct {
+	client   *redis.Client
+	fallback ports.RateLimiter
+}
+
+func NewRedisRateLimiter(r *RedisCache, fallback ports.RateLimiter) *RedisRateLimiter {
+	return &RedisRateLimiter{client: r.client, fallback: fallback}
+}
+
+func (r *RedisRateLimiter) Allow(ctx context.Context, key string, limit domain.RateLimit) (bool, time.Duration, error) {
+	if limit.IsUnlimited() {
+		return true, 0, nil
+	}
+
+	allowed, retryAfter, err := r.allow(ctx, key, limit)
+	if err != nil {
+		log.Printf("Rate limiter falling back to memory for key '%s': %v", key, err)
+		return r.fallback.Allow(ctx, key, limit)
+	}
+	return allowed, retryAfter, nil
+}
+
+func (r *RedisRateLimiter) allow(ctx context.Context, key string, limit domain.RateLimit) (bool, time.Duration, error) {
+	fullKey := config.RateLimitKeyPrefix + key
+	now := time.Now().UnixMilli()
+
+	result, err := slidingWindowScript.Run(ctx, r.client, []string{fullKey},
+		now, limit.Window.Milliseconds(), limit.Requests, strconv.FormatInt(now, 10)+":"+uuid.NewString()).Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if len(result) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limiter result %v", result)
+	}
+
+	allowed, _ := result[0].(int64)
+	retryAfter, _ := result[1].(int64)
+	return allowed == 1, time.Duration(retryAfter) * time.Millisecond, nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	ctx := context.Background()
+	appConfig := config.NewConfig()
+	apiKeyTableName := appConfig.GetAPIKeyTableName()
+
+	apiKeyRepo, err := repository.NewAPIKeyRepository(ctx, apiKeyTableName)
+	if err != nil {
+		log.Fatalf("failed to create API key repository: %v", err)
+	}
+
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, appConfig.GetAdminAPIKey())
+	auth := handlers.NewAuthenticator(apiKeyService)
+	handler := handlers.NewAPIKeyFunctionHandler(apiKeyService)
+
+	lambda.Start(auth.RequireScope(domain.ScopeAdmin, handler.Handle))
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/cache"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/queue"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/ports"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	ctx := context.Background()
+	appConfig := config.NewConfig()
+	redisAddress, redisPassword, redisDB := appConfig.GetRedisParams()
+	redisCache := cache.NewRedisCache(redisAddress, redisPassword, redisDB)
+
+	linkRepo, err := repository.NewLinkRepository(ctx, appConfig.GetLinkTableName())
+	if err != nil {
+		log.Fatalf("failed to create link repository: %v", err)
+	}
+	historyRepo, err := repository.NewLinkHistoryRepository(ctx, appConfig.GetLinkHistoryTableName())
+	if err != nil {
+		log.Fatalf("failed to create link history repository: %v", err)
+	}
+	linkService := services.NewLinkService(linkRepo, redisCache, historyRepo)
+
+	bulkJobRepo, err := repository.NewBulkJobRepository(ctx, appConfig.GetBulkJobTableName())
+	if err != nil {
+		log.Fatalf("failed to create bulk job repository: %v", err)
+	}
+	bulkQueue, err := queue.NewSQSBulkQueue(ctx, appConfig.GetBulkQueueURL())
+	if err != nil {
+		log.Fatalf("failed to create bulk queue: %v", err)
+	}
+	bulkService := services.NewBulkJobService(bulkJobRepo, bulkQueue, config.BulkJobRetention)
+
+	apiKeyRepo, err := repository.NewAPIKeyRepository(ctx, appConfig.GetAPIKeyTableName())
+	if err != nil {
+		log.Fatalf("failed to create API key repository: %v", err)
+	}
+
+	rateLimiter := handlers.NewRateLimiter(services.NewRateLimitService(cache.NewRedisRateLimiter(redisCache, cache.NewMemoryRateLimiter())))
+	limit := domain.RateLimit{Requests: appConfig.GetBulkRateLimit(), Window: config.BulkRateLimitWindow}
+
+	var policyRepo ports.PolicyRulePort
+	if policyFile := appConfig.GetPolicyFile(); policyFile != "" {
+		policyRepo = repository.NewFilePolicyRuleRepository(policyFile)
+	} else {
+		policyRepo, err = repository.NewPolicyRuleRepository(ctx, appConfig.GetPolicyTableName())
+		if err != nil {
+			log.Fatalf("failed to create policy rule repository: %v", err)
+		}
+	}
+	policyService := services.NewPolicyService(policyRepo, appConfig.GetShortDomains(), config.PolicyReloadInterval)
+
+	handler := handlers.NewBulkGenerateFunctionHandler(linkService, policyService, bulkService)
+	auth := handlers.NewAuthenticator(services.NewAPIKeyService(apiKeyRepo, appConfig.GetAdminAPIKey()))
+
+	// Only creation counts against the rate limit, polling a job's status doesn't
+	create := rateLimiter.Limit(handlers.RateLimitBulk, limit, handler.CreateBulk)
+	lambda.Start(auth.RequireScope(domain.ScopeCreate, func(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+		if req.RequestContext.HTTP.Method == http.MethodGet {
+			return handler.JobStatus(ctx, req)
+		}
+		return create(ctx, req)
+	}))
+}
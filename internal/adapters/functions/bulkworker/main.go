@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/cache"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/ports"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	ctx := context.Background()
+	appConfig := config.NewConfig()
+	redisAddress, redisPassword, redisDB := appConfig.GetRedisParams()
+	redisCache := cache.NewRedisCache(redisAddress, redisPassword, redisDB)
+
+	linkRepo, err := repository.NewLinkRepository(ctx, appConfig.GetLinkTableName())
+	if err != nil {
+		log.Fatalf("failed to create link repository: %v", err)
+	}
+	historyRepo, err := repository.NewLinkHistoryRepository(ctx, appConfig.GetLinkHistoryTableName())
+	if err != nil {
+		log.Fatalf("failed to create link history repository: %v", err)
+	}
+	linkService := services.NewLinkService(linkRepo, redisCache, historyRepo)
+
+	bulkJobRepo, err := repository.NewBulkJobRepository(ctx, appConfig.GetBulkJobTableName())
+	if err != nil {
+		log.Fatalf("failed to create bulk job repository: %v", err)
+	}
+	// The worker only records results, it never queues chunks
+	bulkService := services.NewBulkJobService(bulkJobRepo, nil, config.BulkJobRetention)
+
+	var policyRepo ports.PolicyRulePort
+	if policyFile := appConfig.GetPolicyFile(); policyFile != "" {
+		policyRepo = repository.NewFilePolicyRuleRepository(policyFile)
+	} else {
+		policyRepo, err = repository.NewPolicyRuleRepository(ctx, appConfig.GetPolicyTableName())
+		if err != nil {
+			log.Fatalf("failed to create policy rule repository: %v", err)
+		}
+	}
+	policyService := services.NewPolicyService(policyRepo, appConfig.GetShortDomains(), config.PolicyReloadInterval)
+
+	handler := handlers.NewBulkGenerateFunctionHandler(linkService, policyService, bulkService)
+	lambda.Start(handler.ProcessChunks)
+}
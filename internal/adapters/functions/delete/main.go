@@ -8,6 +8,7 @@ import (
 	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
 	"github.com/aws/aws-lambda-go/lambda"
 )
@@ -17,7 +18,7 @@ func main() {
 	appConfig := config.NewConfig()
 	redisAddress, redisPassword, redisDB := appConfig.GetRedisParams()
 	linkTableName := appConfig.GetLinkTableName()
-	statsTableName := appConfig.GetStatsTableName()
+	apiKeyTableName := appConfig.GetAPIKeyTableName()
 
 	cache := cache.NewRedisCache(redisAddress, redisPassword, redisDB)
 
@@ -25,15 +26,20 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to create link repository: %v", err)
 	}
-	statsRepo, err := repository.NewStatsRepository(ctx, statsTableName)
+	historyRepo, err := repository.NewLinkHistoryRepository(ctx, appConfig.GetLinkHistoryTableName())
 	if err != nil {
-		log.Fatalf("failed to create stats repository: %v", err)
+		log.Fatalf("failed to create link history repository: %v", err)
 	}
+	apiKeyRepo, err := repository.NewAPIKeyRepository(ctx, apiKeyTableName)
+	if err != nil {
+		log.Fatalf("failed to create API key repository: %v", err)
+	}
+
+	linkService := services.NewLinkService(linkRepo, cache, historyRepo)
 
-	linkService := services.NewLinkService(linkRepo, cache)
-	statsService := services.NewStatsService(statsRepo, cache)
+	handler := handlers.NewDeleteFunctionHandler(linkService, appConfig.GetDeletedLinkRetention())
 
-	handler := handlers.NewDeleteFunctionHandler(linkService, statsService)
+	auth := handlers.NewAuthenticator(services.NewAPIKeyService(apiKeyRepo, appConfig.GetAdminAPIKey()))
 
-	lambda.Start(handler.Delete)
+	lambda.Start(auth.RequireScope(domain.ScopeDelete, handler.Handle))
 }
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/cache"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/objectstore"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/queue"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	ctx := context.Background()
+	appConfig := config.NewConfig()
+	redisAddress, redisPassword, redisDB := appConfig.GetRedisParams()
+	cache := cache.NewRedisCache(redisAddress, redisPassword, redisDB)
+
+	linkRepo, err := repository.NewLinkRepository(ctx, appConfig.GetLinkTableName())
+	if err != nil {
+		log.Fatalf("failed to create link repository: %v", err)
+	}
+	historyRepo, err := repository.NewLinkHistoryRepository(ctx, appConfig.GetLinkHistoryTableName())
+	if err != nil {
+		log.Fatalf("failed to create link history repository: %v", err)
+	}
+	statsRepo, err := repository.NewStatsRepository(ctx, appConfig.GetStatsTableName())
+	if err != nil {
+		log.Fatalf("failed to create stats repository: %v", err)
+	}
+	counterRepo, err := repository.NewCounterRepository(ctx, appConfig.GetCounterTableName())
+	if err != nil {
+		log.Fatalf("failed to create counter repository: %v", err)
+	}
+	apiKeyRepo, err := repository.NewAPIKeyRepository(ctx, appConfig.GetAPIKeyTableName())
+	if err != nil {
+		log.Fatalf("failed to create API key repository: %v", err)
+	}
+
+	bucket, endpoint, _ := appConfig.GetExportStoreParams()
+	exportStore, err := objectstore.NewS3ObjectStore(bucket, endpoint)
+	if err != nil {
+		log.Fatalf("failed to create export store: %v", err)
+	}
+	exportQueue, err := queue.NewSQSExportQueue(ctx, appConfig.GetExportQueueURL())
+	if err != nil {
+		log.Fatalf("failed to create export queue: %v", err)
+	}
+
+	linkService := services.NewLinkService(linkRepo, cache, historyRepo)
+	statsService := services.NewStatsService(statsRepo, counterRepo, cache)
+	exportService := services.NewExportService(exportStore, exportQueue, config.ExportRetention, config.ExportURLExpiry)
+
+	handler := handlers.NewExportFunctionHandler(linkService, statsService, exportService)
+
+	auth := handlers.NewAuthenticator(services.NewAPIKeyService(apiKeyRepo, appConfig.GetAdminAPIKey()))
+
+	lambda.Start(auth.RequireScope(domain.ScopeReadStats, handler.Handle))
+}
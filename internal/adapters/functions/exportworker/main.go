@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/cache"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/objectstore"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	ctx := context.Background()
+	appConfig := config.NewConfig()
+	redisAddress, redisPassword, redisDB := appConfig.GetRedisParams()
+	cache := cache.NewRedisCache(redisAddress, redisPassword, redisDB)
+
+	linkRepo, err := repository.NewLinkRepository(ctx, appConfig.GetLinkTableName())
+	if err != nil {
+		log.Fatalf("failed to create link repository: %v", err)
+	}
+	historyRepo, err := repository.NewLinkHistoryRepository(ctx, appConfig.GetLinkHistoryTableName())
+	if err != nil {
+		log.Fatalf("failed to create link history repository: %v", err)
+	}
+	statsRepo, err := repository.NewStatsRepository(ctx, appConfig.GetStatsTableName())
+	if err != nil {
+		log.Fatalf("failed to create stats repository: %v", err)
+	}
+	counterRepo, err := repository.NewCounterRepository(ctx, appConfig.GetCounterTableName())
+	if err != nil {
+		log.Fatalf("failed to create counter repository: %v", err)
+	}
+
+	bucket, endpoint, _ := appConfig.GetExportStoreParams()
+	exportStore, err := objectstore.NewS3ObjectStore(bucket, endpoint)
+	if err != nil {
+		log.Fatalf("failed to create export store: %v", err)
+	}
+
+	linkService := services.NewLinkService(linkRepo, cache, historyRepo)
+	statsService := services.NewStatsService(statsRepo, counterRepo, cache)
+	// The worker only writes files, it never queues jobs
+	exportService := services.NewExportService(exportStore, nil, config.ExportRetention, config.ExportURLExpiry)
+
+	handler := handlers.NewExportFunctionHandler(linkService, statsService, exportService)
+	lambda.Start(handler.ProcessJobs)
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/ports"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
 	"github.com/aws/aws-lambda-go/lambda"
 )
@@ -16,22 +18,53 @@ func main() {
 	ctx := context.Background()
 	appConfig := config.NewConfig()
 	redisAddress, redisPassword, redisDB := appConfig.GetRedisParams()
-	cache := cache.NewRedisCache(redisAddress, redisPassword, redisDB)
+	redisCache := cache.NewRedisCache(redisAddress, redisPassword, redisDB)
 	linkTableName := appConfig.GetLinkTableName()
 	statsTableName := appConfig.GetStatsTableName()
+	counterTableName := appConfig.GetCounterTableName()
+	apiKeyTableName := appConfig.GetAPIKeyTableName()
 
 	linkRepo, err := repository.NewLinkRepository(ctx, linkTableName)
 	if err != nil {
 		log.Fatalf("failed to create link repository: %v", err)
 	}
-	linkService := services.NewLinkService(linkRepo, cache)
+	historyRepo, err := repository.NewLinkHistoryRepository(ctx, appConfig.GetLinkHistoryTableName())
+	if err != nil {
+		log.Fatalf("failed to create link history repository: %v", err)
+	}
+	linkService := services.NewLinkService(linkRepo, redisCache, historyRepo)
 
 	statsRepo, err := repository.NewStatsRepository(ctx, statsTableName)
 	if err != nil {
 		log.Fatalf("failed to create stats repository: %v", err)
 	}
-	statsService := services.NewStatsService(statsRepo, cache)
+	counterRepo, err := repository.NewCounterRepository(ctx, counterTableName)
+	if err != nil {
+		log.Fatalf("failed to create counter repository: %v", err)
+	}
+	apiKeyRepo, err := repository.NewAPIKeyRepository(ctx, apiKeyTableName)
+	if err != nil {
+		log.Fatalf("failed to create API key repository: %v", err)
+	}
+	statsService := services.NewStatsService(statsRepo, counterRepo, redisCache)
+
+	createLimit, _ := appConfig.GetRateLimitParams()
+	rateLimiter := handlers.NewRateLimiter(services.NewRateLimitService(cache.NewRedisRateLimiter(redisCache, cache.NewMemoryRateLimiter())))
+	limit := domain.RateLimit{Requests: createLimit, Window: config.RateLimitWindow}
+
+	var policyRepo ports.PolicyRulePort
+	if policyFile := appConfig.GetPolicyFile(); policyFile != "" {
+		policyRepo = repository.NewFilePolicyRuleRepository(policyFile)
+	} else {
+		policyRepo, err = repository.NewPolicyRuleRepository(ctx, appConfig.GetPolicyTableName())
+		if err != nil {
+			log.Fatalf("failed to create policy rule repository: %v", err)
+		}
+	}
+	policyService := services.NewPolicyService(policyRepo, appConfig.GetShortDomains(), config.PolicyReloadInterval)
+
+	handler := handlers.NewGenerateLinkFunctionHandler(linkService, statsService, policyService)
+	auth := handlers.NewAuthenticator(services.NewAPIKeyService(apiKeyRepo, appConfig.GetAdminAPIKey()))
 
-	handler := handlers.NewGenerateLinkFunctionHandler(linkService, statsService)
-	lambda.Start(handler.CreateShortLink)
+	lambda.Start(auth.RequireScope(domain.ScopeCreate, rateLimiter.Limit(handlers.RateLimitCreate, limit, handler.CreateShortLink)))
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/cache"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/ports"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	ctx := context.Background()
+	appConfig := config.NewConfig()
+	redisAddress, redisPassword, redisDB := appConfig.GetRedisParams()
+	redisCache := cache.NewRedisCache(redisAddress, redisPassword, redisDB)
+
+	linkRepo, err := repository.NewLinkRepository(ctx, appConfig.GetLinkTableName())
+	if err != nil {
+		log.Fatalf("failed to create link repository: %v", err)
+	}
+	historyRepo, err := repository.NewLinkHistoryRepository(ctx, appConfig.GetLinkHistoryTableName())
+	if err != nil {
+		log.Fatalf("failed to create link history repository: %v", err)
+	}
+	linkService := services.NewLinkService(linkRepo, redisCache, historyRepo)
+
+	apiKeyRepo, err := repository.NewAPIKeyRepository(ctx, appConfig.GetAPIKeyTableName())
+	if err != nil {
+		log.Fatalf("failed to create API key repository: %v", err)
+	}
+
+	rateLimiter := handlers.NewRateLimiter(services.NewRateLimitService(cache.NewRedisRateLimiter(redisCache, cache.NewMemoryRateLimiter())))
+	limit := domain.RateLimit{Requests: appConfig.GetBulkRateLimit(), Window: config.BulkRateLimitWindow}
+
+	var policyRepo ports.PolicyRulePort
+	if policyFile := appConfig.GetPolicyFile(); policyFile != "" {
+		policyRepo = repository.NewFilePolicyRuleRepository(policyFile)
+	} else {
+		policyRepo, err = repository.NewPolicyRuleRepository(ctx, appConfig.GetPolicyTableName())
+		if err != nil {
+			log.Fatalf("failed to create policy rule repository: %v", err)
+		}
+	}
+	policyService := services.NewPolicyService(policyRepo, appConfig.GetShortDomains(), config.PolicyReloadInterval)
+
+	handler := handlers.NewImportFunctionHandler(linkService, policyService)
+	auth := handlers.NewAuthenticator(services.NewAPIKeyService(apiKeyRepo, appConfig.GetAdminAPIKey()))
+
+	lambda.Start(auth.RequireScope(domain.ScopeCreate, rateLimiter.Limit(handlers.RateLimitBulk, limit, handler.Import)))
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/cache"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	ctx := context.Background()
+	appConfig := config.NewConfig()
+	redisAddress, redisPassword, redisDB := appConfig.GetRedisParams()
+	linkTableName := appConfig.GetLinkTableName()
+	statsTableName := appConfig.GetStatsTableName()
+	counterTableName := appConfig.GetCounterTableName()
+
+	cache := cache.NewRedisCache(redisAddress, redisPassword, redisDB)
+
+	linkRepo, err := repository.NewLinkRepository(ctx, linkTableName)
+	if err != nil {
+		log.Fatalf("failed to create link repository: %v", err)
+	}
+	historyRepo, err := repository.NewLinkHistoryRepository(ctx, appConfig.GetLinkHistoryTableName())
+	if err != nil {
+		log.Fatalf("failed to create link history repository: %v", err)
+	}
+	statsRepo, err := repository.NewStatsRepository(ctx, statsTableName)
+	if err != nil {
+		log.Fatalf("failed to create stats repository: %v", err)
+	}
+	counterRepo, err := repository.NewCounterRepository(ctx, counterTableName)
+	if err != nil {
+		log.Fatalf("failed to create counter repository: %v", err)
+	}
+
+	linkService := services.NewLinkService(linkRepo, cache, historyRepo)
+	statsService := services.NewStatsService(statsRepo, counterRepo, cache)
+
+	handler := handlers.NewPurgeFunctionHandler(linkService, statsService, appConfig.GetDeletedLinkRetention())
+
+	lambda.Start(handler.Purge)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
 	"github.com/aws/aws-lambda-go/lambda"
 )
@@ -16,23 +17,36 @@ func main() {
 	ctx := context.Background()
 	appConfig := config.NewConfig()
 	redisAddress, redisPassword, redisDB := appConfig.GetRedisParams()
-	cache := cache.NewRedisCache(redisAddress, redisPassword, redisDB)
+	redisCache := cache.NewRedisCache(redisAddress, redisPassword, redisDB)
 	linkTableName := appConfig.GetLinkTableName()
 	statsTableName := appConfig.GetStatsTableName()
+	counterTableName := appConfig.GetCounterTableName()
 
 	linkRepo, err := repository.NewLinkRepository(ctx, linkTableName)
 	if err != nil {
 		log.Fatalf("failed to create link repository: %v", err)
 	}
-	linkService := services.NewLinkService(linkRepo, cache)
+	historyRepo, err := repository.NewLinkHistoryRepository(ctx, appConfig.GetLinkHistoryTableName())
+	if err != nil {
+		log.Fatalf("failed to create link history repository: %v", err)
+	}
+	linkService := services.NewLinkService(linkRepo, redisCache, historyRepo)
 
 	statsRepo, err := repository.NewStatsRepository(ctx, statsTableName)
 	if err != nil {
 		log.Fatalf("failed to create stats repository: %v", err)
 	}
-	statsService := services.NewStatsService(statsRepo, cache)
+	counterRepo, err := repository.NewCounterRepository(ctx, counterTableName)
+	if err != nil {
+		log.Fatalf("failed to create counter repository: %v", err)
+	}
+	statsService := services.NewStatsService(statsRepo, counterRepo, redisCache)
+
+	_, redirectLimit := appConfig.GetRateLimitParams()
+	rateLimiter := handlers.NewRateLimiter(services.NewRateLimitService(cache.NewRedisRateLimiter(redisCache, cache.NewMemoryRateLimiter())))
+	limit := domain.RateLimit{Requests: redirectLimit, Window: config.RateLimitWindow}
 
 	handler := handlers.NewRedirectFunctionHandler(linkService, statsService)
 
-	lambda.Start(handler.Redirect)
+	lambda.Start(rateLimiter.Limit(handlers.RateLimitRedirect, limit, handler.Redirect))
 }
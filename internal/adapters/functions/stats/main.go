@@ -8,6 +8,7 @@ import (
 	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
 	"github.com/aws/aws-lambda-go/lambda"
 )
@@ -18,6 +19,8 @@ func main() {
 	redisAddress, redisPassword, redisDB := appConfig.GetRedisParams()
 	linkTableName := appConfig.GetLinkTableName()
 	statsTableName := appConfig.GetStatsTableName()
+	counterTableName := appConfig.GetCounterTableName()
+	apiKeyTableName := appConfig.GetAPIKeyTableName()
 
 	cache := cache.NewRedisCache(redisAddress, redisPassword, redisDB)
 
@@ -25,15 +28,29 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to create link repository: %v", err)
 	}
+	historyRepo, err := repository.NewLinkHistoryRepository(ctx, appConfig.GetLinkHistoryTableName())
+	if err != nil {
+		log.Fatalf("failed to create link history repository: %v", err)
+	}
 	statsRepo, err := repository.NewStatsRepository(ctx, statsTableName)
 	if err != nil {
 		log.Fatalf("failed to create stats repository: %v", err)
 	}
+	counterRepo, err := repository.NewCounterRepository(ctx, counterTableName)
+	if err != nil {
+		log.Fatalf("failed to create counter repository: %v", err)
+	}
+	apiKeyRepo, err := repository.NewAPIKeyRepository(ctx, apiKeyTableName)
+	if err != nil {
+		log.Fatalf("failed to create API key repository: %v", err)
+	}
+
+	linkService := services.NewLinkService(linkRepo, cache, historyRepo)
+	statsService := services.NewStatsService(statsRepo, counterRepo, cache)
 
-	linkService := services.NewLinkService(linkRepo, cache)
-	statsService := services.NewStatsService(statsRepo, cache)
+	handler := handlers.NewStatsFunctionHandler(linkService, statsService, appConfig.GetCursorSecret())
 
-	handler := handlers.NewStatsFunctionHandler(linkService, statsService)
+	auth := handlers.NewAuthenticator(services.NewAPIKeyService(apiKeyRepo, appConfig.GetAdminAPIKey()))
 
-	lambda.Start(handler.Stats)
+	lambda.Start(auth.RequireScope(domain.ScopeReadStats, handler.Stats))
 }
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type APIKeyFunctionHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+func NewAPIKeyFunctionHandler(k *services.APIKeyService) *APIKeyFunctionHandler {
+	return &APIKeyFunctionHandler{apiKeyService: k}
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string         `json:"name"`
+	Workspace string         `json:"workspace"`
+	Scopes    []domain.Scope `json:"scopes"`
+}
+
+// CreateAPIKeyResponse is the only time the token is returned
+type CreateAPIKeyResponse struct {
+	domain.APIKey
+	Token string `json:"token"`
+}
+
+// Handle dispatches the /admin/keys routes served by a single Lambda function
+func (h *APIKeyFunctionHandler) Handle(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	switch req.RequestContext.HTTP.Method {
+	case http.MethodPost:
+		return h.Create(ctx, req)
+	case http.MethodGet:
+		return h.List(ctx, req)
+	case http.MethodDelete:
+		return h.Revoke(ctx, req)
+	default:
+		return ClientError(http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (h *APIKeyFunctionHandler) Create(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, config.DefaultTimeout)
+	defer cancel()
+
+	var requestBody CreateAPIKeyRequest
+	if err := json.Unmarshal([]byte(req.Body), &requestBody); err != nil {
+		return ClientError(http.StatusBadRequest, "Invalid JSON")
+	}
+	if requestBody.Name == "" {
+		return ClientError(http.StatusBadRequest, "Name cannot be empty")
+	}
+	if requestBody.Workspace == "" {
+		return ClientError(http.StatusBadRequest, "Workspace cannot be empty")
+	}
+	if len(requestBody.Scopes) == 0 {
+		return ClientError(http.StatusBadRequest, "At least one scope is required")
+	}
+	for _, scope := range requestBody.Scopes {
+		if !scope.IsValid() {
+			return ClientError(http.StatusBadRequest, fmt.Sprintf("Unknown scope '%s'", scope))
+		}
+	}
+
+	key, token, err := h.apiKeyService.Create(timeoutCtx, requestBody.Name, requestBody.Workspace, requestBody.Scopes)
+	if err != nil {
+		return ServerError(err)
+	}
+
+	jsonResponse, err := json.Marshal(CreateAPIKeyResponse{APIKey: key, Token: token})
+	if err != nil {
+		return ServerError(err)
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusCreated,
+		Body:       string(jsonResponse),
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+	}, nil
+}
+
+func (h *APIKeyFunctionHandler) List(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, config.DefaultTimeout)
+	defer cancel()
+
+	keys, err := h.apiKeyService.GetAll(timeoutCtx)
+	if err != nil {
+		return ServerError(err)
+	}
+	if keys == nil {
+		keys = []domain.APIKey{}
+	}
+
+	jsonResponse, err := json.Marshal(keys)
+	if err != nil {
+		return ServerError(err)
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(jsonResponse),
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+	}, nil
+}
+
+func (h *APIKeyFunctionHandler) Revoke(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, config.DefaultTimeout)
+	defer cancel()
+
+	id := req.PathParameters["id"]
+	if id == "" {
+		return ClientError(http.StatusBadRequest, "ID parameter is required")
+	}
+
+	err := h.apiKeyService.Revoke(timeoutCtx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return ClientError(http.StatusNotFound, "API key not found")
+	}
+	if err != nil {
+		return ServerError(err)
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusNoContent,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type apiKeyContextKey struct{}
+
+// WithAPIKey returns a copy of ctx carrying the authenticated API key
+func WithAPIKey(ctx context.Context, key domain.APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKeyFromContext returns the API key the request was authenticated with
+func APIKeyFromContext(ctx context.Context) (domain.APIKey, bool) {
+	key, ok := ctx.Value(apiKeyContextKey{}).(domain.APIKey)
+	return key, ok
+}
+
+// OwnerFromContext returns the workspace of the authenticated API key, which owns the links the request
+// creates and is the only one allowed to see them. Requests that didn't go through RequireScope get the
+// empty workspace of links created before workspaces existed
+func OwnerFromContext(ctx context.Context) string {
+	key, _ := APIKeyFromContext(ctx)
+	return key.Workspace
+}
+
+// apiKeyID returns the id of the authenticated API key, recorded as the author of link changes
+func apiKeyID(ctx context.Context) string {
+	key, _ := APIKeyFromContext(ctx)
+	return key.Id
+}
+
+type Authenticator struct {
+	apiKeyService *services.APIKeyService
+}
+
+func NewAuthenticator(k *services.APIKeyService) *Authenticator {
+	return &Authenticator{apiKeyService: k}
+}
+
+// RequireScope wraps next so it only runs for requests carrying an API key with the given scope,
+// the key is available to next through APIKeyFromContext
+func (a *Authenticator) RequireScope(scope domain.Scope, next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+		token := APIKeyFromRequest(req)
+		if token == "" {
+			return ClientError(http.StatusUnauthorized, "API key is required")
+		}
+
+		key, err := a.apiKeyService.Authenticate(ctx, token)
+		if err != nil {
+			return ErrorResponse(err)
+		}
+		if !key.HasScope(scope) {
+			return ClientError(http.StatusForbidden, fmt.Sprintf("API key lacks the '%s' scope", scope))
+		}
+
+		return next(WithAPIKey(ctx, key), req)
+	}
+}
+
+// APIKeyFromRequest reads the token from the "Authorization: Bearer" or "X-API-Key" header.
+// API Gateway lowercases header names
+func APIKeyFromRequest(req events.APIGatewayV2HTTPRequest) string {
+	if authorization := req.Headers["authorization"]; authorization != "" {
+		scheme, token, ok := strings.Cut(authorization, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return strings.TrimSpace(req.Headers["x-api-key"])
+}
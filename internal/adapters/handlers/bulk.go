@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// BulkResponse is the result of a bulk request processed synchronously
+type BulkResponse struct {
+	Total   int                     `json:"total"`
+	Created int                     `json:"created"`
+	Failed  int                     `json:"failed"`
+	Results []domain.BulkItemResult `json:"results"`
+}
+
+type BulkGenerateFunctionHandler struct {
+	linkService   *services.LinkService
+	policyService *services.PolicyService
+	bulkService   *services.BulkJobService
+}
+
+func NewBulkGenerateFunctionHandler(l *services.LinkService, p *services.PolicyService, b *services.BulkJobService) *BulkGenerateFunctionHandler {
+	return &BulkGenerateFunctionHandler{linkService: l, policyService: p, bulkService: b}
+}
+
+// CreateBulk creates the links of a JSON array or CSV body. Up to config.BulkSyncLimit links are created
+// right away and reported per item, larger requests are queued as a job whose status URL is returned
+func (h *BulkGenerateFunctionHandler) CreateBulk(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	// Add context timeout, a batch takes longer than a single link
+	timeoutCtx, cancel := context.WithTimeout(ctx, config.MaxTimeout)
+	defer cancel()
+
+	items, err := parseBulkItems(req)
+	if err != nil {
+		return ClientError(http.StatusBadRequest, err.Error())
+	}
+	if len(items) == 0 {
+		return ClientError(http.StatusBadRequest, "At least one URL is required")
+	}
+	if len(items) > config.MaxBulkItems {
+		return ClientError(http.StatusRequestEntityTooLarge, fmt.Sprintf("At most %d URLs can be created per request", config.MaxBulkItems))
+	}
+
+	chunk := domain.BulkChunk{
+		OwnerID:   OwnerFromContext(ctx),
+		CreatedBy: apiKeyID(ctx),
+		Domain:    req.RequestContext.DomainName,
+		Items:     items,
+	}
+
+	if len(items) <= config.BulkSyncLimit {
+		results, err := h.createLinks(timeoutCtx, chunk)
+		if err != nil {
+			return ServerError(err)
+		}
+
+		response := BulkResponse{Total: len(results), Results: results}
+		for _, result := range results {
+			if result.Status == domain.BulkItemCreated {
+				response.Created++
+			}
+		}
+		response.Failed = response.Total - response.Created
+		return jsonBodyResponse(http.StatusOK, response)
+	}
+
+	var chunks []domain.BulkChunk
+	for offset := 0; offset < len(items); offset += config.BulkChunkSize {
+		part := chunk
+		part.Offset = offset
+		part.Items = items[offset:min(offset+config.BulkChunkSize, len(items))]
+		chunks = append(chunks, part)
+	}
+
+	job, err := h.bulkService.Submit(timeoutCtx, chunk.OwnerID, chunk.CreatedBy, chunks)
+	if err != nil {
+		return ServerError(err)
+	}
+
+	response, err := jsonBodyResponse(http.StatusAccepted, job)
+	if response.StatusCode == http.StatusAccepted {
+		response.Headers["Location"] = "/generate/bulk/" + job.Id
+	}
+	return response, err
+}
+
+// JobStatus reports the progress of a bulk job with the results of the items processed so far
+func (h *BulkGenerateFunctionHandler) JobStatus(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, config.DefaultTimeout)
+	defer cancel()
+
+	id := req.PathParameters["id"]
+	if id == "" {
+		return ClientError(http.StatusBadRequest, "ID parameter is required")
+	}
+
+	job, err := h.bulkService.GetOwned(timeoutCtx, id, OwnerFromContext(ctx))
+	if errors.Is(err, domain.ErrNotFound) {
+		return ClientError(http.StatusNotFound, "Bulk job not found")
+	}
+	if err != nil {
+		return ServerError(err)
+	}
+
+	return jsonBodyResponse(http.StatusOK, job)
+}
+
+// ProcessChunks is the bulk worker's SQS handler. Failed chunks are reported back so only
+// they are redelivered, malformed messages are dropped since they would never succeed
+func (h *BulkGenerateFunctionHandler) ProcessChunks(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
+	var response events.SQSEventResponse
+	for _, message := range event.Records {
+		var chunk domain.BulkChunk
+		if err := json.Unmarshal([]byte(message.Body), &chunk); err != nil {
+			log.Printf("Dropping malformed bulk chunk message '%s': %v", message.MessageId, err)
+			continue
+		}
+
+		if err := h.Process(ctx, chunk); err != nil {
+			log.Printf("Failed to process chunk at offset %d of bulk job '%s': %v", chunk.Offset, chunk.JobID, err)
+			response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: message.MessageId})
+		}
+	}
+	return response, nil
+}
+
+// Process creates the links of a queued chunk and records their results on its job
+func (h *BulkGenerateFunctionHandler) Process(ctx context.Context, chunk domain.BulkChunk) error {
+	results, err := h.createLinks(ctx, chunk)
+	if err != nil {
+		return err
+	}
+	return h.bulkService.Complete(ctx, chunk, results)
+}
+
+// createLinks validates the chunk's items and creates the valid ones. Custom aliases are created one
+// by one so a taken alias is never overwritten, generated ids are written in batches and the ones that
+// collide are retried with new ids. A queued chunk delivered again reports the links it created before
+// instead of creating them twice. Only storage failures are returned, item failures are in the results
+func (h *BulkGenerateFunctionHandler) createLinks(ctx context.Context, chunk domain.BulkChunk) ([]domain.BulkItemResult, error) {
+	results := make([]domain.BulkItemResult, len(chunk.Items))
+	var pending []int
+	now := time.Now()
+
+	for i, item := range chunk.Items {
+		results[i] = domain.BulkItemResult{Index: chunk.Offset + i, Long: item.Long}
+		if err := checkDestination(ctx, h.policyService, chunk.Domain, item.Long); err != nil {
+			failBulkItem(&results[i], err)
+			continue
+		}
+		if item.Alias == "" {
+			pending = append(pending, i)
+			continue
+		}
+		if err := checkAlias(item.Alias); err != nil {
+			failBulkItem(&results[i], err)
+			continue
+		}
+
+		link := bulkLink(chunk, item, item.Alias, now)
+		err := h.linkService.Create(ctx, link)
+		if errors.Is(err, domain.ErrConflict) {
+			own, err := h.createdBefore(ctx, chunk, link)
+			if err != nil {
+				return nil, err
+			}
+			if !own {
+				failBulkItem(&results[i], fmt.Errorf("Alias '%s' is already in use", item.Alias))
+				continue
+			}
+		} else if err != nil {
+			return nil, err
+		}
+		results[i].Status = domain.BulkItemCreated
+		results[i].Id = item.Alias
+	}
+
+	for attempt := 1; len(pending) > 0 && attempt <= config.MaxRetries; attempt++ {
+		links := make([]domain.Link, len(pending))
+		for j, i := range pending {
+			links[j] = bulkLink(chunk, chunk.Items[i], bulkItemID(chunk, chunk.Offset+i, attempt), now)
+		}
+
+		taken, err := h.linkService.CreateBatch(ctx, links)
+		if err != nil {
+			return nil, err
+		}
+		collided := make(map[string]bool, len(taken))
+		for _, id := range taken {
+			collided[id] = true
+		}
+
+		var retry []int
+		for j, i := range pending {
+			if collided[links[j].Id] {
+				own, err := h.createdBefore(ctx, chunk, links[j])
+				if err != nil {
+					return nil, err
+				}
+				if !own {
+					retry = append(retry, i)
+					continue
+				}
+			}
+			results[i].Status = domain.BulkItemCreated
+			results[i].Id = links[j].Id
+		}
+		if len(retry) > 0 {
+			log.Printf("%d collisions detected (attempt %d/%d), retrying with new IDs", len(retry), attempt, config.MaxRetries)
+		}
+		pending = retry
+	}
+	for _, i := range pending {
+		failBulkItem(&results[i], errors.New("Failed to generate a unique ID"))
+	}
+
+	return results, nil
+}
+
+// bulkItemID returns the id to try for the item at index on the given attempt. Queued chunks derive it
+// from their job, since SQS can deliver a chunk again after its links were created: the redelivery then
+// tries the same ids and finds its own links instead of creating a second set
+func bulkItemID(chunk domain.BulkChunk, index int, attempt int) string {
+	if chunk.JobID == "" {
+		return GenerateShortURLID(config.ShortIDLength)
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d", chunk.JobID, index, attempt)))
+	id := make([]byte, config.ShortIDLength)
+	for i := range id {
+		id[i] = shortIDCharset[int(sum[i])%len(shortIDCharset)]
+	}
+	return string(id)
+}
+
+// createdBefore reports whether the taken id holds the link an earlier delivery of the queued chunk created
+func (h *BulkGenerateFunctionHandler) createdBefore(ctx context.Context, chunk domain.BulkChunk, link domain.Link) (bool, error) {
+	if chunk.JobID == "" {
+		return false, nil
+	}
+	existing, err := h.linkService.Get(ctx, link.Id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return existing.OwnerID == link.OwnerID && existing.CreatedBy == link.CreatedBy && existing.OriginalURL == link.OriginalURL, nil
+}
+
+func bulkLink(chunk domain.BulkChunk, item domain.BulkItem, id string, now time.Time) domain.Link {
+	return domain.Link{
+		Id:          id,
+		OwnerID:     chunk.OwnerID,
+		CreatedBy:   chunk.CreatedBy,
+		OriginalURL: item.Long,
+		CreatedAt:   now,
+		Version:     1,
+	}
+}
+
+func failBulkItem(result *domain.BulkItemResult, err error) {
+	result.Status = domain.BulkItemFailed
+	result.Error = err.Error()
+	var violation *domain.PolicyViolation
+	if errors.As(err, &violation) {
+		result.Reason = violation.Reason
+		result.Error = violation.Message
+	}
+}
+
+// parseBulkItems reads a text/csv body of "long[,alias]" rows with an optional "long,alias" header,
+// anything else as a JSON array of URLs or {"long", "alias"} objects
+func parseBulkItems(req events.APIGatewayV2HTTPRequest) ([]domain.BulkItem, error) {
+	body := req.Body
+	if req.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return nil, errors.New("Invalid request body encoding")
+		}
+		body = string(decoded)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(req.Headers["content-type"])
+	if mediaType != "text/csv" {
+		var items []domain.BulkItem
+		if err := json.Unmarshal([]byte(body), &items); err != nil {
+			return nil, errors.New("Invalid JSON, expected an array of URLs or {\"long\", \"alias\"} objects")
+		}
+		return items, nil
+	}
+
+	reader := csv.NewReader(strings.NewReader(body))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var items []domain.BulkItem
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid CSV: %v", err)
+		}
+		if len(record) > 2 {
+			return nil, fmt.Errorf("Invalid CSV at line %d, expected long URL and optional alias columns", line)
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "long") {
+			continue
+		}
+
+		item := domain.BulkItem{Long: strings.TrimSpace(record[0])}
+		if len(record) == 2 {
+			item.Alias = strings.TrimSpace(record[1])
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// jsonBodyResponse marshals body as the response with the given status
+func jsonBodyResponse(status int, body interface{}) (events.APIGatewayProxyResponse, error) {
+	js, err := json.Marshal(body)
+	if err != nil {
+		return ServerError(err)
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+	}, nil
+}
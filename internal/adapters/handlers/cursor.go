@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorSigner turns repository start keys into opaque pagination cursors, signed together
+// with the owner so clients can't forge keys or reuse another workspace's cursors
+type CursorSigner struct {
+	secret []byte
+}
+
+// NewCursorSigner creates a signer for the secret. Without a secret a random one
+// is generated, and cursors are only valid within the current process.
+func NewCursorSigner(secret string) *CursorSigner {
+	if secret == "" {
+		log.Print("Warning: no cursor secret configured, pagination cursors won't survive restarts")
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			log.Printf("Failed to generate cursor secret: %v", err)
+		}
+		return &CursorSigner{secret: random}
+	}
+	return &CursorSigner{secret: []byte(secret)}
+}
+
+// Encode signs the owner's start key, the last page (empty key) has no cursor
+func (c *CursorSigner) Encode(owner string, startKey string) string {
+	if startKey == "" {
+		return ""
+	}
+	payload := base64.RawURLEncoding.EncodeToString([]byte(startKey))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(c.sign(owner, payload))
+}
+
+// Decode verifies the cursor was issued to owner and returns its start key, an empty cursor is the first page
+func (c *CursorSigner) Decode(owner string, cursor string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+
+	payload, signature, found := strings.Cut(cursor, ".")
+	if !found {
+		return "", ErrInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, c.sign(owner, payload)) {
+		return "", ErrInvalidCursor
+	}
+	startKey, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", ErrInvalidCursor
+	}
+	return string(startKey), nil
+}
+
+func (c *CursorSigner) sign(owner string, payload string) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(owner))
+	mac.Write([]byte{0})
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
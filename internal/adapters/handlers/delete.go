@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
@@ -10,14 +11,29 @@ import (
 )
 
 type DeleteFunctionHandler struct {
-	statsService *services.StatsService
-	linkService  *services.LinkService
+	linkService *services.LinkService
+	retention   time.Duration
 }
 
-func NewDeleteFunctionHandler(l *services.LinkService, s *services.StatsService) *DeleteFunctionHandler {
-	return &DeleteFunctionHandler{linkService: l, statsService: s}
+// NewDeleteFunctionHandler creates the handler, deleted links can be restored for the retention period
+func NewDeleteFunctionHandler(l *services.LinkService, retention time.Duration) *DeleteFunctionHandler {
+	return &DeleteFunctionHandler{linkService: l, retention: retention}
 }
 
+// Handle serves both routes of the delete function
+func (h *DeleteFunctionHandler) Handle(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	switch req.RequestContext.HTTP.Method {
+	case http.MethodDelete:
+		return h.Delete(ctx, req)
+	case http.MethodPost:
+		return h.Restore(ctx, req)
+	default:
+		return ClientError(http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Delete tombstones the link, it redirects with 410 Gone until it is restored or purged.
+// Its stats are kept and purged along with the link
 func (h *DeleteFunctionHandler) Delete(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
 	// Add context timeout
 	timeoutCtx, cancel := context.WithTimeout(ctx, config.DefaultTimeout)
@@ -28,24 +44,30 @@ func (h *DeleteFunctionHandler) Delete(ctx context.Context, req events.APIGatewa
 		return ClientError(http.StatusBadRequest, "ID parameter is required")
 	}
 
-	// Delete link first
-	err := h.linkService.Delete(timeoutCtx, id)
-	if err != nil {
-		return ServerError(err)
-	}
-
-	// Delete associated stats
-	err = h.statsService.Delete(timeoutCtx, id)
+	err := h.linkService.Delete(timeoutCtx, id, OwnerFromContext(ctx), apiKeyID(ctx))
 	if err != nil {
-		// Log the error but don't fail the request
-		// Stats deletion is not critical
-		return events.APIGatewayProxyResponse{
-			StatusCode: http.StatusNoContent,
-			Body:       "Link deleted but stats deletion failed",
-		}, nil
+		return ErrorResponse(err)
 	}
 
 	return events.APIGatewayProxyResponse{
 		StatusCode: http.StatusNoContent,
 	}, nil
 }
+
+// Restore undoes the deletion of a link that is still within the retention period
+func (h *DeleteFunctionHandler) Restore(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, config.DefaultTimeout)
+	defer cancel()
+
+	id := req.PathParameters["id"]
+	if id == "" {
+		return ClientError(http.StatusBadRequest, "ID parameter is required")
+	}
+
+	link, err := h.linkService.Restore(timeoutCtx, id, OwnerFromContext(ctx), time.Now().Add(-h.retention), apiKeyID(ctx))
+	if err != nil {
+		return ErrorResponse(err)
+	}
+
+	return linkResponse(link)
+}
@@ -0,0 +1,437 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// errExportTooLarge stops a synchronous export that outgrew config.MaxExportSyncBytes
+var errExportTooLarge = errors.New("export is too large")
+
+// exportPlatforms are the per-platform click columns of a links CSV export
+var exportPlatforms = []domain.Platform{domain.PlatformInstagram, domain.PlatformTwitter, domain.PlatformYouTube, domain.PlatformUnknown}
+
+type ExportFunctionHandler struct {
+	linkService   *services.LinkService
+	statsService  *services.StatsService
+	exportService *services.ExportService
+}
+
+func NewExportFunctionHandler(l *services.LinkService, s *services.StatsService, e *services.ExportService) *ExportFunctionHandler {
+	return &ExportFunctionHandler{linkService: l, statsService: s, exportService: e}
+}
+
+// Handle routes the requests of the export function by their route key
+func (h *ExportFunctionHandler) Handle(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	switch req.RouteKey {
+	case "GET /export":
+		return h.Export(ctx, req)
+	case "POST /export":
+		return h.Submit(ctx, req)
+	case "GET /export/{id}":
+		return h.JobStatus(ctx, req)
+	case "GET /export/{id}/download":
+		return h.Download(ctx, req)
+	default:
+		return ClientError(http.StatusNotFound, "Route not found")
+	}
+}
+
+// Export returns the workspace's links with their click totals (type=links) or their raw click
+// events (type=stats) as CSV or NDJSON. The optional from/to range filters the clicks
+func (h *ExportFunctionHandler) Export(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	// Add context timeout, exports read many pages
+	timeoutCtx, cancel := context.WithTimeout(ctx, config.MaxTimeout)
+	defer cancel()
+
+	job, err := parseExportJob(ctx, req)
+	if err != nil {
+		return ClientError(http.StatusBadRequest, err.Error())
+	}
+
+	var body bytes.Buffer
+	_, err = h.write(timeoutCtx, job, &limitedWriter{w: &body, remaining: config.MaxExportSyncBytes})
+	if errors.Is(err, errExportTooLarge) {
+		return ClientError(http.StatusRequestEntityTooLarge, fmt.Sprintf("Export is larger than %d bytes, request it asynchronously with POST /export", config.MaxExportSyncBytes))
+	}
+	if err != nil {
+		return ServerError(err)
+	}
+
+	return exportFileResponse(job, body.String()), nil
+}
+
+// Submit queues an export with the same parameters as Export, its file is written to the
+// object store and the job's status URL is returned
+func (h *ExportFunctionHandler) Submit(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, config.DefaultTimeout)
+	defer cancel()
+
+	job, err := parseExportJob(ctx, req)
+	if err != nil {
+		return ClientError(http.StatusBadRequest, err.Error())
+	}
+
+	job, err = h.exportService.Submit(timeoutCtx, job)
+	if err != nil {
+		return ServerError(err)
+	}
+
+	response, err := jsonBodyResponse(http.StatusAccepted, job)
+	if response.StatusCode == http.StatusAccepted {
+		response.Headers["Location"] = "/export/" + job.Id
+	}
+	return response, err
+}
+
+// JobStatus reports the progress of an export, completed exports have a download_url
+func (h *ExportFunctionHandler) JobStatus(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, config.DefaultTimeout)
+	defer cancel()
+
+	job, err := h.exportService.GetOwned(timeoutCtx, req.PathParameters["id"], OwnerFromContext(ctx))
+	if errors.Is(err, domain.ErrNotFound) {
+		return ClientError(http.StatusNotFound, "Export not found")
+	}
+	if err != nil {
+		return ServerError(err)
+	}
+
+	// Stores without direct links are downloaded through the API
+	if job.Status == domain.ExportCompleted && job.DownloadURL == "" {
+		job.DownloadURL = "/export/" + job.Id + "/download"
+	}
+	return jsonBodyResponse(http.StatusOK, job)
+}
+
+// Download redirects to the completed export's file, or serves it when the store has no direct links
+func (h *ExportFunctionHandler) Download(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, config.MaxTimeout)
+	defer cancel()
+
+	job, err := h.exportService.GetOwned(timeoutCtx, req.PathParameters["id"], OwnerFromContext(ctx))
+	if errors.Is(err, domain.ErrNotFound) {
+		return ClientError(http.StatusNotFound, "Export not found")
+	}
+	if err != nil {
+		return ServerError(err)
+	}
+	if job.Status != domain.ExportCompleted {
+		return ClientError(http.StatusConflict, fmt.Sprintf("Export is %s", job.Status))
+	}
+	if job.DownloadURL != "" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusFound,
+			Headers: map[string]string{
+				"Location": job.DownloadURL,
+			},
+		}, nil
+	}
+
+	file, err := h.exportService.OpenFile(timeoutCtx, job)
+	if err != nil {
+		return ServerError(err)
+	}
+	defer file.Close()
+
+	body, err := io.ReadAll(file)
+	if err != nil {
+		return ServerError(err)
+	}
+	return exportFileResponse(job, string(body)), nil
+}
+
+// ProcessJobs is the export worker's SQS handler. Failed jobs are reported back so only
+// they are redelivered, malformed messages are dropped since they would never succeed
+func (h *ExportFunctionHandler) ProcessJobs(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
+	var response events.SQSEventResponse
+	for _, message := range event.Records {
+		var job domain.ExportJob
+		if err := json.Unmarshal([]byte(message.Body), &job); err != nil {
+			log.Printf("Dropping malformed export message '%s': %v", message.MessageId, err)
+			continue
+		}
+
+		if err := h.Process(ctx, job); err != nil {
+			log.Printf("Failed to process export '%s': %v", job.Id, err)
+			response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: message.MessageId})
+		}
+	}
+	return response, nil
+}
+
+// Process writes the file of a queued export to the object store
+func (h *ExportFunctionHandler) Process(ctx context.Context, job domain.ExportJob) error {
+	if err := h.exportService.Start(ctx, job); err != nil {
+		return err
+	}
+
+	job, err := h.exportService.Complete(ctx, job, func(w io.Writer) (int, error) {
+		return h.write(ctx, job, w)
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("Export '%s' completed with %d rows", job.Id, job.Rows)
+	return nil
+}
+
+// write pages through the owner's links and writes the job's rows, returning how many were written
+func (h *ExportFunctionHandler) write(ctx context.Context, job domain.ExportJob, w io.Writer) (int, error) {
+	encoder, err := newExportEncoder(w, job)
+	if err != nil {
+		return 0, err
+	}
+	from, to := job.Range()
+
+	startKey := ""
+	for {
+		links, nextKey, err := h.linkService.GetPage(ctx, job.OwnerID, config.ExportPageSize, startKey)
+		if err != nil {
+			return encoder.rows, err
+		}
+
+		if job.Kind == domain.ExportLinks {
+			if err := h.addClicks(ctx, links, from, to); err != nil {
+				return encoder.rows, err
+			}
+			for _, link := range links {
+				if err := encoder.encode(link, linkRecord(link)); err != nil {
+					return encoder.rows, err
+				}
+			}
+		} else {
+			for _, link := range links {
+				if err := h.writeStats(ctx, encoder, link.Id, from, to); err != nil {
+					return encoder.rows, err
+				}
+			}
+		}
+
+		if nextKey == "" {
+			return encoder.rows, encoder.flush()
+		}
+		startKey = nextKey
+	}
+}
+
+// writeStats pages through the link's click events
+func (h *ExportFunctionHandler) writeStats(ctx context.Context, encoder *exportEncoder, linkID string, from time.Time, to time.Time) error {
+	startKey := ""
+	for {
+		stats, nextKey, err := h.statsService.GetStatsPage(ctx, linkID, from, to, config.ExportPageSize, startKey)
+		if err != nil {
+			return err
+		}
+		for _, stat := range stats {
+			if err := encoder.encode(stat, statsRecord(stat)); err != nil {
+				return err
+			}
+		}
+
+		if nextKey == "" {
+			return nil
+		}
+		startKey = nextKey
+	}
+}
+
+// addClicks sets the click totals of the links concurrently, unlike the stats listing a
+// failure fails the export instead of leaving the link without clicks
+func (h *ExportFunctionHandler) addClicks(ctx context.Context, links []domain.Link, from time.Time, to time.Time) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(links))
+
+	for i := range links {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+
+			summary, err := h.statsService.GetClickSummary(ctx, links[index].Id, domain.GranularityDay, from, to)
+			if err != nil {
+				errs[index] = err
+				return
+			}
+
+			// Only the totals are exported
+			summary.Granularity = ""
+			summary.Series = nil
+			links[index].Clicks = &summary
+		}(i)
+	}
+
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// parseExportJob reads the "type" (links or stats), "format" (csv or ndjson) and from/to query parameters
+func parseExportJob(ctx context.Context, req events.APIGatewayV2HTTPRequest) (domain.ExportJob, error) {
+	job := domain.ExportJob{
+		OwnerID:   OwnerFromContext(ctx),
+		CreatedBy: apiKeyID(ctx),
+		Kind:      domain.ExportLinks,
+		Format:    domain.ExportCSV,
+	}
+
+	switch kind := domain.ExportKind(req.QueryStringParameters["type"]); kind {
+	case "":
+	case domain.ExportLinks, domain.ExportStats:
+		job.Kind = kind
+	default:
+		return job, errors.New("'type' must be 'links' or 'stats'")
+	}
+
+	switch format := domain.ExportFormat(req.QueryStringParameters["format"]); format {
+	case "":
+	case domain.ExportCSV, domain.ExportNDJSON:
+		job.Format = format
+	default:
+		return job, errors.New("'format' must be 'csv' or 'ndjson'")
+	}
+
+	from, to, err := ParseTimeRange(req)
+	if err != nil {
+		return job, err
+	}
+	if !from.IsZero() {
+		job.From = &from
+	}
+	if !to.IsZero() {
+		job.To = &to
+	}
+	return job, nil
+}
+
+// exportFileResponse returns the export as a file attachment
+func exportFileResponse(job domain.ExportJob, body string) events.APIGatewayProxyResponse {
+	filename := string(job.Kind) + "." + string(job.Format)
+	if job.Id != "" {
+		filename = string(job.Kind) + "-" + job.Id + "." + string(job.Format)
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       body,
+		Headers: map[string]string{
+			"Content-Type":        job.Format.ContentType(),
+			"Content-Disposition": fmt.Sprintf("attachment; filename=%q", filename),
+		},
+	}
+}
+
+// exportEncoder writes rows as CSV records under a header, or as one JSON value per line
+type exportEncoder struct {
+	csv  *csv.Writer
+	json *json.Encoder
+	rows int
+}
+
+func newExportEncoder(w io.Writer, job domain.ExportJob) (*exportEncoder, error) {
+	if job.Format == domain.ExportNDJSON {
+		return &exportEncoder{json: json.NewEncoder(w)}, nil
+	}
+
+	header := []string{"id", "link_id", "platform", "created_at", "variant"}
+	if job.Kind == domain.ExportLinks {
+		header = []string{"id", "original_url", "created_at", "created_by", "expires_at", "max_clicks", "click_count", "version", "updated_at", "deleted_at", "clicks"}
+		for _, platform := range exportPlatforms {
+			header = append(header, "clicks_"+strings.ToLower(platform.String()))
+		}
+	}
+
+	encoder := &exportEncoder{csv: csv.NewWriter(w)}
+	if err := encoder.csv.Write(header); err != nil {
+		return nil, err
+	}
+	return encoder, nil
+}
+
+// encode writes value in NDJSON exports and record in CSV exports
+func (e *exportEncoder) encode(value interface{}, record []string) error {
+	var err error
+	if e.json != nil {
+		err = e.json.Encode(value)
+	} else {
+		err = e.csv.Write(record)
+	}
+	if err != nil {
+		return err
+	}
+	e.rows++
+	return nil
+}
+
+func (e *exportEncoder) flush() error {
+	if e.csv == nil {
+		return nil
+	}
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+func linkRecord(link domain.Link) []string {
+	record := []string{
+		link.Id,
+		link.OriginalURL,
+		formatExportTime(&link.CreatedAt),
+		link.CreatedBy,
+		formatExportTime(link.ExpiresAt),
+		strconv.FormatInt(link.MaxClicks, 10),
+		strconv.FormatInt(link.ClickCount, 10),
+		strconv.FormatInt(link.Version, 10),
+		formatExportTime(link.UpdatedAt),
+		formatExportTime(link.DeletedAt),
+	}
+
+	var clicks domain.ClickSummary
+	if link.Clicks != nil {
+		clicks = *link.Clicks
+	}
+	record = append(record, strconv.FormatInt(clicks.Total, 10))
+	for _, platform := range exportPlatforms {
+		record = append(record, strconv.FormatInt(clicks.Platforms[platform.String()], 10))
+	}
+	return record
+}
+
+func statsRecord(stat domain.Stats) []string {
+	return []string{stat.Id, stat.LinkID, stat.Platform.String(), formatExportTime(&stat.CreatedAt), stat.Variant}
+}
+
+// formatExportTime formats t as RFC3339 in UTC, nil is an empty field
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// limitedWriter fails with errExportTooLarge once more than remaining bytes are written
+type limitedWriter struct {
+	w         io.Writer
+	remaining int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > l.remaining {
+		return 0, errExportTooLarge
+	}
+	l.remaining -= len(p)
+	return l.w.Write(p)
+}
@@ -10,8 +10,8 @@ import (
 	"math/big"
 	"net/http"
 	"os"
-	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
@@ -20,21 +20,39 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
-	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 )
 
 type RequestBody struct {
-	Long string `json:"long"`
+	Long      string     `json:"long"`
+	Alias     string     `json:"alias,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	MaxClicks int64      `json:"max_clicks,omitempty"`
+	// RedirectStatus is 301, 302, 307 or 308, 302 by default
+	RedirectStatus int `json:"redirect_status,omitempty"`
+	// CacheMaxAge is how many seconds browsers may cache the redirect, by default they don't
+	// so every click is counted
+	CacheMaxAge int64 `json:"cache_max_age,omitempty"`
+	// Targets send matching visitors to other destinations, the first match wins
+	Targets []domain.TargetRule `json:"targets,omitempty"`
+	// Variants split the other visitors over several destinations by weight for A/B tests
+	Variants []domain.Variant `json:"variants,omitempty"`
+	// Password protects the link, only a hash of it is stored
+	Password string `json:"password,omitempty"`
+	// Title is shown on the link's preview page
+	Title string `json:"title,omitempty"`
+	// Preview shows visitors the preview page instead of redirecting them right away
+	Preview bool `json:"preview,omitempty"`
 }
 
 type GenerateLinkFunctionHandler struct {
-	linkService  *services.LinkService
-	statsService *services.StatsService
+	linkService   *services.LinkService
+	statsService  *services.StatsService
+	policyService *services.PolicyService
 }
 
-func NewGenerateLinkFunctionHandler(l *services.LinkService, s *services.StatsService) *GenerateLinkFunctionHandler {
-	return &GenerateLinkFunctionHandler{linkService: l, statsService: s}
+func NewGenerateLinkFunctionHandler(l *services.LinkService, s *services.StatsService, p *services.PolicyService) *GenerateLinkFunctionHandler {
+	return &GenerateLinkFunctionHandler{linkService: l, statsService: s, policyService: p}
 }
 
 func (h *GenerateLinkFunctionHandler) CreateShortLink(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
@@ -49,27 +67,72 @@ func (h *GenerateLinkFunctionHandler) CreateShortLink(ctx context.Context, req e
 	}
 
 	// Validation
-	if requestBody.Long == "" {
-		return ClientError(http.StatusBadRequest, "URL cannot be empty")
+	if response, invalid := validateDestination(timeoutCtx, h.policyService, req, requestBody.Long); invalid {
+		return response, nil
 	}
-	if len(requestBody.Long) < config.MinURLLength {
-		return ClientError(http.StatusBadRequest, fmt.Sprintf("URL must be at least %d characters long", config.MinURLLength))
+
+	if requestBody.ExpiresAt != nil && !requestBody.ExpiresAt.After(time.Now()) {
+		return ClientError(http.StatusBadRequest, "Expiry date must be in the future")
+	}
+	if requestBody.MaxClicks < 0 {
+		return ClientError(http.StatusBadRequest, "Max clicks cannot be negative")
+	}
+	if err := checkRedirect(requestBody.RedirectStatus, requestBody.CacheMaxAge); err != nil {
+		return ClientError(http.StatusBadRequest, err.Error())
+	}
+	if response, invalid := validateTargets(timeoutCtx, h.policyService, req, requestBody.Targets); invalid {
+		return response, nil
 	}
-	if !IsValidLink(requestBody.Long) {
-		return ClientError(http.StatusBadRequest, "Invalid URL format")
+	if response, invalid := validateVariants(timeoutCtx, h.policyService, req, requestBody.Variants); invalid {
+		return response, nil
+	}
+	if utf8.RuneCountInString(requestBody.Title) > config.MaxTitleLength {
+		return ClientError(http.StatusBadRequest, fmt.Sprintf("Title cannot be longer than %d characters", config.MaxTitleLength))
+	}
+	var passwordHash string
+	if requestBody.Password != "" {
+		if len(requestBody.Password) < config.MinPasswordLength || len(requestBody.Password) > config.MaxPasswordLength {
+			return ClientError(http.StatusBadRequest, fmt.Sprintf("Password must be %d-%d characters long", config.MinPasswordLength, config.MaxPasswordLength))
+		}
+		if passwordHash, err = services.HashPassword(requestBody.Password); err != nil {
+			return ServerError(err)
+		}
 	}
-	if IsMaliciousURL(requestBody.Long) {
-		return ClientError(http.StatusBadRequest, "URL contains malicious patterns")
+
+	// Custom aliases are taken as-is, so a collision is reported to the caller
+	// instead of being retried with a new ID
+	if requestBody.Alias != "" {
+		if err := checkAlias(requestBody.Alias); err != nil {
+			return ClientError(http.StatusBadRequest, err.Error())
+		}
 	}
 
 	// Generate short URL with collision detection
 	var link domain.Link
 	var createErr error
 	for i := 0; i < config.MaxRetries; i++ {
+		id := requestBody.Alias
+		if id == "" {
+			id = GenerateShortURLID(config.ShortIDLength)
+		}
+
 		link = domain.Link{
-			Id:          GenerateShortURLID(config.ShortIDLength),
+			Id:          id,
+			OwnerID:     OwnerFromContext(ctx),
+			CreatedBy:   apiKeyID(ctx),
 			OriginalURL: requestBody.Long,
+			Title:       requestBody.Title,
 			CreatedAt:   time.Now(),
+			ExpiresAt:   requestBody.ExpiresAt,
+			MaxClicks:   requestBody.MaxClicks,
+			Version:     1,
+
+			RedirectStatus: requestBody.RedirectStatus,
+			CacheMaxAge:    requestBody.CacheMaxAge,
+			Targets:        requestBody.Targets,
+			Variants:       requestBody.Variants,
+			PasswordHash:   passwordHash,
+			Preview:        requestBody.Preview,
 		}
 
 		createErr = h.linkService.Create(timeoutCtx, link)
@@ -77,10 +140,10 @@ func (h *GenerateLinkFunctionHandler) CreateShortLink(ctx context.Context, req e
 			break // Success
 		}
 
-		// Check if it's a collision (DynamoDB conditional check failed)
-		var condCheckErr *ddbtypes.ConditionalCheckFailedException
-		if errors.As(createErr, &condCheckErr) || 
-		   strings.Contains(createErr.Error(), "already exists") {
+		if errors.Is(createErr, domain.ErrConflict) {
+			if requestBody.Alias != "" {
+				return ClientError(http.StatusConflict, fmt.Sprintf("Alias '%s' is already in use", requestBody.Alias))
+			}
 			// Collision detected - retry with new ID
 			log.Printf("Collision detected (attempt %d/%d), retrying with new ID", i+1, config.MaxRetries)
 			continue
@@ -113,6 +176,141 @@ func (h *GenerateLinkFunctionHandler) CreateShortLink(ctx context.Context, req e
 	}, nil
 }
 
+// validateDestination checks a link's destination, returning the error response when it's rejected
+func validateDestination(ctx context.Context, policyService *services.PolicyService, req events.APIGatewayV2HTTPRequest, long string) (events.APIGatewayProxyResponse, bool) {
+	err := checkDestination(ctx, policyService, req.RequestContext.DomainName, long)
+	if err == nil {
+		return events.APIGatewayProxyResponse{}, false
+	}
+
+	var response events.APIGatewayProxyResponse
+	var violation *domain.PolicyViolation
+	if errors.As(err, &violation) {
+		response, _ = PolicyViolationResponse(violation)
+	} else {
+		response, _ = ClientError(http.StatusBadRequest, err.Error())
+	}
+	return response, true
+}
+
+// checkDestination returns why a destination can't be shortened from the short domain, either a
+// *domain.PolicyViolation with its reason code or a validation error, and nil when it's accepted
+func checkDestination(ctx context.Context, policyService *services.PolicyService, shortDomain string, long string) error {
+	switch {
+	case long == "":
+		return errors.New("URL cannot be empty")
+	case len(long) < config.MinURLLength:
+		return fmt.Errorf("URL must be at least %d characters long", config.MinURLLength)
+	}
+
+	// The policy runs first so every rejected destination gets a reason code
+	if err := policyService.Check(ctx, long, shortDomain); err != nil {
+		return err
+	}
+	if !IsValidLink(long) {
+		return errors.New("Invalid URL format")
+	}
+	return nil
+}
+
+// checkRedirect returns why a link can't redirect with the status and cache age, zero values are the defaults
+func checkRedirect(status int, cacheMaxAge int64) error {
+	if status != 0 && !domain.IsValidRedirectStatus(status) {
+		return errors.New("Redirect status must be 301, 302, 307 or 308")
+	}
+	if maxAge := int64(config.MaxRedirectCacheAge / time.Second); cacheMaxAge < 0 || cacheMaxAge > maxAge {
+		return fmt.Errorf("Cache max age must be between 0 and %d seconds", maxAge)
+	}
+	return nil
+}
+
+// validateTargets checks a link's targeting rules and their destinations, returning the error response
+// of the first rule that is rejected
+func validateTargets(ctx context.Context, policyService *services.PolicyService, req events.APIGatewayV2HTTPRequest, targets []domain.TargetRule) (events.APIGatewayProxyResponse, bool) {
+	if len(targets) > config.MaxTargetRules {
+		response, _ := ClientError(http.StatusBadRequest, fmt.Sprintf("A link can have at most %d targeting rules", config.MaxTargetRules))
+		return response, true
+	}
+	for i, rule := range targets {
+		var err error
+		switch {
+		case rule.IsEmpty():
+			err = errors.New("needs a platform, os, device or language")
+		case rule.Platform != "" && !domain.IsValidTargetPlatform(rule.Platform):
+			err = errors.New("platform must be instagram, twitter or youtube")
+		case rule.OS != "" && !domain.IsValidTargetOS(rule.OS):
+			err = errors.New("os must be ios, android, windows, macos or linux")
+		case rule.Device != "" && !domain.IsValidTargetDevice(rule.Device):
+			err = errors.New("device must be mobile or desktop")
+		case rule.Language != "" && !languagePattern.MatchString(rule.Language):
+			err = errors.New("language must be a language tag like 'en' or 'en-US'")
+		}
+		if err != nil {
+			response, _ := ClientError(http.StatusBadRequest, fmt.Sprintf("Targeting rule %d %v", i+1, err))
+			return response, true
+		}
+		if response, invalid := validateDestination(ctx, policyService, req, rule.URL); invalid {
+			return response, true
+		}
+	}
+	return events.APIGatewayProxyResponse{}, false
+}
+
+// validateVariants checks a link's A/B variants and their destinations, returning the error response
+// of the first variant that is rejected. Variants without a name are named by their position (a, b, ...)
+// and variants without a weight get a weight of 1
+func validateVariants(ctx context.Context, policyService *services.PolicyService, req events.APIGatewayV2HTTPRequest, variants []domain.Variant) (events.APIGatewayProxyResponse, bool) {
+	if len(variants) == 0 {
+		return events.APIGatewayProxyResponse{}, false
+	}
+	if len(variants) < 2 || len(variants) > config.MaxVariants {
+		response, _ := ClientError(http.StatusBadRequest, fmt.Sprintf("A link needs between 2 and %d variants", config.MaxVariants))
+		return response, true
+	}
+
+	names := make(map[string]bool, len(variants))
+	for i := range variants {
+		variant := &variants[i]
+		if variant.Name == "" {
+			variant.Name = string(rune('a' + i))
+		}
+		if variant.Weight == 0 {
+			variant.Weight = 1
+		}
+
+		var err error
+		switch {
+		case len(variant.Name) > 32 || !aliasPattern.MatchString(variant.Name):
+			err = errors.New("name must be at most 32 letters, digits, '-' or '_'")
+		case names[variant.Name]:
+			err = fmt.Errorf("name '%s' is used twice", variant.Name)
+		case variant.Weight < 0 || variant.Weight > config.MaxVariantWeight:
+			err = fmt.Errorf("weight must be between 1 and %d", config.MaxVariantWeight)
+		}
+		if err != nil {
+			response, _ := ClientError(http.StatusBadRequest, fmt.Sprintf("Variant %d %v", i+1, err))
+			return response, true
+		}
+		names[variant.Name] = true
+
+		if response, invalid := validateDestination(ctx, policyService, req, variant.URL); invalid {
+			return response, true
+		}
+	}
+	return events.APIGatewayProxyResponse{}, false
+}
+
+// checkAlias returns why a custom alias can't be used, nil when it can
+func checkAlias(alias string) error {
+	if !IsValidAlias(alias) {
+		return fmt.Errorf("Alias must be %d-%d characters long and contain only letters, digits, '-' or '_'", config.MinAliasLength, config.MaxAliasLength)
+	}
+	if IsReservedAlias(alias) {
+		return errors.New("Alias is reserved")
+	}
+	return nil
+}
+
 func sendMessageToQueue(ctx context.Context, link domain.Link) {
 	cfg, err := awsconfig.LoadDefaultConfig(ctx)
 	if err != nil {
@@ -138,17 +336,19 @@ func sendMessageToQueue(ctx context.Context, link domain.Link) {
 	}
 }
 
+// shortIDCharset is the alphabet of generated short link ids
+const shortIDCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
 func GenerateShortURLID(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	result := make([]byte, length)
 	for i := 0; i < length; i++ {
-		charIndex, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		charIndex, err := rand.Int(rand.Reader, big.NewInt(int64(len(shortIDCharset))))
 		if err != nil {
 			// Fallback to timestamp-based generation if crypto/rand fails
 			log.Printf("Failed to generate random number: %v", err)
-			charIndex = big.NewInt(int64(time.Now().UnixNano() % int64(len(charset))))
+			charIndex = big.NewInt(int64(time.Now().UnixNano() % int64(len(shortIDCharset))))
 		}
-		result[i] = charset[charIndex.Int64()]
+		result[i] = shortIDCharset[charIndex.Int64()]
 	}
 	return string(result)
 }
@@ -1,13 +1,22 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"regexp"
+	"time"
 
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 	"github.com/aws/aws-lambda-go/events"
 )
 
+// HandlerFunc is the signature shared by every API Gateway handler
+type HandlerFunc func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error)
+
 func ClientError(status int, message string) (events.APIGatewayProxyResponse, error) {
 	return events.APIGatewayProxyResponse{
 		StatusCode: status,
@@ -22,6 +31,57 @@ func ServerError(err error) (events.APIGatewayProxyResponse, error) {
 	}, nil
 }
 
+// errorResponses lists the HTTP status each domain error is reported with, the first match wins so
+// the more specific errors come before the ones they wrap
+var errorResponses = []struct {
+	err     error
+	status  int
+	message string
+}{
+	{err: domain.ErrVersionNotFound, status: http.StatusNotFound, message: "Version not found"},
+	{err: domain.ErrRevisionNotFound, status: http.StatusNotFound, message: "Link didn't exist at that time"},
+	{err: domain.ErrNotFound, status: http.StatusNotFound, message: "Link not found"},
+	{err: domain.ErrConflict, status: http.StatusConflict, message: "Link already exists"},
+	{err: domain.ErrExpired, status: http.StatusGone, message: "Link has expired"},
+	{err: domain.ErrDeleted, status: http.StatusGone, message: "Link has been deleted"},
+	{err: domain.ErrVersionConflict, status: http.StatusConflict, message: "Link was changed by another request, reload it and retry"},
+	{err: domain.ErrUnauthorized, status: http.StatusUnauthorized, message: "Invalid API key"},
+	{err: domain.ErrHistoryNotRecorded, status: http.StatusInternalServerError, message: "The change was saved but couldn't be recorded in the link's history"},
+}
+
+// ErrorResponse maps a domain error to its client error, anything else is a server error
+func ErrorResponse(err error) (events.APIGatewayProxyResponse, error) {
+	var violation *domain.PolicyViolation
+	if errors.As(err, &violation) {
+		return PolicyViolationResponse(violation)
+	}
+	for _, response := range errorResponses {
+		if errors.Is(err, response.err) {
+			return ClientError(response.status, response.message)
+		}
+	}
+	return ServerError(err)
+}
+
+// PolicyViolationResponse reports a rejected URL as JSON, with the reason code for clients to act on
+func PolicyViolationResponse(violation *domain.PolicyViolation) (events.APIGatewayProxyResponse, error) {
+	js, err := json.Marshal(violation)
+	if err != nil {
+		return ServerError(err)
+	}
+	status := http.StatusBadRequest
+	if violation.Reason == domain.ReasonPolicyUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+	}, nil
+}
+
 func IsValidLink(u string) bool {
 	re := regexp.MustCompile(`^(http|https)://`)
 	if !re.MatchString(u) {
@@ -35,3 +95,27 @@ func IsValidLink(u string) bool {
 
 	return true
 }
+
+// ParseTimeRange reads the optional RFC3339 "from" and "to" query parameters
+func ParseTimeRange(req events.APIGatewayV2HTTPRequest) (time.Time, time.Time, error) {
+	var from, to time.Time
+	var err error
+
+	if value := req.QueryStringParameters["from"]; value != "" {
+		from, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return from, to, fmt.Errorf("'from' must be an RFC3339 timestamp")
+		}
+	}
+	if value := req.QueryStringParameters["to"]; value != "" {
+		to, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return from, to, fmt.Errorf("'to' must be an RFC3339 timestamp")
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return from, to, errors.New("'from' must not be after 'to'")
+	}
+
+	return from, to, nil
+}
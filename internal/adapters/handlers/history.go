@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// LinkHistory lists every destination a link id has had, oldest first
+type LinkHistory struct {
+	LinkID    string                `json:"link_id"`
+	Revisions []domain.LinkRevision `json:"revisions"`
+}
+
+type HistoryFunctionHandler struct {
+	linkService *services.LinkService
+}
+
+func NewHistoryFunctionHandler(l *services.LinkService) *HistoryFunctionHandler {
+	return &HistoryFunctionHandler{linkService: l}
+}
+
+// History returns the link's revisions, or with the RFC3339 "at" query parameter only the revision live at that time
+func (h *HistoryFunctionHandler) History(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, config.DefaultTimeout)
+	defer cancel()
+
+	linkID := req.PathParameters["id"]
+	if linkID == "" {
+		return ClientError(http.StatusBadRequest, "Link ID is required")
+	}
+
+	var body interface{}
+	if value := req.QueryStringParameters["at"]; value != "" {
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return ClientError(http.StatusBadRequest, "'at' must be an RFC3339 timestamp")
+		}
+		revision, err := h.linkService.RevisionAt(timeoutCtx, linkID, OwnerFromContext(ctx), at)
+		if err != nil {
+			return ErrorResponse(err)
+		}
+		body = revision
+	} else {
+		revisions, err := h.linkService.History(timeoutCtx, linkID, OwnerFromContext(ctx))
+		if err != nil {
+			return ErrorResponse(err)
+		}
+		body = LinkHistory{LinkID: linkID, Revisions: revisions}
+	}
+
+	js, err := json.Marshal(body)
+	if err != nil {
+		return ServerError(err)
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+	}, nil
+}
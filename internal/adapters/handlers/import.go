@@ -0,0 +1,406 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Column names of the short code, destination and creation date in the exports we read, in order of
+// preference. They cover Bitly ("bitlink", "long_url"), YOURLS ("keyword", "url", "timestamp") and
+// our own exports. Names are compared lowercased with spaces and dashes as underscores
+var (
+	importIDColumns      = []string{"id", "keyword", "short_code", "code", "slug", "alias", "bitlink", "link", "short_url", "shorturl"}
+	importLongColumns    = []string{"long_url", "original_url", "url", "long", "destination", "target"}
+	importCreatedColumns = []string{"created_at", "timestamp", "created", "creation_date", "date"}
+)
+
+// importTimeLayouts are the creation date formats we accept, dates without a zone are UTC
+var importTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05-0700", // Bitly
+	"2006-01-02 15:04:05",      // YOURLS
+	"2006-01-02",
+}
+
+// ImportReport is the outcome of an import, in a dry run Imported counts the rows that would be imported
+type ImportReport struct {
+	DryRun    bool                  `json:"dry_run"`
+	Total     int                   `json:"total"`
+	Imported  int                   `json:"imported"`
+	Skipped   int                   `json:"skipped"`
+	Conflicts int                   `json:"conflicts"`
+	Results   []domain.ImportResult `json:"results"`
+}
+
+// importRow is one link read from an export, its fields are validated when it's imported
+type importRow struct {
+	line    int
+	id      string
+	long    string
+	created string
+}
+
+type ImportFunctionHandler struct {
+	linkService   *services.LinkService
+	policyService *services.PolicyService
+}
+
+func NewImportFunctionHandler(l *services.LinkService, p *services.PolicyService) *ImportFunctionHandler {
+	return &ImportFunctionHandler{linkService: l, policyService: p}
+}
+
+// Import creates links from another shortener's CSV or JSON export, keeping their short codes and
+// creation dates. Each link is written with the same attribute_not_exists(id) condition as /generate,
+// so a taken code is reported as a conflict and never overwritten. With dry_run=true nothing is written
+func (h *ImportFunctionHandler) Import(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	// Add context timeout, every row is a separate write
+	timeoutCtx, cancel := context.WithTimeout(ctx, config.MaxTimeout)
+	defer cancel()
+
+	dryRun := false
+	if value := req.QueryStringParameters["dry_run"]; value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return ClientError(http.StatusBadRequest, "'dry_run' must be true or false")
+		}
+		dryRun = parsed
+	}
+
+	rows, err := parseImportRows(req)
+	if err != nil {
+		return ClientError(http.StatusBadRequest, err.Error())
+	}
+	if len(rows) == 0 {
+		return ClientError(http.StatusBadRequest, "The export has no links")
+	}
+	if len(rows) > config.MaxImportRows {
+		return ClientError(http.StatusRequestEntityTooLarge, fmt.Sprintf("At most %d links can be imported per request", config.MaxImportRows))
+	}
+
+	report := ImportReport{DryRun: dryRun, Total: len(rows), Results: make([]domain.ImportResult, len(rows))}
+	seen := make(map[string]int, len(rows))
+	now := time.Now()
+
+	for i, row := range rows {
+		result := &report.Results[i]
+		*result = domain.ImportResult{Line: row.line, Id: row.id, Long: row.long, Status: domain.ImportImported}
+
+		link, err := h.check(timeoutCtx, req.RequestContext.DomainName, row, now)
+		if err == nil {
+			if line, ok := seen[row.id]; ok {
+				err = fmt.Errorf("Duplicate of line %d", line)
+			} else {
+				seen[row.id] = row.line
+			}
+		}
+		if err != nil {
+			skipImportRow(result, err)
+			continue
+		}
+		link.OwnerID = OwnerFromContext(ctx)
+		link.CreatedBy = apiKeyID(ctx)
+
+		if dryRun {
+			_, err = h.linkService.Get(timeoutCtx, link.Id)
+			if errors.Is(err, domain.ErrNotFound) {
+				continue
+			}
+			if err == nil {
+				err = domain.ErrConflict
+			}
+		} else {
+			err = h.linkService.Create(timeoutCtx, link)
+		}
+
+		switch {
+		case err == nil:
+		case errors.Is(err, domain.ErrHistoryNotRecorded):
+			// The link was saved, only its revision is missing
+			log.Printf("Imported link '%s' without history: %v", link.Id, err)
+			result.Error = "Imported, but the link's history couldn't be recorded"
+		case errors.Is(err, domain.ErrConflict):
+			result.Status = domain.ImportConflict
+			result.Error = fmt.Sprintf("Id '%s' is already in use", link.Id)
+		default:
+			// Keep going, the rows already imported are reported
+			log.Printf("Failed to import link '%s': %v", link.Id, err)
+			skipImportRow(result, errors.New("Failed to save link"))
+		}
+	}
+
+	for _, result := range report.Results {
+		switch result.Status {
+		case domain.ImportImported:
+			report.Imported++
+		case domain.ImportConflict:
+			report.Conflicts++
+		default:
+			report.Skipped++
+		}
+	}
+	return jsonBodyResponse(http.StatusOK, report)
+}
+
+// check validates the row and returns its link
+func (h *ImportFunctionHandler) check(ctx context.Context, shortDomain string, row importRow, now time.Time) (domain.Link, error) {
+	if row.id == "" {
+		return domain.Link{}, errors.New("Missing short code")
+	}
+	if err := checkImportedID(row.id); err != nil {
+		return domain.Link{}, err
+	}
+	if row.long == "" {
+		return domain.Link{}, errors.New("Missing long URL")
+	}
+	if err := checkDestination(ctx, h.policyService, shortDomain, row.long); err != nil {
+		return domain.Link{}, err
+	}
+
+	createdAt := now
+	if row.created != "" {
+		var err error
+		if createdAt, err = parseImportTime(row.created); err != nil {
+			return domain.Link{}, err
+		}
+	}
+
+	return domain.Link{
+		Id:          row.id,
+		OriginalURL: row.long,
+		CreatedAt:   createdAt,
+		Version:     1,
+	}, nil
+}
+
+// checkImportedID is checkAlias without the minimum length, other shorteners hand out shorter codes
+func checkImportedID(id string) error {
+	if len(id) > config.MaxAliasLength || !aliasPattern.MatchString(id) {
+		return fmt.Errorf("Short code must be at most %d characters long and contain only letters, digits, '-' or '_'", config.MaxAliasLength)
+	}
+	if IsReservedAlias(id) {
+		return errors.New("Short code is reserved")
+	}
+	return nil
+}
+
+func skipImportRow(result *domain.ImportResult, err error) {
+	result.Status = domain.ImportSkipped
+	result.Error = err.Error()
+	var violation *domain.PolicyViolation
+	if errors.As(err, &violation) {
+		result.Reason = violation.Reason
+		result.Error = violation.Message
+	}
+}
+
+// parseImportTime reads a creation date in one of importTimeLayouts, or as Unix seconds
+func parseImportTime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	for _, layout := range importTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Invalid creation date '%s'", value)
+}
+
+// parseImportRows reads a text/csv body with a header row, anything else as JSON: an array of link
+// objects, one object per line, or an object whose "links" are an array (Bitly) or a map (YOURLS)
+func parseImportRows(req events.APIGatewayV2HTTPRequest) ([]importRow, error) {
+	body := req.Body
+	if req.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return nil, errors.New("Invalid request body encoding")
+		}
+		body = string(decoded)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(req.Headers["content-type"])
+	if mediaType == "text/csv" {
+		return parseImportCSV(body)
+	}
+	return parseImportJSON(body)
+}
+
+func parseImportCSV(body string) ([]importRow, error) {
+	reader := csv.NewReader(strings.NewReader(body))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Invalid CSV: %v", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[importColumnName(name)] = i
+	}
+	column := func(record []string, names []string) string {
+		for _, name := range names {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+		}
+		return ""
+	}
+	if _, ok := findImportColumn(columns, importIDColumns); !ok {
+		return nil, errors.New("Invalid CSV, the header has no short code column")
+	}
+	if _, ok := findImportColumn(columns, importLongColumns); !ok {
+		return nil, errors.New("Invalid CSV, the header has no long URL column")
+	}
+
+	var rows []importRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid CSV: %v", err)
+		}
+		rows = append(rows, importRow{
+			line:    line,
+			id:      shortCode(column(record, importIDColumns)),
+			long:    column(record, importLongColumns),
+			created: column(record, importCreatedColumns),
+		})
+	}
+	return rows, nil
+}
+
+func parseImportJSON(body string) ([]importRow, error) {
+	invalid := errors.New("Invalid JSON, expected an array of links, one link per line or an object with \"links\"")
+
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var values []interface{}
+	for {
+		var value interface{}
+		err := decoder.Decode(&value)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, invalid
+		}
+		values = append(values, value)
+	}
+
+	objects := values
+	if len(values) == 1 {
+		switch value := values[0].(type) {
+		case []interface{}:
+			objects = value
+		case map[string]interface{}:
+			switch links := value["links"].(type) {
+			case []interface{}:
+				objects = links
+			case map[string]interface{}:
+				objects = sortedImportLinks(links)
+			}
+		}
+	}
+
+	rows := make([]importRow, 0, len(objects))
+	for i, value := range objects {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, invalid
+		}
+
+		fields := make(map[string]string, len(object))
+		for name, field := range object {
+			switch field := field.(type) {
+			case string:
+				fields[importColumnName(name)] = strings.TrimSpace(field)
+			case json.Number:
+				fields[importColumnName(name)] = field.String()
+			}
+		}
+		field := func(names []string) string {
+			for _, name := range names {
+				if value := fields[name]; value != "" {
+					return value
+				}
+			}
+			return ""
+		}
+
+		rows = append(rows, importRow{
+			line:    i + 1,
+			id:      shortCode(field(importIDColumns)),
+			long:    field(importLongColumns),
+			created: field(importCreatedColumns),
+		})
+	}
+	return rows, nil
+}
+
+// sortedImportLinks returns the values of YOURLS' "links" map in key order, its keys are "link_1", "link_2", ...
+func sortedImportLinks(links map[string]interface{}) []interface{} {
+	keys := make([]string, 0, len(links))
+	for key := range links {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		values[i] = links[key]
+	}
+	return values
+}
+
+func findImportColumn(columns map[string]int, names []string) (int, bool) {
+	for _, name := range names {
+		if i, ok := columns[name]; ok {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// importColumnName normalizes a column name, e.g. "Long URL" to "long_url"
+func importColumnName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+}
+
+// shortCode returns the code of a short URL such as "bit.ly/abc" or "https://sho.rt/abc", or the value itself
+func shortCode(value string) string {
+	value = strings.TrimRight(value, "/")
+	if i := strings.LastIndex(value, "/"); i >= 0 {
+		return value[i+1:]
+	}
+	return value
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// passwordFormTemplate asks for the password of a protected link, posting it back to the link itself
+var passwordFormTemplate = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Password required</title>
+</head>
+<body>
+<form method="post">
+<p>This link is password protected.</p>
+{{if .}}<p role="alert">{{.}}</p>
+{{end}}<input type="password" name="password" aria-label="Password" autofocus required>
+<button type="submit">Continue</button>
+</form>
+</body>
+</html>
+`))
+
+// unlock checks the password of a protected link, sent in the X-Link-Password header or posted from the
+// password form. It returns the response to send instead of the redirect while the password is missing or
+// wrong
+func (h *RedirectFunctionHandler) unlock(ctx context.Context, req events.APIGatewayV2HTTPRequest, shortLinkKey string, redirect domain.Redirect) (events.APIGatewayProxyResponse, bool) {
+	password := req.Headers["x-link-password"]
+	if password == "" {
+		password = formPassword(req)
+	}
+	if password == "" {
+		return passwordFormResponse(http.StatusUnauthorized, ""), false
+	}
+
+	err := h.linkService.CheckPassword(ctx, shortLinkKey, RateLimitClient(ctx, req), redirect, password)
+	switch {
+	case errors.Is(err, domain.ErrLocked):
+		response := passwordFormResponse(http.StatusTooManyRequests, "Too many wrong passwords, try again later.")
+		response.Headers["Retry-After"] = strconv.Itoa(int(config.PasswordLockout.Seconds()))
+		return response, false
+	case errors.Is(err, domain.ErrWrongPassword):
+		return passwordFormResponse(http.StatusUnauthorized, "Wrong password."), false
+	case err != nil:
+		response, _ := ServerError(err)
+		return response, false
+	}
+
+	return events.APIGatewayProxyResponse{}, true
+}
+
+// formPassword returns the password posted from the password form
+func formPassword(req events.APIGatewayV2HTTPRequest) string {
+	if req.RequestContext.HTTP.Method != http.MethodPost {
+		return ""
+	}
+	if mediaType, _, _ := mime.ParseMediaType(req.Headers["content-type"]); mediaType != "application/x-www-form-urlencoded" {
+		return ""
+	}
+
+	body := req.Body
+	if req.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return ""
+		}
+		body = string(decoded)
+	}
+	values, err := url.ParseQuery(body)
+	if err != nil {
+		return ""
+	}
+	return values.Get("password")
+}
+
+func passwordFormResponse(status int, message string) events.APIGatewayProxyResponse {
+	var page bytes.Buffer
+	if err := passwordFormTemplate.Execute(&page, message); err != nil {
+		log.Printf("Failed to render password form: %v", err)
+	}
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       page.String(),
+		Headers: map[string]string{
+			"Content-Type":    "text/html; charset=utf-8",
+			"Cache-Control":   "no-store",
+			"X-Frame-Options": "DENY",
+		},
+	}
+}
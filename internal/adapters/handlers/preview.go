@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// previewTemplate shows where a link goes before following it, continuing posts back to the link itself
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<meta property="og:title" content="{{.Title}}">
+{{if .Destination}}<meta property="og:description" content="{{.Destination}}">
+{{end}}<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Destination}}<p>This link goes to <code>{{.Destination}}</code></p>
+{{else}}<p>This link is password protected, its destination is shown once you continue.</p>
+{{end}}<p>Created {{.CreatedAt.Format "January 2, 2006"}}, clicked {{.Clicks}} {{if eq .Clicks 1}}time{{else}}times{{end}}.</p>
+<form method="post" action="{{.Action}}">
+<button type="submit">Continue</button>
+</form>
+</body>
+</html>
+`))
+
+type previewPage struct {
+	Title       string
+	Destination string
+	CreatedAt   time.Time
+	Clicks      int64
+	Action      string
+}
+
+// preview renders the preview page of the link instead of redirecting. Showing it doesn't use up clicks
+func (h *RedirectFunctionHandler) preview(ctx context.Context, req events.APIGatewayV2HTTPRequest, shortLinkKey string) (events.APIGatewayProxyResponse, error) {
+	link, err := h.linkService.GetActive(ctx, shortLinkKey)
+	if err != nil {
+		return ErrorResponse(err)
+	}
+	summary, err := h.statsService.GetClickSummary(ctx, shortLinkKey, domain.GranularityDay, link.CreatedAt, time.Now())
+	if err != nil {
+		return ServerError(err)
+	}
+
+	page := previewPage{
+		Title:     link.Title,
+		CreatedAt: link.CreatedAt,
+		Clicks:    summary.Total,
+		Action:    strings.TrimSuffix(req.RawPath, "+"),
+	}
+	if page.Title == "" {
+		page.Title = "Link preview"
+	}
+	// Protected links don't give away their destination before the password
+	if !link.IsProtected() {
+		page.Destination = link.OriginalURL
+	}
+
+	var body bytes.Buffer
+	if err := previewTemplate.Execute(&body, page); err != nil {
+		log.Printf("Failed to render preview of link '%s': %v", shortLinkKey, err)
+		return ServerError(err)
+	}
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       body.String(),
+		Headers: map[string]string{
+			"Content-Type":    "text/html; charset=utf-8",
+			"Cache-Control":   "no-store",
+			"X-Frame-Options": "DENY",
+		},
+	}, nil
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+)
+
+// PurgeResult reports what a purge run removed
+type PurgeResult struct {
+	PurgedLinks  int `json:"purged_links"`
+	DeletedStats int `json:"deleted_stats"`
+	FailedLinks  int `json:"failed_links"`
+}
+
+type PurgeFunctionHandler struct {
+	linkService  *services.LinkService
+	statsService *services.StatsService
+	retention    time.Duration
+}
+
+func NewPurgeFunctionHandler(l *services.LinkService, s *services.StatsService, retention time.Duration) *PurgeFunctionHandler {
+	return &PurgeFunctionHandler{linkService: l, statsService: s, retention: retention}
+}
+
+// Purge permanently removes the links deleted more than the retention period ago, with their stats and click counters.
+// Stats go first, so a failure leaves the link to be purged again on the next run
+func (h *PurgeFunctionHandler) Purge(ctx context.Context) (PurgeResult, error) {
+	var result PurgeResult
+
+	links, err := h.linkService.DeletedBefore(ctx, time.Now().Add(-h.retention))
+	if err != nil {
+		return result, err
+	}
+
+	for _, link := range links {
+		deleted, err := h.statsService.DeleteByLinkID(ctx, link.Id)
+		result.DeletedStats += deleted
+		if err != nil {
+			log.Printf("Failed to purge stats of link '%s', %d rows deleted: %v", link.Id, deleted, err)
+			result.FailedLinks++
+			continue
+		}
+		if err := h.linkService.Purge(ctx, link); err != nil {
+			log.Printf("Failed to purge link '%s': %v", link.Id, err)
+			result.FailedLinks++
+			continue
+		}
+		result.PurgedLinks++
+		log.Printf("Purged link '%s' deleted at %s and %d stats rows", link.Id, link.DeletedAt.Format(time.RFC3339), deleted)
+	}
+
+	if result.FailedLinks > 0 {
+		return result, fmt.Errorf("failed to purge %d of %d deleted links", result.FailedLinks, len(links))
+	}
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Rate limit buckets, each client is counted separately per bucket
+const (
+	RateLimitCreate   = "create"
+	RateLimitRedirect = "redirect"
+	RateLimitBulk     = "bulk"
+)
+
+type RateLimiter struct {
+	rateLimitService *services.RateLimitService
+}
+
+func NewRateLimiter(r *services.RateLimitService) *RateLimiter {
+	return &RateLimiter{rateLimitService: r}
+}
+
+// Limit wraps next so each client gets at most limit requests in the bucket, further requests get
+// 429 with a Retry-After header. Clients are told apart by API key, or by source IP for anonymous
+// requests, so Limit goes inside RequireScope on authenticated routes
+func (r *RateLimiter) Limit(bucket string, limit domain.RateLimit, next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+		allowed, retryAfter, err := r.rateLimitService.Allow(ctx, bucket, RateLimitClient(ctx, req), limit)
+		if err != nil {
+			// An unavailable limiter shouldn't take the API down with it
+			log.Printf("Error checking rate limit: %v", err)
+			return next(ctx, req)
+		}
+		if allowed {
+			return next(ctx, req)
+		}
+
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		if seconds < 1 {
+			seconds = 1
+		}
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusTooManyRequests,
+			Body:       "Too many requests",
+			Headers: map[string]string{
+				"Retry-After": strconv.Itoa(seconds),
+			},
+		}, nil
+	}
+}
+
+// RateLimitClient identifies who a request is counted against
+func RateLimitClient(ctx context.Context, req events.APIGatewayV2HTTPRequest) string {
+	if key, ok := APIKeyFromContext(ctx); ok {
+		return "key:" + key.Id
+	}
+	return "ip:" + req.RequestContext.HTTP.SourceIP
+}
@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"context"
+	"hash/fnv"
 	"log"
+	"math/rand"
 	"net/http"
 	"strings"
 	"time"
@@ -33,18 +35,63 @@ func (h *RedirectFunctionHandler) Redirect(ctx context.Context, req events.APIGa
 		return ClientError(http.StatusBadRequest, "Invalid URL path")
 	}
 
-	shortLinkKey := pathSegments[len(pathSegments)-1]
+	// A + after the key asks for the preview page instead of the redirect
+	shortLinkKey, preview := strings.CutSuffix(pathSegments[len(pathSegments)-1], "+")
 	if shortLinkKey == "" {
 		return ClientError(http.StatusBadRequest, "Short link key cannot be empty")
 	}
+	if preview {
+		return h.preview(timeoutCtx, req, shortLinkKey)
+	}
 
-	longLink, err := h.linkService.GetOriginalURL(timeoutCtx, shortLinkKey)
-	if err != nil || longLink == nil || *longLink == "" {
-		return ClientError(http.StatusNotFound, "Link not found")
+	redirect, err := h.linkService.GetRedirect(timeoutCtx, shortLinkKey)
+	if err != nil {
+		return ErrorResponse(err)
+	}
+	// Preview links redirect once the visitor continues from the preview page, which posts back here
+	if redirect.Preview && req.RequestContext.HTTP.Method != http.MethodPost {
+		return h.preview(timeoutCtx, req, shortLinkKey)
+	}
+	if redirect.IsProtected() {
+		if response, ok := h.unlock(timeoutCtx, req, shortLinkKey, redirect); !ok {
+			return response, nil
+		}
+	}
+	// GetRedirect leaves the click of protected and preview links with a click limit to be counted here
+	if redirect.MaxClicks > 0 {
+		if err := h.linkService.CountClick(timeoutCtx, shortLinkKey); err != nil {
+			return ErrorResponse(err)
+		}
 	}
 
-	// Extract platform from request headers
-	platform := ExtractPlatformFromRequest(req)
+	// Extract platform, device and language from request headers
+	visitor := ExtractVisitorFromRequest(req)
+
+	// Targeting rules come first, the visitors they don't catch are split over the A/B variants
+	headers := map[string]string{
+		"Location":      redirect.URL,
+		"Cache-Control": redirect.CacheControl(),
+	}
+	var variant domain.Variant
+	if rule, ok := redirect.Target(visitor); ok {
+		headers["Location"] = rule.URL
+	} else if len(redirect.Variants) > 0 {
+		variant = pickVariant(req, shortLinkKey, redirect)
+		headers["Location"] = variant.URL
+		headers["Set-Cookie"] = variantCookie(req, variant)
+	}
+
+	var vary []string
+	if len(redirect.Targets) > 0 {
+		vary = append(vary, "User-Agent", "Referer", "Accept-Language")
+	}
+	if len(redirect.Variants) > 0 {
+		vary = append(vary, "Cookie")
+	}
+	if len(vary) > 0 {
+		// Shared caches must not hand one visitor's destination to another
+		headers["Vary"] = strings.Join(vary, ", ")
+	}
 
 	// Create stats asynchronously to not block the redirect
 	go func() {
@@ -52,18 +99,55 @@ func (h *RedirectFunctionHandler) Redirect(ctx context.Context, req events.APIGa
 		if err := h.statsService.Create(statsCtx, domain.Stats{
 			Id:        uuid.NewString(),
 			LinkID:    shortLinkKey,
-			CreatedAt: time.Now(),
-			Platform:  platform,
+			CreatedAt: time.Now().UTC(),
+			Platform:  visitor.Platform,
+			Variant:   variant.Name,
 		}); err != nil {
 			log.Printf("Failed to create stats for link '%s': %v", shortLinkKey, err)
 		}
 	}()
 
+	// Redirects answering a POST, from the password form, are always 303 so the browser follows them
+	// with a GET. A 307 or 308 would post the form, and the password, again to the destination
+	status := redirect.StatusCode()
+	if req.RequestContext.HTTP.Method == http.MethodPost {
+		status = http.StatusSeeOther
+		headers["Cache-Control"] = "no-store"
+	}
+
 	return events.APIGatewayProxyResponse{
-		StatusCode: http.StatusMovedPermanently,
-		Headers: map[string]string{
-			"Location":      *longLink,
-			"Cache-Control": "public, max-age=300", // Cache for 5 minutes
-		},
+		StatusCode: status,
+		Headers:    headers,
 	}, nil
 }
+
+// pickVariant returns the variant the visitor saw before, from their cookie, or assigns one by weight.
+// Visitors without the cookie are assigned by a hash of their IP address, so they keep their variant
+// when they don't keep cookies
+func pickVariant(req events.APIGatewayV2HTTPRequest, linkID string, redirect domain.Redirect) domain.Variant {
+	if variant, ok := redirect.Variant(requestCookie(req, config.VariantCookieName)); ok {
+		return variant
+	}
+
+	sourceIP := req.RequestContext.HTTP.SourceIP
+	if sourceIP == "" {
+		return redirect.PickVariant(rand.Uint64())
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(linkID + "|" + sourceIP))
+	return redirect.PickVariant(hash.Sum64())
+}
+
+// variantCookie remembers the visitor's variant, only for the link's own path
+func variantCookie(req events.APIGatewayV2HTTPRequest, variant domain.Variant) string {
+	cookie := http.Cookie{
+		Name:     config.VariantCookieName,
+		Value:    variant.Name,
+		Path:     req.RawPath,
+		MaxAge:   int(config.VariantCookieMaxAge / time.Second),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	return cookie.String()
+}
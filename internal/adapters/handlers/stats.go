@@ -3,11 +3,14 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"sync"
 
 	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
 	"github.com/aws/aws-lambda-go/events"
 )
@@ -15,10 +18,18 @@ import (
 type StatsFunctionHandler struct {
 	statsService *services.StatsService
 	linkService  *services.LinkService
+	cursors      *CursorSigner
 }
 
-func NewStatsFunctionHandler(l *services.LinkService, s *services.StatsService) *StatsFunctionHandler {
-	return &StatsFunctionHandler{linkService: l, statsService: s}
+// StatsPage is one page of links with their click totals
+type StatsPage struct {
+	Links      []domain.Link `json:"links"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// NewStatsFunctionHandler creates the handler, cursorSecret signs its pagination cursors
+func NewStatsFunctionHandler(l *services.LinkService, s *services.StatsService, cursorSecret string) *StatsFunctionHandler {
+	return &StatsFunctionHandler{linkService: l, statsService: s, cursors: NewCursorSigner(cursorSecret)}
 }
 
 func (h *StatsFunctionHandler) Stats(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
@@ -26,7 +37,27 @@ func (h *StatsFunctionHandler) Stats(ctx context.Context, req events.APIGatewayV
 	timeoutCtx, cancel := context.WithTimeout(ctx, config.DefaultTimeout)
 	defer cancel()
 
-	links, err := h.linkService.GetAll(timeoutCtx)
+	from, to, err := ParseTimeRange(req)
+	if err != nil {
+		return ClientError(http.StatusBadRequest, err.Error())
+	}
+
+	limit := int32(config.DefaultScanLimit)
+	if value := req.QueryStringParameters["limit"]; value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > config.MaxPageLimit {
+			return ClientError(http.StatusBadRequest, fmt.Sprintf("'limit' must be between 1 and %d", config.MaxPageLimit))
+		}
+		limit = int32(parsed)
+	}
+
+	owner := OwnerFromContext(ctx)
+	startKey, err := h.cursors.Decode(owner, req.QueryStringParameters["cursor"])
+	if err != nil {
+		return ClientError(http.StatusBadRequest, "Invalid cursor")
+	}
+
+	links, nextKey, err := h.linkService.GetPage(timeoutCtx, owner, limit, startKey)
 	if err != nil {
 		return ServerError(err)
 	}
@@ -40,21 +71,29 @@ func (h *StatsFunctionHandler) Stats(ctx context.Context, req events.APIGatewayV
 		go func(index int) {
 			defer wg.Done()
 			
-			stats, err := h.statsService.GetStatsByLinkID(timeoutCtx, links[index].Id)
+			summary, err := h.statsService.GetClickSummary(timeoutCtx, links[index].Id, domain.GranularityDay, from, to)
 			if err != nil {
 				log.Printf("Error getting stats for link '%s': %v", links[index].Id, err)
 				return
 			}
-			
+
+			// Only the totals are listed, the time series is served per link
+			summary.Granularity = ""
+			summary.Series = nil
+
 			mu.Lock()
-			links[index].Stats = stats
+			links[index].Clicks = &summary
 			mu.Unlock()
 		}(i)
 	}
-	
+
 	wg.Wait()
 
-	jsonResponse, err := json.Marshal(links)
+	if links == nil {
+		links = []domain.Link{}
+	}
+
+	jsonResponse, err := json.Marshal(StatsPage{Links: links, NextCursor: h.cursors.Encode(owner, nextKey)})
 	if err != nil {
 		return ServerError(err)
 	}
@@ -68,7 +107,7 @@ func (h *StatsFunctionHandler) Stats(ctx context.Context, req events.APIGatewayV
 	}, nil
 }
 
-// GetLinkStats returns stats for a specific link ID
+// GetLinkStats returns a link with its clicks by platform and over time
 func (h *StatsFunctionHandler) GetLinkStats(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
 	timeoutCtx, cancel := context.WithTimeout(ctx, config.DefaultTimeout)
 	defer cancel()
@@ -78,25 +117,32 @@ func (h *StatsFunctionHandler) GetLinkStats(ctx context.Context, req events.APIG
 		return ClientError(http.StatusBadRequest, "Link ID is required")
 	}
 
-	stats, err := h.statsService.GetStatsByLinkID(timeoutCtx, linkID)
+	from, to, err := ParseTimeRange(req)
 	if err != nil {
-		return ServerError(err)
+		return ClientError(http.StatusBadRequest, err.Error())
 	}
 
-	// Count by platform
-	platformCounts := make(map[string]int)
-	for _, stat := range stats {
-		platformCounts[stat.Platform.String()]++
+	granularity := domain.GranularityDay
+	switch req.QueryStringParameters["granularity"] {
+	case "", string(domain.GranularityDay):
+	case string(domain.GranularityHour):
+		granularity = domain.GranularityHour
+	default:
+		return ClientError(http.StatusBadRequest, "'granularity' must be 'hour' or 'day'")
 	}
 
-	response := map[string]interface{}{
-		"link_id":         linkID,
-		"total_clicks":    len(stats),
-		"platform_counts": platformCounts,
-		"details":         stats,
+	link, err := h.linkService.GetOwned(timeoutCtx, linkID, OwnerFromContext(ctx))
+	if err != nil {
+		return ErrorResponse(err)
+	}
+
+	summary, err := h.statsService.GetClickSummary(timeoutCtx, linkID, granularity, from, to)
+	if err != nil {
+		return ServerError(err)
 	}
+	link.Clicks = &summary
 
-	jsonResponse, err := json.Marshal(response)
+	jsonResponse, err := json.Marshal(link)
 	if err != nil {
 		return ServerError(err)
 	}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// UpdateLinkRequest changes a link's destination, Version is the version the client last read
+type UpdateLinkRequest struct {
+	Long    string `json:"long"`
+	Version *int64 `json:"version"`
+}
+
+// RollbackLinkRequest restores the destination of ToVersion, Version is the version the client last read
+type RollbackLinkRequest struct {
+	ToVersion *int64 `json:"to_version"`
+	Version   *int64 `json:"version"`
+}
+
+type UpdateLinkFunctionHandler struct {
+	linkService   *services.LinkService
+	policyService *services.PolicyService
+}
+
+func NewUpdateLinkFunctionHandler(l *services.LinkService, p *services.PolicyService) *UpdateLinkFunctionHandler {
+	return &UpdateLinkFunctionHandler{linkService: l, policyService: p}
+}
+
+// Handle serves both routes of the update function
+func (h *UpdateLinkFunctionHandler) Handle(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	switch req.RequestContext.HTTP.Method {
+	case http.MethodPatch:
+		return h.Update(ctx, req)
+	case http.MethodPost:
+		return h.Rollback(ctx, req)
+	default:
+		return ClientError(http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Update points an existing short link at a new destination. The request must carry the link's current
+// version, so two clients editing the same link can't silently overwrite each other
+func (h *UpdateLinkFunctionHandler) Update(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, config.DefaultTimeout)
+	defer cancel()
+
+	linkID := req.PathParameters["id"]
+	if linkID == "" {
+		return ClientError(http.StatusBadRequest, "Link ID is required")
+	}
+
+	var requestBody UpdateLinkRequest
+	if err := json.Unmarshal([]byte(req.Body), &requestBody); err != nil {
+		return ClientError(http.StatusBadRequest, "Invalid JSON")
+	}
+	if requestBody.Version == nil {
+		return ClientError(http.StatusBadRequest, "Version is required")
+	}
+	if response, invalid := validateDestination(timeoutCtx, h.policyService, req, requestBody.Long); invalid {
+		return response, nil
+	}
+
+	link, err := h.linkService.Update(timeoutCtx, linkID, OwnerFromContext(ctx), requestBody.Long, *requestBody.Version, apiKeyID(ctx))
+	if err != nil {
+		return ErrorResponse(err)
+	}
+
+	return linkResponse(link)
+}
+
+// Rollback points the link back at the destination of an earlier version, as a new version
+func (h *UpdateLinkFunctionHandler) Rollback(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, config.DefaultTimeout)
+	defer cancel()
+
+	linkID := req.PathParameters["id"]
+	if linkID == "" {
+		return ClientError(http.StatusBadRequest, "Link ID is required")
+	}
+
+	var requestBody RollbackLinkRequest
+	if err := json.Unmarshal([]byte(req.Body), &requestBody); err != nil {
+		return ClientError(http.StatusBadRequest, "Invalid JSON")
+	}
+	if requestBody.ToVersion == nil {
+		return ClientError(http.StatusBadRequest, "Target version is required")
+	}
+	if requestBody.Version == nil {
+		return ClientError(http.StatusBadRequest, "Version is required")
+	}
+
+	target, err := h.linkService.RollbackTarget(timeoutCtx, linkID, OwnerFromContext(ctx), *requestBody.ToVersion)
+	if err != nil {
+		return ErrorResponse(err)
+	}
+	// The old destination may have been denied since, it is checked like a new one
+	if response, invalid := validateDestination(timeoutCtx, h.policyService, req, target.OriginalURL); invalid {
+		return response, nil
+	}
+
+	link, err := h.linkService.RollbackTo(timeoutCtx, target, *requestBody.Version, apiKeyID(ctx))
+	if err != nil {
+		return ErrorResponse(err)
+	}
+
+	return linkResponse(link)
+}
+
+func linkResponse(link domain.Link) (events.APIGatewayProxyResponse, error) {
+	js, err := json.Marshal(link)
+	if err != nil {
+		return ServerError(err)
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+	}, nil
+}
@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
@@ -37,30 +40,95 @@ func ExtractPlatformFromRequest(req events.APIGatewayV2HTTPRequest) domain.Platf
 	return domain.PlatformUnknown
 }
 
-// IsShortURLLoop checks if the URL is trying to shorten an already shortened URL
-func IsShortURLLoop(url, baseURL string) bool {
-	return strings.Contains(url, baseURL)
+// ExtractVisitorFromRequest determines the platform, operating system, device and language of the
+// client from request headers, for the link's targeting rules
+func ExtractVisitorFromRequest(req events.APIGatewayV2HTTPRequest) domain.Visitor {
+	userAgent := strings.ToLower(req.Headers["user-agent"])
+	visitor := domain.Visitor{
+		Platform: ExtractPlatformFromRequest(req),
+		Device:   domain.DeviceDesktop,
+		Language: preferredLanguage(req.Headers["accept-language"]),
+	}
+
+	// iOS user agents say "like Mac OS X" and Android ones "Linux", so they are checked first
+	switch {
+	case strings.Contains(userAgent, "iphone"), strings.Contains(userAgent, "ipad"), strings.Contains(userAgent, "ipod"):
+		visitor.OS = domain.OSiOS
+	case strings.Contains(userAgent, "android"):
+		visitor.OS = domain.OSAndroid
+	case strings.Contains(userAgent, "windows"):
+		visitor.OS = domain.OSWindows
+	case strings.Contains(userAgent, "macintosh"), strings.Contains(userAgent, "mac os x"):
+		visitor.OS = domain.OSMacOS
+	case strings.Contains(userAgent, "linux"):
+		visitor.OS = domain.OSLinux
+	}
+	if visitor.OS == domain.OSiOS || visitor.OS == domain.OSAndroid || strings.Contains(userAgent, "mobile") {
+		visitor.Device = domain.DeviceMobile
+	}
+	return visitor
+}
+
+// preferredLanguage returns the Accept-Language tag with the highest weight, the first one on ties
+func preferredLanguage(header string) string {
+	best, bestWeight := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		weight := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			weight = parsed
+		}
+		if weight > bestWeight {
+			best, bestWeight = tag, weight
+		}
+	}
+	return best
+}
+
+// requestCookie returns the value of the request's cookie with the name, or "" if it has none.
+// API Gateway moves cookies out of the headers, the local server sends both
+func requestCookie(req events.APIGatewayV2HTTPRequest, name string) string {
+	header := http.Header{}
+	for _, cookie := range req.Cookies {
+		header.Add("Cookie", cookie)
+	}
+	if value := req.Headers["cookie"]; value != "" && len(req.Cookies) == 0 {
+		header.Add("Cookie", value)
+	}
+	cookie, err := (&http.Request{Header: header}).Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
 }
 
-// IsMaliciousURL checks for potentially malicious URLs
-// This is a basic implementation - in production, you'd want a more sophisticated check
-func IsMaliciousURL(url string) bool {
-	url = strings.ToLower(url)
-	
-	// List of suspicious patterns
-	suspiciousPatterns := []string{
-		"javascript:",
-		"data:",
-		"file:",
-		"vbscript:",
-		"about:",
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+var languagePattern = regexp.MustCompile(`^[A-Za-z]{2,8}(-[A-Za-z0-9]{1,8})*$`)
+
+// IsValidAlias checks the syntax of a custom short link alias
+func IsValidAlias(alias string) bool {
+	if len(alias) < config.MinAliasLength || len(alias) > config.MaxAliasLength {
+		return false
 	}
-	
-	for _, pattern := range suspiciousPatterns {
-		if strings.HasPrefix(url, pattern) {
+	return aliasPattern.MatchString(alias)
+}
+
+// IsReservedAlias checks if the alias collides with a route or word we keep for ourselves
+func IsReservedAlias(alias string) bool {
+	alias = strings.ToLower(alias)
+	for _, reserved := range config.ReservedAliases {
+		if alias == reserved {
 			return true
 		}
 	}
-	
 	return false
 }
@@ -0,0 +1,84 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+)
+
+// LocalObjectStore keeps objects as files under a directory for the standalone server,
+// keys are slash-separated paths relative to it
+type LocalObjectStore struct {
+	dir string
+}
+
+func NewLocalObjectStore(dir string) *LocalObjectStore {
+	return &LocalObjectStore{dir: dir}
+}
+
+// Put writes to a temporary file that is renamed once complete, so a failed write leaves nothing behind
+func (s *LocalObjectStore) Put(ctx context.Context, key string, contentType string, body io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory of '%s': %w", key, err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create '%s': %w", key, err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, body); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write '%s': %w", key, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", key, err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", key, err)
+	}
+	return nil
+}
+
+func (s *LocalObjectStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("object '%s': %w", key, domain.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open '%s': %w", key, err)
+	}
+	return file, nil
+}
+
+// URL returns an empty string, local objects are only served through Get
+func (s *LocalObjectStore) URL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "", nil
+}
+
+// path maps the key to a file under the directory, rejecting keys that would escape it
+func (s *LocalObjectStore) path(key string) (string, error) {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	rel, err := filepath.Rel(s.dir, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid object key '%s'", key)
+	}
+	return path, nil
+}
@@ -0,0 +1,86 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// S3ObjectStore keeps objects in an S3 bucket. Any S3-compatible store (MinIO, R2, ...) can be
+// used by setting its endpoint, objects are then addressed path-style
+type S3ObjectStore struct {
+	client   *s3.S3
+	uploader *s3manager.Uploader
+	bucket   string
+}
+
+// NewS3ObjectStore creates a store for the bucket, an empty endpoint uses AWS
+func NewS3ObjectStore(bucket string, endpoint string) (*S3ObjectStore, error) {
+	cfg := aws.NewConfig()
+	if endpoint != "" {
+		cfg = cfg.WithEndpoint(endpoint).WithS3ForcePathStyle(true)
+	}
+
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create AWS session: %w", err)
+	}
+
+	client := s3.New(sess)
+	return &S3ObjectStore{
+		client:   client,
+		uploader: s3manager.NewUploaderWithClient(client),
+		bucket:   bucket,
+	}, nil
+}
+
+// Put streams body with a multipart upload, which is aborted if reading it fails
+func (s *S3ObjectStore) Put(ctx context.Context, key string, contentType string, body io.Reader) error {
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+		Body:        body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload '%s' to S3: %w", key, err)
+	}
+	return nil
+}
+
+func (s *S3ObjectStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	result, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, fmt.Errorf("object '%s': %w", key, domain.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get '%s' from S3: %w", key, err)
+	}
+	return result.Body, nil
+}
+
+// URL presigns a GET request of the object
+func (s *S3ObjectStore) URL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	url, err := req.Presign(expiry)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign '%s': %w", key, err)
+	}
+	return url, nil
+}
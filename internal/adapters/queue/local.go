@@ -0,0 +1,95 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+)
+
+// localQueueSize is how many chunks can wait before Enqueue blocks
+const localQueueSize = 1000
+
+// LocalBulkQueue processes bulk job chunks in-process for the standalone server. Chunks are
+// lost when the process exits, their jobs then stay incomplete until they expire
+type LocalBulkQueue struct {
+	chunks chan domain.BulkChunk
+}
+
+func NewLocalBulkQueue() *LocalBulkQueue {
+	return &LocalBulkQueue{chunks: make(chan domain.BulkChunk, localQueueSize)}
+}
+
+func (q *LocalBulkQueue) Enqueue(ctx context.Context, chunk domain.BulkChunk) error {
+	select {
+	case q.chunks <- chunk:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run passes the chunks to process one at a time until ctx is done, a failed chunk is retried
+// up to config.MaxRetries times like SQS redelivers it
+func (q *LocalBulkQueue) Run(ctx context.Context, process func(context.Context, domain.BulkChunk) error) {
+	run(ctx, q.chunks, process, func(chunk domain.BulkChunk) string {
+		return fmt.Sprintf("chunk at offset %d of bulk job '%s'", chunk.Offset, chunk.JobID)
+	})
+}
+
+// LocalExportQueue runs export jobs in-process for the standalone server. Jobs are
+// lost when the process exits, they then stay queued until they expire
+type LocalExportQueue struct {
+	jobs chan domain.ExportJob
+}
+
+func NewLocalExportQueue() *LocalExportQueue {
+	return &LocalExportQueue{jobs: make(chan domain.ExportJob, localQueueSize)}
+}
+
+func (q *LocalExportQueue) Enqueue(ctx context.Context, job domain.ExportJob) error {
+	select {
+	case q.jobs <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run passes the jobs to process one at a time until ctx is done, retrying failed jobs like LocalBulkQueue
+func (q *LocalExportQueue) Run(ctx context.Context, process func(context.Context, domain.ExportJob) error) {
+	run(ctx, q.jobs, process, func(job domain.ExportJob) string {
+		return fmt.Sprintf("export '%s'", job.Id)
+	})
+}
+
+// run processes the messages of a local queue one at a time until ctx is done, a failed message is
+// retried up to config.MaxRetries times with a growing delay. describe names a message in the logs
+func run[T any](ctx context.Context, messages <-chan T, process func(context.Context, T) error, describe func(T) string) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message := <-messages:
+			for attempt := 1; ; attempt++ {
+				err := process(ctx, message)
+				if err == nil {
+					break
+				}
+				if attempt == config.MaxRetries {
+					log.Printf("Giving up on %s: %v", describe(message), err)
+					break
+				}
+				log.Printf("Failed to process %s (attempt %d/%d): %v", describe(message), attempt, config.MaxRetries, err)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Duration(attempt) * time.Second):
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// SQSBulkQueue sends bulk job chunks to an SQS queue consumed by the bulk worker function
+type SQSBulkQueue struct {
+	client   *sqs.Client
+	queueURL string
+}
+
+func NewSQSBulkQueue(ctx context.Context, queueURL string) (*SQSBulkQueue, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+
+	return &SQSBulkQueue{
+		client:   sqs.NewFromConfig(cfg),
+		queueURL: queueURL,
+	}, nil
+}
+
+func (q *SQSBulkQueue) Enqueue(ctx context.Context, chunk domain.BulkChunk) error {
+	return sendMessage(ctx, q.client, q.queueURL, chunk)
+}
+
+// SQSExportQueue sends export jobs to an SQS queue consumed by the export worker function
+type SQSExportQueue struct {
+	client   *sqs.Client
+	queueURL string
+}
+
+func NewSQSExportQueue(ctx context.Context, queueURL string) (*SQSExportQueue, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+
+	return &SQSExportQueue{
+		client:   sqs.NewFromConfig(cfg),
+		queueURL: queueURL,
+	}, nil
+}
+
+func (q *SQSExportQueue) Enqueue(ctx context.Context, job domain.ExportJob) error {
+	return sendMessage(ctx, q.client, q.queueURL, job)
+}
+
+// sendMessage sends message to the queue as JSON
+func sendMessage(ctx context.Context, client *sqs.Client, queueURL string, message interface{}) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	_, err = client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:    &queueURL,
+		MessageBody: aws.String(string(body)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send message to SQS: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// APIKeyRepository stores API keys by id, revoked keys are kept for auditing
+type APIKeyRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewAPIKeyRepository(ctx context.Context, tableName string) (*APIKeyRepository, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+
+	client := dynamodb.NewFromConfig(cfg)
+	return &APIKeyRepository{
+		client:    client,
+		tableName: tableName,
+	}, nil
+}
+
+func (d *APIKeyRepository) All(ctx context.Context) ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	var lastEvaluatedKey map[string]ddbtypes.AttributeValue
+
+	// Paginate through all results
+	for {
+		input := &dynamodb.ScanInput{
+			TableName:         &d.tableName,
+			ExclusiveStartKey: lastEvaluatedKey,
+		}
+
+		result, err := d.client.Scan(ctx, input)
+		if err != nil {
+			return keys, fmt.Errorf("failed to get items from DynamoDB: %w", err)
+		}
+
+		var pageKeys []domain.APIKey
+		err = attributevalue.UnmarshalListOfMaps(result.Items, &pageKeys)
+		if err != nil {
+			return keys, fmt.Errorf("failed to unmarshal data from DynamoDB: %w", err)
+		}
+
+		keys = append(keys, pageKeys...)
+
+		lastEvaluatedKey = result.LastEvaluatedKey
+		if lastEvaluatedKey == nil {
+			break
+		}
+	}
+
+	return keys, nil
+}
+
+func (d *APIKeyRepository) Get(ctx context.Context, id string) (domain.APIKey, error) {
+	key := domain.APIKey{}
+
+	input := &dynamodb.GetItemInput{
+		TableName: &d.tableName,
+		Key: map[string]ddbtypes.AttributeValue{
+			"id": &ddbtypes.AttributeValueMemberS{Value: id},
+		},
+	}
+
+	result, err := d.client.GetItem(ctx, input)
+	if err != nil {
+		return key, fmt.Errorf("failed to get item from DynamoDB: %w", err)
+	}
+	if result.Item == nil {
+		return key, fmt.Errorf("API key with id '%s': %w", id, domain.ErrNotFound)
+	}
+
+	err = attributevalue.UnmarshalMap(result.Item, &key)
+	if err != nil {
+		return key, fmt.Errorf("failed to unmarshal data from DynamoDB: %w", err)
+	}
+
+	return key, nil
+}
+
+func (d *APIKeyRepository) Create(ctx context.Context, key domain.APIKey) error {
+	item, err := attributevalue.MarshalMap(key)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           &d.tableName,
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	}
+
+	_, err = d.client.PutItem(ctx, input)
+	if err != nil {
+		var condCheckErr *ddbtypes.ConditionalCheckFailedException
+		if errors.As(err, &condCheckErr) {
+			return fmt.Errorf("API key with id '%s': %w", key.Id, domain.ErrConflict)
+		}
+		return fmt.Errorf("failed to put item to DynamoDB: %w", err)
+	}
+
+	return nil
+}
+
+func (d *APIKeyRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	revokedAt, err := attributevalue.Marshal(at)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: &d.tableName,
+		Key: map[string]ddbtypes.AttributeValue{
+			"id": &ddbtypes.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:    aws.String("SET revoked_at = if_not_exists(revoked_at, :at)"),
+		ConditionExpression: aws.String("attribute_exists(id)"),
+		ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+			":at": revokedAt,
+		},
+	}
+
+	_, err = d.client.UpdateItem(ctx, input)
+	if err != nil {
+		var condCheckErr *ddbtypes.ConditionalCheckFailedException
+		if errors.As(err, &condCheckErr) {
+			return fmt.Errorf("API key with id '%s': %w", id, domain.ErrNotFound)
+		}
+		return fmt.Errorf("failed to update item in DynamoDB: %w", err)
+	}
+	return nil
+}
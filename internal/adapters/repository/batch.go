@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appconfig "github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// batchWrite sends the requests, retrying the items DynamoDB leaves unprocessed when the table is
+// throttled with an exponential backoff. It returns how many requests were never processed
+func batchWrite(ctx context.Context, client *dynamodb.Client, tableName string, requests []ddbtypes.WriteRequest) (int, error) {
+	backoff := appconfig.BatchWriteBackoff
+	for attempt := 0; ; attempt++ {
+		result, err := client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]ddbtypes.WriteRequest{tableName: requests},
+		})
+		if err != nil {
+			return len(requests), fmt.Errorf("failed to batch write items to DynamoDB: %w", err)
+		}
+
+		requests = result.UnprocessedItems[tableName]
+		if len(requests) == 0 {
+			return 0, nil
+		}
+		if attempt == appconfig.MaxBatchWriteRetries {
+			return len(requests), fmt.Errorf("%d items still unprocessed after %d retries", len(requests), attempt)
+		}
+
+		if err := sleepContext(ctx, backoff); err != nil {
+			return len(requests), err
+		}
+		backoff *= 2
+	}
+}
+
+// sleepContext waits for d, returning early with the context's error when it's done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
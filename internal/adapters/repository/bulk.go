@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// bulkJobPart is the range key of a job's header, the results of each chunk are stored
+// under bulkResultsPart so a job isn't bound by the 400KB item size limit
+const bulkJobPart = "job"
+
+// bulkResultsPart is fixed-width so chunks sort by offset
+func bulkResultsPart(offset int) string {
+	return fmt.Sprintf("results#%09d", offset)
+}
+
+// bulkJobItem is the header of a job
+type bulkJobItem struct {
+	domain.BulkJob
+	Part string `dynamodbav:"part"`
+}
+
+// bulkResultsItem holds the results of one chunk
+type bulkResultsItem struct {
+	JobID     string                  `dynamodbav:"job_id"`
+	Part      string                  `dynamodbav:"part"`
+	Results   []domain.BulkItemResult `dynamodbav:"results"`
+	ExpiresAt time.Time               `dynamodbav:"expires_at,unixtime"`
+}
+
+// BulkJobRepository stores bulk jobs keyed by job_id, with their header and the results of each
+// chunk as separate items under the part range key. Items expire with the expires_at TTL attribute
+type BulkJobRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewBulkJobRepository(ctx context.Context, tableName string) (*BulkJobRepository, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+
+	client := dynamodb.NewFromConfig(cfg)
+	return &BulkJobRepository{
+		client:    client,
+		tableName: tableName,
+	}, nil
+}
+
+func (d *BulkJobRepository) Create(ctx context.Context, job domain.BulkJob) error {
+	item, err := attributevalue.MarshalMap(bulkJobItem{BulkJob: job, Part: bulkJobPart})
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           &d.tableName,
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(job_id)"),
+	}
+
+	_, err = d.client.PutItem(ctx, input)
+	if err != nil {
+		var condCheckErr *ddbtypes.ConditionalCheckFailedException
+		if errors.As(err, &condCheckErr) {
+			return fmt.Errorf("bulk job with id '%s': %w", job.Id, domain.ErrConflict)
+		}
+		return fmt.Errorf("failed to put item to DynamoDB: %w", err)
+	}
+	return nil
+}
+
+// Get queries every item of the job, the header comes first and the chunks follow in offset order
+func (d *BulkJobRepository) Get(ctx context.Context, id string) (domain.BulkJob, error) {
+	var job domain.BulkJob
+	var found bool
+	results := []domain.BulkItemResult{}
+	var lastEvaluatedKey map[string]ddbtypes.AttributeValue
+
+	// Paginate through all results
+	for {
+		input := &dynamodb.QueryInput{
+			TableName:              &d.tableName,
+			KeyConditionExpression: aws.String("job_id = :jobID"),
+			ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+				":jobID": &ddbtypes.AttributeValueMemberS{Value: id},
+			},
+			ExclusiveStartKey: lastEvaluatedKey,
+		}
+
+		result, err := d.client.Query(ctx, input)
+		if err != nil {
+			return job, fmt.Errorf("failed to query bulk job: %w", err)
+		}
+
+		for _, item := range result.Items {
+			if part, ok := item["part"].(*ddbtypes.AttributeValueMemberS); ok && part.Value == bulkJobPart {
+				var header bulkJobItem
+				if err := attributevalue.UnmarshalMap(item, &header); err != nil {
+					return job, fmt.Errorf("failed to unmarshal data from DynamoDB: %w", err)
+				}
+				job, found = header.BulkJob, true
+				continue
+			}
+
+			var chunk bulkResultsItem
+			if err := attributevalue.UnmarshalMap(item, &chunk); err != nil {
+				return job, fmt.Errorf("failed to unmarshal data from DynamoDB: %w", err)
+			}
+			results = append(results, chunk.Results...)
+		}
+
+		lastEvaluatedKey = result.LastEvaluatedKey
+		if lastEvaluatedKey == nil {
+			break
+		}
+	}
+
+	if !found {
+		return job, fmt.Errorf("bulk job with id '%s': %w", id, domain.ErrNotFound)
+	}
+	job.Results = results
+	return job, nil
+}
+
+// AddResults writes the chunk and updates the header's counts in one transaction, the chunk's
+// attribute_not_exists condition makes a redelivered chunk cancel the whole transaction
+func (d *BulkJobRepository) AddResults(ctx context.Context, id string, offset int, results []domain.BulkItemResult) error {
+	job, err := d.header(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	chunk, err := attributevalue.MarshalMap(bulkResultsItem{
+		JobID:     id,
+		Part:      bulkResultsPart(offset),
+		Results:   results,
+		ExpiresAt: job.ExpiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+	updatedAt, err := attributevalue.Marshal(time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	created, failed := countResults(results)
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []ddbtypes.TransactWriteItem{
+			{
+				Put: &ddbtypes.Put{
+					TableName:           &d.tableName,
+					Item:                chunk,
+					ConditionExpression: aws.String("attribute_not_exists(part)"),
+				},
+			},
+			{
+				Update: &ddbtypes.Update{
+					TableName: &d.tableName,
+					Key: map[string]ddbtypes.AttributeValue{
+						"job_id": &ddbtypes.AttributeValueMemberS{Value: id},
+						"part":   &ddbtypes.AttributeValueMemberS{Value: bulkJobPart},
+					},
+					UpdateExpression: aws.String("ADD processed :processed, created :created, failed :failed SET updated_at = :updatedAt"),
+					ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+						":processed": &ddbtypes.AttributeValueMemberN{Value: fmt.Sprint(len(results))},
+						":created":   &ddbtypes.AttributeValueMemberN{Value: fmt.Sprint(created)},
+						":failed":    &ddbtypes.AttributeValueMemberN{Value: fmt.Sprint(failed)},
+						":updatedAt": updatedAt,
+					},
+				},
+			},
+		},
+	}
+
+	_, err = d.client.TransactWriteItems(ctx, input)
+	if err != nil {
+		var canceledErr *ddbtypes.TransactionCanceledException
+		if errors.As(err, &canceledErr) && len(canceledErr.CancellationReasons) > 0 &&
+			aws.StringValue(canceledErr.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+			return nil // Already recorded
+		}
+		return fmt.Errorf("failed to record results of bulk job '%s' at offset %d: %w", id, offset, err)
+	}
+	return nil
+}
+
+// header reads the job without its results
+func (d *BulkJobRepository) header(ctx context.Context, id string) (domain.BulkJob, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: &d.tableName,
+		Key: map[string]ddbtypes.AttributeValue{
+			"job_id": &ddbtypes.AttributeValueMemberS{Value: id},
+			"part":   &ddbtypes.AttributeValueMemberS{Value: bulkJobPart},
+		},
+	}
+
+	result, err := d.client.GetItem(ctx, input)
+	if err != nil {
+		return domain.BulkJob{}, fmt.Errorf("failed to get item from DynamoDB: %w", err)
+	}
+	if result.Item == nil {
+		return domain.BulkJob{}, fmt.Errorf("bulk job with id '%s': %w", id, domain.ErrNotFound)
+	}
+
+	var header bulkJobItem
+	if err := attributevalue.UnmarshalMap(result.Item, &header); err != nil {
+		return domain.BulkJob{}, fmt.Errorf("failed to unmarshal data from DynamoDB: %w", err)
+	}
+	return header.BulkJob, nil
+}
+
+// countResults returns how many of the results are created and failed links
+func countResults(results []domain.BulkItemResult) (int, int) {
+	created := 0
+	for _, result := range results {
+		if result.Status == domain.BulkItemCreated {
+			created++
+		}
+	}
+	return created, len(results) - created
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	appconfig "github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+const (
+	counterTotalAttribute   = "total"
+	counterPlatformPrefix   = "p_"
+	counterVariantPrefix    = "v_"
+	counterBucketHourLayout = "2006-01-02T15"
+	counterBucketDayLayout  = "2006-01-02"
+	counterBucketSeparator  = "#"
+)
+
+// CounterRepository stores one item per link and bucket (e.g. link_id=abc, bucket=day#2024-03-01)
+// holding the total, per-platform and per-variant clicks, updated with atomic ADD operations
+type CounterRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewCounterRepository(ctx context.Context, tableName string) (*CounterRepository, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+
+	client := dynamodb.NewFromConfig(cfg)
+	return &CounterRepository{
+		client:    client,
+		tableName: tableName,
+	}, nil
+}
+
+func (d *CounterRepository) Increment(ctx context.Context, linkID string, platform domain.Platform, variant string, at time.Time) error {
+	update := "ADD #total :one, #platform :one"
+	names := map[string]string{
+		"#total":    counterTotalAttribute,
+		"#platform": counterPlatformPrefix + platform.String(),
+	}
+	if variant != "" {
+		update += ", #variant :one"
+		names["#variant"] = counterVariantPrefix + variant
+	}
+
+	// Both buckets are updated in one transaction so they never disagree
+	var items []ddbtypes.TransactWriteItem
+	for _, granularity := range []domain.Granularity{domain.GranularityHour, domain.GranularityDay} {
+		items = append(items, ddbtypes.TransactWriteItem{
+			Update: &ddbtypes.Update{
+				TableName: &d.tableName,
+				Key: map[string]ddbtypes.AttributeValue{
+					"link_id": &ddbtypes.AttributeValueMemberS{Value: linkID},
+					"bucket":  &ddbtypes.AttributeValueMemberS{Value: bucketKey(granularity, at)},
+				},
+				UpdateExpression:         aws.String(update),
+				ExpressionAttributeNames: names,
+				ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+					":one": &ddbtypes.AttributeValueMemberN{Value: "1"},
+				},
+			},
+		})
+	}
+
+	if _, err := d.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items}); err != nil {
+		return fmt.Errorf("failed to update counters in DynamoDB: %w", err)
+	}
+	return nil
+}
+
+func (d *CounterRepository) GetBuckets(ctx context.Context, linkID string, granularity domain.Granularity, from time.Time, to time.Time) ([]domain.ClickBucket, error) {
+	// Bucket keys of one granularity share a prefix and sort chronologically
+	lower := string(granularity) + counterBucketSeparator
+	upper := lower + "~"
+	if !from.IsZero() {
+		lower = bucketKey(granularity, from)
+	}
+	if !to.IsZero() {
+		upper = bucketKey(granularity, to)
+	}
+
+	buckets := []domain.ClickBucket{}
+	var lastEvaluatedKey map[string]ddbtypes.AttributeValue
+
+	// Paginate through all results
+	for {
+		input := &dynamodb.QueryInput{
+			TableName:              &d.tableName,
+			KeyConditionExpression: aws.String("link_id = :linkID AND bucket BETWEEN :lower AND :upper"),
+			ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+				":linkID": &ddbtypes.AttributeValueMemberS{Value: linkID},
+				":lower":  &ddbtypes.AttributeValueMemberS{Value: lower},
+				":upper":  &ddbtypes.AttributeValueMemberS{Value: upper},
+			},
+			ExclusiveStartKey: lastEvaluatedKey,
+		}
+
+		result, err := d.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query counters: %w", err)
+		}
+
+		for _, item := range result.Items {
+			bucket, err := unmarshalBucket(granularity, item)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal data from DynamoDB: %w", err)
+			}
+			buckets = append(buckets, bucket)
+		}
+
+		// Check if there are more pages
+		lastEvaluatedKey = result.LastEvaluatedKey
+		if lastEvaluatedKey == nil {
+			break
+		}
+	}
+
+	return buckets, nil
+}
+
+// DeleteByLinkID queries the keys of the link's buckets and deletes them with BatchWriteItem, 25 keys per request
+func (d *CounterRepository) DeleteByLinkID(ctx context.Context, linkID string) (int, error) {
+	var keys []map[string]ddbtypes.AttributeValue
+	var lastEvaluatedKey map[string]ddbtypes.AttributeValue
+
+	// Paginate through all results
+	for {
+		input := &dynamodb.QueryInput{
+			TableName:              &d.tableName,
+			KeyConditionExpression: aws.String("link_id = :linkID"),
+			ProjectionExpression:   aws.String("link_id, bucket"),
+			ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+				":linkID": &ddbtypes.AttributeValueMemberS{Value: linkID},
+			},
+			ExclusiveStartKey: lastEvaluatedKey,
+		}
+
+		result, err := d.client.Query(ctx, input)
+		if err != nil {
+			return 0, fmt.Errorf("failed to query counters: %w", err)
+		}
+		keys = append(keys, result.Items...)
+
+		// Check if there are more pages
+		lastEvaluatedKey = result.LastEvaluatedKey
+		if lastEvaluatedKey == nil {
+			break
+		}
+	}
+
+	deleted := 0
+	for start := 0; start < len(keys); start += appconfig.MaxBatchWriteItems {
+		end := min(start+appconfig.MaxBatchWriteItems, len(keys))
+		requests := make([]ddbtypes.WriteRequest, 0, end-start)
+		for _, key := range keys[start:end] {
+			requests = append(requests, ddbtypes.WriteRequest{DeleteRequest: &ddbtypes.DeleteRequest{Key: key}})
+		}
+
+		unprocessed, err := batchWrite(ctx, d.client, d.tableName, requests)
+		deleted += len(requests) - unprocessed
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete counters of link '%s', %d deleted: %w", linkID, deleted, err)
+		}
+	}
+	return deleted, nil
+}
+
+func bucketLayout(granularity domain.Granularity) string {
+	if granularity == domain.GranularityHour {
+		return counterBucketHourLayout
+	}
+	return counterBucketDayLayout
+}
+
+func bucketKey(granularity domain.Granularity, t time.Time) string {
+	return string(granularity) + counterBucketSeparator + granularity.BucketStart(t).Format(bucketLayout(granularity))
+}
+
+func unmarshalBucket(granularity domain.Granularity, item map[string]ddbtypes.AttributeValue) (domain.ClickBucket, error) {
+	bucket := domain.ClickBucket{Platforms: map[string]int64{}}
+
+	key, ok := item["bucket"].(*ddbtypes.AttributeValueMemberS)
+	if !ok {
+		return bucket, fmt.Errorf("bucket key is missing")
+	}
+	start, err := time.Parse(bucketLayout(granularity), strings.TrimPrefix(key.Value, string(granularity)+counterBucketSeparator))
+	if err != nil {
+		return bucket, fmt.Errorf("invalid bucket key '%s': %w", key.Value, err)
+	}
+	bucket.Start = start
+
+	for name, value := range item {
+		number, ok := value.(*ddbtypes.AttributeValueMemberN)
+		if !ok {
+			continue
+		}
+		count, err := strconv.ParseInt(number.Value, 10, 64)
+		if err != nil {
+			return bucket, fmt.Errorf("invalid counter '%s': %w", name, err)
+		}
+		if name == counterTotalAttribute {
+			bucket.Total = count
+		} else if strings.HasPrefix(name, counterPlatformPrefix) {
+			bucket.Platforms[strings.TrimPrefix(name, counterPlatformPrefix)] = count
+		} else if strings.HasPrefix(name, counterVariantPrefix) {
+			if bucket.Variants == nil {
+				bucket.Variants = map[string]int64{}
+			}
+			bucket.Variants[strings.TrimPrefix(name, counterVariantPrefix)] = count
+		}
+	}
+	return bucket, nil
+}
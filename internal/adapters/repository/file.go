@@ -0,0 +1,641 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+)
+
+const (
+	opPutLink        = "put_link"
+	opDeleteLink     = "delete_link"
+	opPutStats       = "put_stats"
+	opDeleteStats    = "delete_stats"
+	opAddClick       = "add_click"
+	opSetCounter     = "set_counter"
+	opPutAPIKey      = "put_api_key"
+	opAddRevision    = "add_revision"
+	opPutBulkJob     = "put_bulk_job"
+	opAddBulkResults = "add_bulk_results"
+	opDeleteCounters = "delete_counters"
+)
+
+// journalEntry is a single line of the store file
+type journalEntry struct {
+	Op          string               `json:"op"`
+	ID          string               `json:"id,omitempty"`
+	Link        *linkEntry           `json:"link,omitempty"`
+	Stats       *domain.Stats        `json:"stats,omitempty"`
+	Click       *clickEntry          `json:"click,omitempty"`
+	Counter     *counterEntry        `json:"counter,omitempty"`
+	APIKey      *apiKeyEntry         `json:"api_key,omitempty"`
+	Revision    *domain.LinkRevision `json:"revision,omitempty"`
+	BulkJob     *bulkJobEntry        `json:"bulk_job,omitempty"`
+	BulkResults *bulkResultsEntry    `json:"bulk_results,omitempty"`
+}
+
+// clickEntry records one counted click
+type clickEntry struct {
+	LinkID   string          `json:"link_id"`
+	Platform domain.Platform `json:"platform"`
+	Variant  string          `json:"variant,omitempty"`
+	At       time.Time       `json:"at"`
+}
+
+// counterEntry holds a whole bucket, compaction folds the clicks into them
+type counterEntry struct {
+	LinkID string             `json:"link_id"`
+	Key    string             `json:"key"`
+	Bucket domain.ClickBucket `json:"bucket"`
+}
+
+// linkEntry persists the password hash of protected links, which domain.Link leaves out of its JSON
+type linkEntry struct {
+	domain.Link
+	PasswordHash string `json:"password_hash,omitempty"`
+}
+
+func newLinkEntry(link domain.Link) *linkEntry {
+	return &linkEntry{Link: link, PasswordHash: link.PasswordHash}
+}
+
+// apiKeyEntry persists the key's hash, which domain.APIKey leaves out of its JSON
+type apiKeyEntry struct {
+	domain.APIKey
+	Hash string `json:"hash"`
+}
+
+func newAPIKeyEntry(key domain.APIKey) *apiKeyEntry {
+	return &apiKeyEntry{APIKey: key, Hash: key.Hash}
+}
+
+// bulkJobEntry persists the fields of a job that domain.BulkJob leaves out of its JSON,
+// the counts are rebuilt from the results entries
+type bulkJobEntry struct {
+	Id        string    `json:"id"`
+	OwnerID   string    `json:"owner_id"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	Total     int       `json:"total"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func newBulkJobEntry(job domain.BulkJob) *bulkJobEntry {
+	return &bulkJobEntry{
+		Id:        job.Id,
+		OwnerID:   job.OwnerID,
+		CreatedBy: job.CreatedBy,
+		Total:     job.Total,
+		CreatedAt: job.CreatedAt,
+		ExpiresAt: job.ExpiresAt,
+	}
+}
+
+// bulkResultsEntry records the results of one chunk of a job
+type bulkResultsEntry struct {
+	JobID   string                  `json:"job_id"`
+	Offset  int                     `json:"offset"`
+	Results []domain.BulkItemResult `json:"results"`
+	At      time.Time               `json:"at"`
+}
+
+// FileStore keeps links and stats in a single append-only journal file, so
+// small deployments don't need DynamoDB. Every write is synced to disk before
+// it becomes visible, and the journal is compacted each time the store is opened.
+// The file must not be shared between processes.
+type FileStore struct {
+	mu        sync.RWMutex
+	path      string
+	file      *os.File
+	links     map[string]domain.Link
+	stats     map[string]domain.Stats
+	counters  clickCounters
+	apiKeys   map[string]domain.APIKey
+	revisions linkRevisions
+	bulkJobs  bulkJobs
+}
+
+// OpenFileStore loads the store at path, creating the file if it doesn't exist
+func OpenFileStore(path string) (*FileStore, error) {
+	store := &FileStore{
+		path:      path,
+		links:     make(map[string]domain.Link),
+		stats:     make(map[string]domain.Stats),
+		counters:  make(clickCounters),
+		apiKeys:   make(map[string]domain.APIKey),
+		revisions: make(linkRevisions),
+		bulkJobs:  make(bulkJobs),
+	}
+
+	if err := store.replay(); err != nil {
+		return nil, err
+	}
+	if err := store.compact(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store file: %w", err)
+	}
+	store.file = file
+	return store, nil
+}
+
+// Close releases the store file
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+func (s *FileStore) LinkRepository() *FileLinkRepository {
+	return &FileLinkRepository{store: s}
+}
+
+func (s *FileStore) StatsRepository() *FileStatsRepository {
+	return &FileStatsRepository{store: s}
+}
+
+func (s *FileStore) CounterRepository() *FileCounterRepository {
+	return &FileCounterRepository{store: s}
+}
+
+func (s *FileStore) APIKeyRepository() *FileAPIKeyRepository {
+	return &FileAPIKeyRepository{store: s}
+}
+
+func (s *FileStore) LinkHistoryRepository() *FileLinkHistoryRepository {
+	return &FileLinkHistoryRepository{store: s}
+}
+
+func (s *FileStore) BulkJobRepository() *FileBulkJobRepository {
+	return &FileBulkJobRepository{store: s}
+}
+
+// replay rebuilds the in-memory state from the journal
+func (s *FileStore) replay() error {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read store file: %w", err)
+	}
+
+	lines := bytes.Split(data, []byte("\n"))
+	// A crash during a write can leave the last line without its newline, that write was never acknowledged
+	lines = lines[:len(lines)-1]
+	for i, line := range lines {
+		var entry journalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return fmt.Errorf("corrupted store file at line %d: %w", i+1, err)
+		}
+		s.apply(entry)
+	}
+	return nil
+}
+
+// compact rewrites the journal with one entry per live item
+func (s *FileStore) compact() error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create store file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for _, link := range sortedLinks(s.links) {
+		if err := encoder.Encode(journalEntry{Op: opPutLink, Link: newLinkEntry(link)}); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to write store file: %w", err)
+		}
+	}
+	for _, stats := range sortedStats(s.stats, allStats) {
+		stats := stats
+		if err := encoder.Encode(journalEntry{Op: opPutStats, Stats: &stats}); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to write store file: %w", err)
+		}
+	}
+
+	for linkID, buckets := range s.counters {
+		for key, bucket := range buckets {
+			counter := counterEntry{LinkID: linkID, Key: key, Bucket: bucket}
+			if err := encoder.Encode(journalEntry{Op: opSetCounter, Counter: &counter}); err != nil {
+				tmp.Close()
+				return fmt.Errorf("failed to write store file: %w", err)
+			}
+		}
+	}
+
+	for _, key := range sortedAPIKeys(s.apiKeys) {
+		if err := encoder.Encode(journalEntry{Op: opPutAPIKey, APIKey: newAPIKeyEntry(key)}); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to write store file: %w", err)
+		}
+	}
+
+	for _, revisions := range s.revisions {
+		for _, revision := range revisions {
+			revision := revision
+			if err := encoder.Encode(journalEntry{Op: opAddRevision, Revision: &revision}); err != nil {
+				tmp.Close()
+				return fmt.Errorf("failed to write store file: %w", err)
+			}
+		}
+	}
+
+	// Expired jobs are dropped, like the DynamoDB TTL does
+	now := time.Now()
+	for id, state := range s.bulkJobs {
+		if !now.Before(state.job.ExpiresAt) {
+			delete(s.bulkJobs, id)
+			continue
+		}
+		if err := encoder.Encode(journalEntry{Op: opPutBulkJob, BulkJob: newBulkJobEntry(state.job)}); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to write store file: %w", err)
+		}
+		for offset, results := range state.chunks {
+			chunk := bulkResultsEntry{JobID: state.job.Id, Offset: offset, Results: results, At: state.job.UpdatedAt}
+			if err := encoder.Encode(journalEntry{Op: opAddBulkResults, BulkResults: &chunk}); err != nil {
+				tmp.Close()
+				return fmt.Errorf("failed to write store file: %w", err)
+			}
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write store file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync store file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close store file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace store file: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) apply(entry journalEntry) {
+	switch entry.Op {
+	case opPutLink:
+		link := entry.Link.Link
+		link.PasswordHash = entry.Link.PasswordHash
+		s.links[link.Id] = link
+	case opDeleteLink:
+		delete(s.links, entry.ID)
+	case opPutStats:
+		s.stats[entry.Stats.Id] = *entry.Stats
+	case opDeleteStats:
+		delete(s.stats, entry.ID)
+	case opAddClick:
+		s.counters.add(entry.Click.LinkID, entry.Click.Platform, entry.Click.Variant, entry.Click.At)
+	case opSetCounter:
+		s.counters.set(entry.Counter.LinkID, entry.Counter.Key, entry.Counter.Bucket)
+	case opDeleteCounters:
+		s.counters.delete(entry.ID)
+	case opPutAPIKey:
+		key := entry.APIKey.APIKey
+		key.Hash = entry.APIKey.Hash
+		s.apiKeys[key.Id] = key
+	case opAddRevision:
+		// Replayed entries were checked when they were written
+		_ = s.revisions.append(*entry.Revision)
+	case opPutBulkJob:
+		job := entry.BulkJob
+		_ = s.bulkJobs.create(domain.BulkJob{
+			Id:        job.Id,
+			OwnerID:   job.OwnerID,
+			CreatedBy: job.CreatedBy,
+			Total:     job.Total,
+			CreatedAt: job.CreatedAt,
+			UpdatedAt: job.CreatedAt,
+			ExpiresAt: job.ExpiresAt,
+		})
+	case opAddBulkResults:
+		chunk := entry.BulkResults
+		_, _ = s.bulkJobs.addResults(chunk.JobID, chunk.Offset, chunk.Results, chunk.At)
+	}
+}
+
+// write appends the entry to the journal and syncs it; callers must hold the write lock
+func (s *FileStore) write(entry journalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write store file: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync store file: %w", err)
+	}
+	return nil
+}
+
+// updateLink runs mutate against the links and persists the result, leaving
+// the in-memory state untouched if either step fails
+func (s *FileStore) updateLink(id string, mutate func(map[string]domain.Link) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.links[id]
+	if err := mutate(s.links); err != nil {
+		return err
+	}
+
+	entry := journalEntry{Op: opDeleteLink, ID: id}
+	if link, ok := s.links[id]; ok {
+		entry = journalEntry{Op: opPutLink, Link: newLinkEntry(link)}
+	}
+	if err := s.write(entry); err != nil {
+		if existed {
+			s.links[id] = previous
+		} else {
+			delete(s.links, id)
+		}
+		return err
+	}
+	return nil
+}
+
+// append persists an entry that can't fail to apply, then applies it
+func (s *FileStore) append(entry journalEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commit(entry)
+}
+
+// commit writes and applies an entry, the caller must hold the lock
+func (s *FileStore) commit(entry journalEntry) error {
+	if err := s.write(entry); err != nil {
+		return err
+	}
+	s.apply(entry)
+	return nil
+}
+
+// FileLinkRepository is the LinkPort view of a FileStore
+type FileLinkRepository struct {
+	store *FileStore
+}
+
+func (r *FileLinkRepository) All(ctx context.Context) ([]domain.Link, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return sortedLinks(r.store.links), nil
+}
+
+func (r *FileLinkRepository) DeletedBefore(ctx context.Context, before time.Time) ([]domain.Link, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return deletedLinks(sortedLinks(r.store.links), before), nil
+}
+
+func (r *FileLinkRepository) AllByOwner(ctx context.Context, owner string, limit int32, startKey string) ([]domain.Link, string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return paginateLinks(ownedLinks(sortedLinks(r.store.links), owner), limit, startKey)
+}
+
+func (r *FileLinkRepository) Get(ctx context.Context, id string) (domain.Link, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return getLink(r.store.links, id)
+}
+
+func (r *FileLinkRepository) Create(ctx context.Context, link domain.Link) error {
+	return r.store.updateLink(link.Id, func(links map[string]domain.Link) error {
+		return createLink(links, link)
+	})
+}
+
+func (r *FileLinkRepository) CreateBatch(ctx context.Context, links []domain.Link) ([]string, error) {
+	return createLinks(ctx, links, r.Create)
+}
+
+func (r *FileLinkRepository) Update(ctx context.Context, link domain.Link, expectedVersion int64) error {
+	return r.store.updateLink(link.Id, func(links map[string]domain.Link) error {
+		return updateLinkDestination(links, link, expectedVersion)
+	})
+}
+
+func (r *FileLinkRepository) SoftDelete(ctx context.Context, id string, owner string, at time.Time, deletedBy string) error {
+	return r.store.updateLink(id, func(links map[string]domain.Link) error {
+		return softDeleteLink(links, id, owner, at, deletedBy)
+	})
+}
+
+func (r *FileLinkRepository) Restore(ctx context.Context, id string, owner string, deletedAfter time.Time) error {
+	return r.store.updateLink(id, func(links map[string]domain.Link) error {
+		return restoreLink(links, id, owner, deletedAfter)
+	})
+}
+
+func (r *FileLinkRepository) Delete(ctx context.Context, id string, owner string) error {
+	return r.store.updateLink(id, func(links map[string]domain.Link) error {
+		return deleteLink(links, id, owner)
+	})
+}
+
+func (r *FileLinkRepository) IncrementClicks(ctx context.Context, id string) error {
+	return r.store.updateLink(id, func(links map[string]domain.Link) error {
+		return incrementClicks(links, id)
+	})
+}
+
+// FileStatsRepository is the StatsPort view of a FileStore
+type FileStatsRepository struct {
+	store *FileStore
+}
+
+func (r *FileStatsRepository) All(ctx context.Context) ([]domain.Stats, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return sortedStats(r.store.stats, allStats), nil
+}
+
+func (r *FileStatsRepository) Get(ctx context.Context, id string) (domain.Stats, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return getStats(r.store.stats, id)
+}
+
+func (r *FileStatsRepository) Create(ctx context.Context, stats domain.Stats) error {
+	return r.store.append(journalEntry{Op: opPutStats, Stats: &stats})
+}
+
+func (r *FileStatsRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if _, err := getStats(r.store.stats, id); err != nil {
+		return err
+	}
+	return r.store.commit(journalEntry{Op: opDeleteStats, ID: id})
+}
+
+// DeleteByLinkID journals one deletion per row, a failed write leaves the remaining rows in place
+func (r *FileStatsRepository) DeleteByLinkID(ctx context.Context, linkID string) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	deleted := 0
+	for _, stats := range sortedStats(r.store.stats, linkStatsInRange(linkID, time.Time{}, time.Time{})) {
+		if err := r.store.commit(journalEntry{Op: opDeleteStats, ID: stats.Id}); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (r *FileStatsRepository) GetStatsByLinkID(ctx context.Context, linkID string) ([]domain.Stats, error) {
+	return r.GetStatsByLinkIDInRange(ctx, linkID, time.Time{}, time.Time{})
+}
+
+func (r *FileStatsRepository) GetStatsByLinkIDInRange(ctx context.Context, linkID string, from time.Time, to time.Time) ([]domain.Stats, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return sortedStats(r.store.stats, linkStatsInRange(linkID, from, to)), nil
+}
+
+func (r *FileStatsRepository) GetStatsPageByLinkID(ctx context.Context, linkID string, from time.Time, to time.Time, limit int32, startKey string) ([]domain.Stats, string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return paginateStats(sortedStats(r.store.stats, linkStatsInRange(linkID, from, to)), limit, startKey)
+}
+
+// FileCounterRepository is the CounterPort view of a FileStore
+type FileCounterRepository struct {
+	store *FileStore
+}
+
+func (r *FileCounterRepository) Increment(ctx context.Context, linkID string, platform domain.Platform, variant string, at time.Time) error {
+	return r.store.append(journalEntry{Op: opAddClick, Click: &clickEntry{LinkID: linkID, Platform: platform, Variant: variant, At: at}})
+}
+
+func (r *FileCounterRepository) GetBuckets(ctx context.Context, linkID string, granularity domain.Granularity, from time.Time, to time.Time) ([]domain.ClickBucket, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return r.store.counters.buckets(linkID, granularity, from, to), nil
+}
+
+func (r *FileCounterRepository) DeleteByLinkID(ctx context.Context, linkID string) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	deleted := len(r.store.counters[linkID])
+	if deleted == 0 {
+		return 0, nil
+	}
+	return deleted, r.store.commit(journalEntry{Op: opDeleteCounters, ID: linkID})
+}
+
+// FileAPIKeyRepository is the APIKeyPort view of a FileStore
+type FileAPIKeyRepository struct {
+	store *FileStore
+}
+
+func (r *FileAPIKeyRepository) All(ctx context.Context) ([]domain.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return sortedAPIKeys(r.store.apiKeys), nil
+}
+
+func (r *FileAPIKeyRepository) Get(ctx context.Context, id string) (domain.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return getAPIKey(r.store.apiKeys, id)
+}
+
+func (r *FileAPIKeyRepository) Create(ctx context.Context, key domain.APIKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if _, exists := r.store.apiKeys[key.Id]; exists {
+		return fmt.Errorf("API key with id '%s': %w", key.Id, domain.ErrConflict)
+	}
+	return r.store.commit(journalEntry{Op: opPutAPIKey, APIKey: newAPIKeyEntry(key)})
+}
+
+func (r *FileAPIKeyRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	key, err := revokeAPIKey(r.store.apiKeys, id, at)
+	if err != nil {
+		return err
+	}
+	return r.store.commit(journalEntry{Op: opPutAPIKey, APIKey: newAPIKeyEntry(key)})
+}
+
+// FileLinkHistoryRepository is the LinkHistoryPort view of a FileStore
+type FileLinkHistoryRepository struct {
+	store *FileStore
+}
+
+func (r *FileLinkHistoryRepository) Append(ctx context.Context, revision domain.LinkRevision) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, existing := range r.store.revisions[revision.LinkID] {
+		if existing.Id == revision.Id {
+			return fmt.Errorf("revision '%s' of link '%s': %w", revision.Id, revision.LinkID, domain.ErrConflict)
+		}
+	}
+	return r.store.commit(journalEntry{Op: opAddRevision, Revision: &revision})
+}
+
+func (r *FileLinkHistoryRepository) ByLinkID(ctx context.Context, linkID string) ([]domain.LinkRevision, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return r.store.revisions.byLinkID(linkID), nil
+}
+
+// FileBulkJobRepository is the BulkJobPort view of a FileStore
+type FileBulkJobRepository struct {
+	store *FileStore
+}
+
+func (r *FileBulkJobRepository) Create(ctx context.Context, job domain.BulkJob) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if _, exists := r.store.bulkJobs[job.Id]; exists {
+		return fmt.Errorf("bulk job with id '%s': %w", job.Id, domain.ErrConflict)
+	}
+	return r.store.commit(journalEntry{Op: opPutBulkJob, BulkJob: newBulkJobEntry(job)})
+}
+
+func (r *FileBulkJobRepository) Get(ctx context.Context, id string) (domain.BulkJob, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return r.store.bulkJobs.get(id, time.Now())
+}
+
+func (r *FileBulkJobRepository) AddResults(ctx context.Context, id string, offset int, results []domain.BulkItemResult) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	state, exists := r.store.bulkJobs[id]
+	if !exists {
+		return fmt.Errorf("bulk job with id '%s': %w", id, domain.ErrNotFound)
+	}
+	if _, recorded := state.chunks[offset]; recorded {
+		return nil
+	}
+	chunk := bulkResultsEntry{JobID: id, Offset: offset, Results: results, At: time.Now()}
+	return r.store.commit(journalEntry{Op: opAddBulkResults, BulkResults: &chunk})
+}
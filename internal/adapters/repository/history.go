@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// LinkHistoryRepository stores link revisions keyed by link_id, with the chronologically
+// sortable revision id as the range key
+type LinkHistoryRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewLinkHistoryRepository(ctx context.Context, tableName string) (*LinkHistoryRepository, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+
+	client := dynamodb.NewFromConfig(cfg)
+	return &LinkHistoryRepository{
+		client:    client,
+		tableName: tableName,
+	}, nil
+}
+
+func (d *LinkHistoryRepository) Append(ctx context.Context, revision domain.LinkRevision) error {
+	item, err := attributevalue.MarshalMap(revision)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           &d.tableName,
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(revision)"), // History is append-only
+	}
+
+	_, err = d.client.PutItem(ctx, input)
+	if err != nil {
+		var condCheckErr *ddbtypes.ConditionalCheckFailedException
+		if errors.As(err, &condCheckErr) {
+			return fmt.Errorf("revision '%s' of link '%s': %w", revision.Id, revision.LinkID, domain.ErrConflict)
+		}
+		return fmt.Errorf("failed to put item to DynamoDB: %w", err)
+	}
+	return nil
+}
+
+func (d *LinkHistoryRepository) ByLinkID(ctx context.Context, linkID string) ([]domain.LinkRevision, error) {
+	var revisions []domain.LinkRevision
+	var lastEvaluatedKey map[string]ddbtypes.AttributeValue
+
+	// Paginate through all results
+	for {
+		input := &dynamodb.QueryInput{
+			TableName:              &d.tableName,
+			KeyConditionExpression: aws.String("link_id = :linkID"),
+			ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+				":linkID": &ddbtypes.AttributeValueMemberS{Value: linkID},
+			},
+			ExclusiveStartKey: lastEvaluatedKey,
+		}
+
+		result, err := d.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query link history: %w", err)
+		}
+
+		var pageRevisions []domain.LinkRevision
+		err = attributevalue.UnmarshalListOfMaps(result.Items, &pageRevisions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal data from DynamoDB: %w", err)
+		}
+
+		revisions = append(revisions, pageRevisions...)
+
+		lastEvaluatedKey = result.LastEvaluatedKey
+		if lastEvaluatedKey == nil {
+			break
+		}
+	}
+
+	return revisions, nil
+}
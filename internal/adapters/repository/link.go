@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
+	appconfig "github.com/dheeraj-vp/golang-url-shortener/internal/config"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -66,27 +69,125 @@ func (d *LinkRepository) All(ctx context.Context) ([]domain.Link, error) {
 	return links, nil
 }
 
-// AllWithPagination returns links with pagination support
-func (d *LinkRepository) AllWithPagination(ctx context.Context, limit int32, lastKey map[string]ddbtypes.AttributeValue) ([]domain.Link, map[string]ddbtypes.AttributeValue, error) {
+// DeletedBefore scans the table for tombstones only, so just the deleted links are read into memory.
+// deleted_at is stored as a Unix time number, which compares correctly in the filter
+func (d *LinkRepository) DeletedBefore(ctx context.Context, before time.Time) ([]domain.Link, error) {
 	var links []domain.Link
+	var lastEvaluatedKey map[string]ddbtypes.AttributeValue
+
+	// Paginate through all results, the filter runs on each page
+	for {
+		input := &dynamodb.ScanInput{
+			TableName:        &d.tableName,
+			FilterExpression: aws.String("attribute_exists(deleted_at) AND deleted_at < :before"),
+			ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+				":before": &ddbtypes.AttributeValueMemberN{Value: strconv.FormatInt(before.Unix(), 10)},
+			},
+			ExclusiveStartKey: lastEvaluatedKey,
+		}
+
+		result, err := d.client.Scan(ctx, input)
+		if err != nil {
+			return links, fmt.Errorf("failed to scan deleted links in DynamoDB: %w", err)
+		}
 
-	input := &dynamodb.ScanInput{
-		TableName:         &d.tableName,
+		var pageLinks []domain.Link
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &pageLinks); err != nil {
+			return links, fmt.Errorf("failed to unmarshal data from DynamoDB: %w", err)
+		}
+		links = append(links, pageLinks...)
+
+		// Check if there are more pages
+		lastEvaluatedKey = result.LastEvaluatedKey
+		if lastEvaluatedKey == nil {
+			break
+		}
+	}
+
+	return links, nil
+}
+
+// AllByOwner pages through the owner's links with the owner_id/created_at index, the start keys
+// encode DynamoDB's LastEvaluatedKey. Links created before workspaces existed have no owner_id
+// and aren't indexed, they belong to the empty owner and are found by scanning the table
+func (d *LinkRepository) AllByOwner(ctx context.Context, owner string, limit int32, startKey string) ([]domain.Link, string, error) {
+	var links []domain.Link
+
+	lastKey, err := decodeStartKey(startKey)
+	if err != nil {
+		return links, "", err
+	}
+	if owner == "" {
+		return d.scanUnowned(ctx, limit, lastKey)
+	}
+
+	result, err := d.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              &d.tableName,
+		IndexName:              aws.String(appconfig.LinkOwnerIndexName),
+		KeyConditionExpression: aws.String("owner_id = :owner"),
+		ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+			":owner": &ddbtypes.AttributeValueMemberS{Value: owner},
+		},
 		Limit:             aws.Int32(limit),
 		ExclusiveStartKey: lastKey,
+	})
+	if err != nil {
+		return links, "", fmt.Errorf("failed to query items from DynamoDB: %w", err)
 	}
 
-	result, err := d.client.Scan(ctx, input)
+	err = attributevalue.UnmarshalListOfMaps(result.Items, &links)
 	if err != nil {
-		return links, nil, fmt.Errorf("failed to get items from DynamoDB: %w", err)
+		return links, "", fmt.Errorf("failed to unmarshal data from DynamoDB: %w", err)
 	}
 
-	err = attributevalue.UnmarshalListOfMaps(result.Items, &links)
+	nextKey, err := encodeStartKey(result.LastEvaluatedKey)
 	if err != nil {
-		return links, nil, fmt.Errorf("failed to unmarshal data from DynamoDB: %w", err)
+		return links, "", err
+	}
+
+	return links, nextKey, nil
+}
+
+// scanUnowned pages through the links without owner_id. Scan applies Limit before the filter, so it keeps
+// scanning until the page is full or the table ends, and a page that overshoots ends at its last link
+func (d *LinkRepository) scanUnowned(ctx context.Context, limit int32, lastKey map[string]ddbtypes.AttributeValue) ([]domain.Link, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("invalid limit %d", limit)
 	}
 
-	return links, result.LastEvaluatedKey, nil
+	var items []map[string]ddbtypes.AttributeValue
+	for {
+		result, err := d.client.Scan(ctx, &dynamodb.ScanInput{
+			TableName:         &d.tableName,
+			FilterExpression:  aws.String("attribute_not_exists(owner_id)"),
+			Limit:             aws.Int32(limit),
+			ExclusiveStartKey: lastKey,
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get items from DynamoDB: %w", err)
+		}
+		items = append(items, result.Items...)
+		lastKey = result.LastEvaluatedKey
+
+		if len(items) > int(limit) {
+			// A scan can resume after any key of the table
+			items = items[:limit]
+			lastKey = map[string]ddbtypes.AttributeValue{"id": items[limit-1]["id"]}
+		}
+		if len(items) == int(limit) || lastKey == nil {
+			break
+		}
+	}
+
+	links := []domain.Link{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &links); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal data from DynamoDB: %w", err)
+	}
+	nextKey, err := encodeStartKey(lastKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return links, nextKey, nil
 }
 
 func (d *LinkRepository) Get(ctx context.Context, id string) (domain.Link, error) {
@@ -103,6 +204,9 @@ func (d *LinkRepository) Get(ctx context.Context, id string) (domain.Link, error
 	if err != nil {
 		return link, fmt.Errorf("failed to get item from DynamoDB: %w", err)
 	}
+	if result.Item == nil {
+		return link, fmt.Errorf("link with id '%s': %w", id, domain.ErrNotFound)
+	}
 
 	err = attributevalue.UnmarshalMap(result.Item, &link)
 	if err != nil {
@@ -129,7 +233,7 @@ func (d *LinkRepository) Create(ctx context.Context, link domain.Link) error {
 		// Check if it's a conditional check failure (collision)
 		var condCheckErr *ddbtypes.ConditionalCheckFailedException
 		if errors.As(err, &condCheckErr) {
-			return fmt.Errorf("link with id '%s' already exists: %w", link.Id, err)
+			return fmt.Errorf("link with id '%s': %w", link.Id, domain.ErrConflict)
 		}
 		return fmt.Errorf("failed to put item to DynamoDB: %w", err)
 	}
@@ -137,17 +241,270 @@ func (d *LinkRepository) Create(ctx context.Context, link domain.Link) error {
 	return nil
 }
 
-func (d *LinkRepository) Delete(ctx context.Context, id string) error {
+// CreateBatch writes the links with TransactWriteItems, up to 100 Puts per transaction, each conditional on
+// the id being free so a link created in the meantime is never overwritten
+func (d *LinkRepository) CreateBatch(ctx context.Context, links []domain.Link) ([]string, error) {
+	var taken []string
+	seen := make(map[string]bool, len(links))
+	free := make([]domain.Link, 0, len(links))
+	for _, link := range links {
+		if seen[link.Id] {
+			taken = append(taken, link.Id) // Duplicates within the batch
+			continue
+		}
+		seen[link.Id] = true
+		free = append(free, link)
+	}
+
+	for start := 0; start < len(free); start += appconfig.MaxTransactWriteItems {
+		end := min(start+appconfig.MaxTransactWriteItems, len(free))
+		chunkTaken, err := d.createTransaction(ctx, free[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to create links: %w", err)
+		}
+		taken = append(taken, chunkTaken...)
+	}
+	return taken, nil
+}
+
+// createTransaction creates the links in one transaction. A cancelled transaction reports why each Put
+// failed, the ids whose condition failed are taken and the others are written again without them.
+// Transactions cancelled by conflicts or throttling are retried with a backoff like batchWrite
+func (d *LinkRepository) createTransaction(ctx context.Context, links []domain.Link) ([]string, error) {
+	ids := make([]string, 0, len(links))
+	items := make([]ddbtypes.TransactWriteItem, 0, len(links))
+	for _, link := range links {
+		item, err := attributevalue.MarshalMap(link)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal data: %w", err)
+		}
+		items = append(items, ddbtypes.TransactWriteItem{
+			Put: &ddbtypes.Put{
+				TableName:           &d.tableName,
+				Item:                item,
+				ConditionExpression: aws.String("attribute_not_exists(id)"),
+			},
+		})
+		ids = append(ids, link.Id)
+	}
+
+	var taken []string
+	backoff := appconfig.BatchWriteBackoff
+	for attempt := 0; len(items) > 0; {
+		_, err := d.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+		if err == nil {
+			break
+		}
+		var canceled *ddbtypes.TransactionCanceledException
+		if !errors.As(err, &canceled) || len(canceled.CancellationReasons) != len(items) {
+			return nil, fmt.Errorf("failed to write transaction to DynamoDB: %w", err)
+		}
+
+		var retryIDs []string
+		var retry []ddbtypes.TransactWriteItem
+		for i, reason := range canceled.CancellationReasons {
+			if aws.StringValue(reason.Code) == "ConditionalCheckFailed" {
+				taken = append(taken, ids[i])
+				continue
+			}
+			retryIDs = append(retryIDs, ids[i])
+			retry = append(retry, items[i])
+		}
+		if len(retry) == len(items) {
+			// Nothing was taken, the transaction conflicted with another write or was throttled
+			if attempt == appconfig.MaxBatchWriteRetries {
+				return nil, fmt.Errorf("transaction still cancelled after %d retries: %w", attempt, err)
+			}
+			if err := sleepContext(ctx, backoff); err != nil {
+				return nil, err
+			}
+			backoff *= 2
+			attempt++
+		}
+		ids, items = retryIDs, retry
+	}
+	return taken, nil
+}
+
+// Update sets the link's destination and audit fields with a conditional write on the expected version,
+// click counts are left alone so concurrent redirects aren't lost
+func (d *LinkRepository) Update(ctx context.Context, link domain.Link, expectedVersion int64) error {
+	updatedAt, err := attributevalue.Marshal(link.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+	values := map[string]ddbtypes.AttributeValue{
+		":url":        &ddbtypes.AttributeValueMemberS{Value: link.OriginalURL},
+		":version":    &ddbtypes.AttributeValueMemberN{Value: strconv.FormatInt(link.Version, 10)},
+		":expected":   &ddbtypes.AttributeValueMemberN{Value: strconv.FormatInt(expectedVersion, 10)},
+		":updated_at": updatedAt,
+		":updated_by": &ddbtypes.AttributeValueMemberS{Value: link.UpdatedBy},
+		":previous":   &ddbtypes.AttributeValueMemberS{Value: link.PreviousURL},
+	}
+
+	// Links created before versioning have no version attribute
+	condition := "attribute_exists(id) AND attribute_not_exists(deleted_at) AND #version = :expected"
+	if expectedVersion == 0 {
+		condition = "attribute_exists(id) AND attribute_not_exists(deleted_at) AND (attribute_not_exists(#version) OR #version = :expected)"
+	}
+	condition += " AND " + ownerCondition(link.OwnerID, values)
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: &d.tableName,
+		Key: map[string]ddbtypes.AttributeValue{
+			"id": &ddbtypes.AttributeValueMemberS{Value: link.Id},
+		},
+		UpdateExpression:                    aws.String("SET original_url = :url, #version = :version, updated_at = :updated_at, updated_by = :updated_by, previous_url = :previous"),
+		ConditionExpression:                 aws.String(condition),
+		ExpressionAttributeNames:            map[string]string{"#version": "version"},
+		ExpressionAttributeValues:           values,
+		ReturnValuesOnConditionCheckFailure: ddbtypes.ReturnValuesOnConditionCheckFailureAllOld,
+	}
+
+	_, err = d.client.UpdateItem(ctx, input)
+	if err != nil {
+		var condCheckErr *ddbtypes.ConditionalCheckFailedException
+		if errors.As(err, &condCheckErr) {
+			var current domain.Link
+			if condCheckErr.Item == nil || attributevalue.UnmarshalMap(condCheckErr.Item, &current) != nil || current.OwnerID != link.OwnerID {
+				return fmt.Errorf("link with id '%s': %w", link.Id, domain.ErrNotFound)
+			}
+			if current.IsDeleted() {
+				return fmt.Errorf("link with id '%s': %w", link.Id, domain.ErrDeleted)
+			}
+			return fmt.Errorf("link with id '%s' is at version %d: %w", link.Id, current.Version, domain.ErrVersionConflict)
+		}
+		return fmt.Errorf("failed to update item in DynamoDB: %w", err)
+	}
+	return nil
+}
+
+// SoftDelete tombstones the owner's link, the item stays in the table until it is purged
+func (d *LinkRepository) SoftDelete(ctx context.Context, id string, owner string, at time.Time, deletedBy string) error {
+	deletedAt, err := attributevalue.Marshal(attributevalue.UnixTime(at))
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+	values := map[string]ddbtypes.AttributeValue{
+		":deleted_at": deletedAt,
+		":deleted_by": &ddbtypes.AttributeValueMemberS{Value: deletedBy},
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: &d.tableName,
+		Key: map[string]ddbtypes.AttributeValue{
+			"id": &ddbtypes.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:          aws.String("SET deleted_at = :deleted_at, deleted_by = :deleted_by"),
+		ConditionExpression:       aws.String("attribute_exists(id) AND attribute_not_exists(deleted_at) AND " + ownerCondition(owner, values)),
+		ExpressionAttributeValues: values,
+	}
+
+	_, err = d.client.UpdateItem(ctx, input)
+	if err != nil {
+		var condCheckErr *ddbtypes.ConditionalCheckFailedException
+		if errors.As(err, &condCheckErr) {
+			return fmt.Errorf("link with id '%s': %w", id, domain.ErrNotFound)
+		}
+		return fmt.Errorf("failed to update item in DynamoDB: %w", err)
+	}
+	return nil
+}
+
+// Restore removes the tombstone of the owner's link if it was deleted after the given time. deleted_at
+// is a number, so the condition also stops a restore racing the purge of an expired link
+func (d *LinkRepository) Restore(ctx context.Context, id string, owner string, deletedAfter time.Time) error {
+	after, err := attributevalue.Marshal(attributevalue.UnixTime(deletedAfter))
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+	values := map[string]ddbtypes.AttributeValue{":after": after}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: &d.tableName,
+		Key: map[string]ddbtypes.AttributeValue{
+			"id": &ddbtypes.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:          aws.String("REMOVE deleted_at, deleted_by"),
+		ConditionExpression:       aws.String("attribute_exists(id) AND deleted_at > :after AND " + ownerCondition(owner, values)),
+		ExpressionAttributeValues: values,
+	}
+
+	_, err = d.client.UpdateItem(ctx, input)
+	if err != nil {
+		var condCheckErr *ddbtypes.ConditionalCheckFailedException
+		if errors.As(err, &condCheckErr) {
+			return fmt.Errorf("deleted link with id '%s': %w", id, domain.ErrNotFound)
+		}
+		return fmt.Errorf("failed to update item in DynamoDB: %w", err)
+	}
+	return nil
+}
+
+// ownerCondition restricts a write to the owner's link, adding the owner to values.
+// Links created before workspaces existed have no owner_id
+func ownerCondition(owner string, values map[string]ddbtypes.AttributeValue) string {
+	if owner == "" {
+		return "attribute_not_exists(owner_id)"
+	}
+	values[":owner"] = &ddbtypes.AttributeValueMemberS{Value: owner}
+	return "owner_id = :owner"
+}
+
+// Delete permanently removes the link only if it belongs to owner, so tenants can't delete each other's links.
+// Deleting a link only tombstones it, this is what purges it once it can't be restored anymore
+func (d *LinkRepository) Delete(ctx context.Context, id string, owner string) error {
 	input := &dynamodb.DeleteItemInput{
 		TableName: &d.tableName,
 		Key: map[string]ddbtypes.AttributeValue{
 			"id": &ddbtypes.AttributeValueMemberS{Value: id},
 		},
+		ConditionExpression: aws.String("attribute_exists(id) AND attribute_not_exists(owner_id)"),
+	}
+	if owner != "" {
+		input.ConditionExpression = aws.String("attribute_exists(id) AND owner_id = :owner")
+		input.ExpressionAttributeValues = map[string]ddbtypes.AttributeValue{
+			":owner": &ddbtypes.AttributeValueMemberS{Value: owner},
+		}
 	}
 
 	_, err := d.client.DeleteItem(ctx, input)
 	if err != nil {
+		var condCheckErr *ddbtypes.ConditionalCheckFailedException
+		if errors.As(err, &condCheckErr) {
+			return fmt.Errorf("link with id '%s': %w", id, domain.ErrNotFound)
+		}
 		return fmt.Errorf("failed to delete item from DynamoDB: %w", err)
 	}
 	return nil
 }
+
+// IncrementClicks atomically counts a visit, failing with domain.ErrExpired once max_clicks is reached
+// and domain.ErrNotFound if the link doesn't exist
+func (d *LinkRepository) IncrementClicks(ctx context.Context, id string) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: &d.tableName,
+		Key: map[string]ddbtypes.AttributeValue{
+			"id": &ddbtypes.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:    aws.String("ADD click_count :one"),
+		ConditionExpression: aws.String("attribute_exists(id) AND (attribute_not_exists(max_clicks) OR attribute_not_exists(click_count) OR click_count < max_clicks)"),
+		ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+			":one": &ddbtypes.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValuesOnConditionCheckFailure: ddbtypes.ReturnValuesOnConditionCheckFailureAllOld,
+	}
+
+	_, err := d.client.UpdateItem(ctx, input)
+	if err != nil {
+		var condCheckErr *ddbtypes.ConditionalCheckFailedException
+		if errors.As(err, &condCheckErr) && condCheckErr.Item == nil {
+			return fmt.Errorf("link with id '%s': %w", id, domain.ErrNotFound)
+		}
+		if errors.As(err, &condCheckErr) {
+			return fmt.Errorf("link with id '%s' reached its click limit: %w", id, domain.ErrExpired)
+		}
+		return fmt.Errorf("failed to update item in DynamoDB: %w", err)
+	}
+	return nil
+}
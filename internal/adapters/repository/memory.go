@@ -0,0 +1,709 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+)
+
+// MemoryLinkRepository is a concurrency-safe in-memory LinkPort with the same
+// semantics as the DynamoDB repository. Data is lost when the process exits.
+type MemoryLinkRepository struct {
+	mu    sync.RWMutex
+	links map[string]domain.Link
+}
+
+func NewMemoryLinkRepository() *MemoryLinkRepository {
+	return &MemoryLinkRepository{links: make(map[string]domain.Link)}
+}
+
+func (m *MemoryLinkRepository) All(ctx context.Context) ([]domain.Link, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return sortedLinks(m.links), nil
+}
+
+func (m *MemoryLinkRepository) DeletedBefore(ctx context.Context, before time.Time) ([]domain.Link, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return deletedLinks(sortedLinks(m.links), before), nil
+}
+
+func (m *MemoryLinkRepository) AllByOwner(ctx context.Context, owner string, limit int32, startKey string) ([]domain.Link, string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return paginateLinks(ownedLinks(sortedLinks(m.links), owner), limit, startKey)
+}
+
+func (m *MemoryLinkRepository) Get(ctx context.Context, id string) (domain.Link, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return getLink(m.links, id)
+}
+
+func (m *MemoryLinkRepository) Create(ctx context.Context, link domain.Link) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return createLink(m.links, link)
+}
+
+func (m *MemoryLinkRepository) CreateBatch(ctx context.Context, links []domain.Link) ([]string, error) {
+	return createLinks(ctx, links, m.Create)
+}
+
+func (m *MemoryLinkRepository) Update(ctx context.Context, link domain.Link, expectedVersion int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return updateLinkDestination(m.links, link, expectedVersion)
+}
+
+func (m *MemoryLinkRepository) SoftDelete(ctx context.Context, id string, owner string, at time.Time, deletedBy string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return softDeleteLink(m.links, id, owner, at, deletedBy)
+}
+
+func (m *MemoryLinkRepository) Restore(ctx context.Context, id string, owner string, deletedAfter time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return restoreLink(m.links, id, owner, deletedAfter)
+}
+
+func (m *MemoryLinkRepository) Delete(ctx context.Context, id string, owner string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return deleteLink(m.links, id, owner)
+}
+
+func (m *MemoryLinkRepository) IncrementClicks(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return incrementClicks(m.links, id)
+}
+
+// paginate pages through items sorted by creation time and ID, the start key is the creation time and
+// ID of the last item returned so deletions don't shift pages
+func paginate[T any](items []T, key func(T) (time.Time, string), limit int32, startKey string) ([]T, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("invalid limit %d", limit)
+	}
+	start := 0
+	if startKey != "" {
+		createdAt, id, found := strings.Cut(startKey, "|")
+		after, err := time.Parse(time.RFC3339Nano, createdAt)
+		if !found || err != nil {
+			return nil, "", fmt.Errorf("invalid start key")
+		}
+		start = sort.Search(len(items), func(i int) bool {
+			itemCreatedAt, itemID := key(items[i])
+			return itemCreatedAt.After(after) || (itemCreatedAt.Equal(after) && itemID > id)
+		})
+	}
+
+	end := min(start+int(limit), len(items))
+	page := items[start:end]
+	if end == len(items) {
+		return page, "", nil
+	}
+
+	lastCreatedAt, lastID := key(page[len(page)-1])
+	return page, lastCreatedAt.Format(time.RFC3339Nano) + "|" + lastID, nil
+}
+
+// paginateLinks pages through links sorted by sortedLinks
+func paginateLinks(links []domain.Link, limit int32, startKey string) ([]domain.Link, string, error) {
+	return paginate(links, func(link domain.Link) (time.Time, string) { return link.CreatedAt, link.Id }, limit, startKey)
+}
+
+// MemoryStatsRepository is a concurrency-safe in-memory StatsPort
+type MemoryStatsRepository struct {
+	mu    sync.RWMutex
+	stats map[string]domain.Stats
+}
+
+func NewMemoryStatsRepository() *MemoryStatsRepository {
+	return &MemoryStatsRepository{stats: make(map[string]domain.Stats)}
+}
+
+func (m *MemoryStatsRepository) All(ctx context.Context) ([]domain.Stats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return sortedStats(m.stats, allStats), nil
+}
+
+func (m *MemoryStatsRepository) Get(ctx context.Context, id string) (domain.Stats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return getStats(m.stats, id)
+}
+
+func (m *MemoryStatsRepository) Create(ctx context.Context, stats domain.Stats) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats[stats.Id] = stats
+	return nil
+}
+
+func (m *MemoryStatsRepository) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, err := getStats(m.stats, id); err != nil {
+		return err
+	}
+	delete(m.stats, id)
+	return nil
+}
+
+func (m *MemoryStatsRepository) DeleteByLinkID(ctx context.Context, linkID string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := 0
+	for id, stats := range m.stats {
+		if stats.LinkID == linkID {
+			delete(m.stats, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (m *MemoryStatsRepository) GetStatsByLinkID(ctx context.Context, linkID string) ([]domain.Stats, error) {
+	return m.GetStatsByLinkIDInRange(ctx, linkID, time.Time{}, time.Time{})
+}
+
+func (m *MemoryStatsRepository) GetStatsByLinkIDInRange(ctx context.Context, linkID string, from time.Time, to time.Time) ([]domain.Stats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return sortedStats(m.stats, linkStatsInRange(linkID, from, to)), nil
+}
+
+func (m *MemoryStatsRepository) GetStatsPageByLinkID(ctx context.Context, linkID string, from time.Time, to time.Time, limit int32, startKey string) ([]domain.Stats, string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return paginateStats(sortedStats(m.stats, linkStatsInRange(linkID, from, to)), limit, startKey)
+}
+
+// MemoryCounterRepository is a concurrency-safe in-memory CounterPort
+type MemoryCounterRepository struct {
+	mu       sync.RWMutex
+	counters clickCounters
+}
+
+func NewMemoryCounterRepository() *MemoryCounterRepository {
+	return &MemoryCounterRepository{counters: make(clickCounters)}
+}
+
+func (m *MemoryCounterRepository) Increment(ctx context.Context, linkID string, platform domain.Platform, variant string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters.add(linkID, platform, variant, at)
+	return nil
+}
+
+func (m *MemoryCounterRepository) GetBuckets(ctx context.Context, linkID string, granularity domain.Granularity, from time.Time, to time.Time) ([]domain.ClickBucket, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.counters.buckets(linkID, granularity, from, to), nil
+}
+
+func (m *MemoryCounterRepository) DeleteByLinkID(ctx context.Context, linkID string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counters.delete(linkID), nil
+}
+
+// clickCounters holds the buckets of every link, keyed by link ID and then bucket key
+type clickCounters map[string]map[string]domain.ClickBucket
+
+func (c clickCounters) add(linkID string, platform domain.Platform, variant string, at time.Time) {
+	if c[linkID] == nil {
+		c[linkID] = make(map[string]domain.ClickBucket)
+	}
+	for _, granularity := range []domain.Granularity{domain.GranularityHour, domain.GranularityDay} {
+		key := bucketKey(granularity, at)
+		bucket, ok := c[linkID][key]
+		if !ok {
+			bucket = domain.ClickBucket{Start: granularity.BucketStart(at), Platforms: make(map[string]int64)}
+		}
+		bucket.Total++
+		bucket.Platforms[platform.String()]++
+		if variant != "" {
+			if bucket.Variants == nil {
+				bucket.Variants = make(map[string]int64)
+			}
+			bucket.Variants[variant]++
+		}
+		c[linkID][key] = bucket
+	}
+}
+
+// delete removes the link's buckets and returns how many there were
+func (c clickCounters) delete(linkID string) int {
+	deleted := len(c[linkID])
+	delete(c, linkID)
+	return deleted
+}
+
+func (c clickCounters) set(linkID string, key string, bucket domain.ClickBucket) {
+	if c[linkID] == nil {
+		c[linkID] = make(map[string]domain.ClickBucket)
+	}
+	c[linkID][key] = bucket
+}
+
+// buckets returns copies of the link's buckets overlapping from..to in chronological order
+func (c clickCounters) buckets(linkID string, granularity domain.Granularity, from time.Time, to time.Time) []domain.ClickBucket {
+	prefix := string(granularity) + counterBucketSeparator
+	result := []domain.ClickBucket{}
+	for key, bucket := range c[linkID] {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if !from.IsZero() && bucket.Start.Before(granularity.BucketStart(from)) {
+			continue
+		}
+		if !to.IsZero() && bucket.Start.After(to) {
+			continue
+		}
+
+		platforms := make(map[string]int64, len(bucket.Platforms))
+		for platform, count := range bucket.Platforms {
+			platforms[platform] = count
+		}
+		bucket.Platforms = platforms
+		if bucket.Variants != nil {
+			variants := make(map[string]int64, len(bucket.Variants))
+			for variant, count := range bucket.Variants {
+				variants[variant] = count
+			}
+			bucket.Variants = variants
+		}
+		result = append(result, bucket)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+	return result
+}
+
+// MemoryAPIKeyRepository is a concurrency-safe in-memory APIKeyPort
+type MemoryAPIKeyRepository struct {
+	mu   sync.RWMutex
+	keys map[string]domain.APIKey
+}
+
+func NewMemoryAPIKeyRepository() *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{keys: make(map[string]domain.APIKey)}
+}
+
+func (m *MemoryAPIKeyRepository) All(ctx context.Context) ([]domain.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return sortedAPIKeys(m.keys), nil
+}
+
+func (m *MemoryAPIKeyRepository) Get(ctx context.Context, id string) (domain.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return getAPIKey(m.keys, id)
+}
+
+func (m *MemoryAPIKeyRepository) Create(ctx context.Context, key domain.APIKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.keys[key.Id]; exists {
+		return fmt.Errorf("API key with id '%s': %w", key.Id, domain.ErrConflict)
+	}
+	m.keys[key.Id] = key
+	return nil
+}
+
+func (m *MemoryAPIKeyRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key, err := revokeAPIKey(m.keys, id, at)
+	if err != nil {
+		return err
+	}
+	m.keys[id] = key
+	return nil
+}
+
+// MemoryLinkHistoryRepository is a concurrency-safe in-memory LinkHistoryPort
+type MemoryLinkHistoryRepository struct {
+	mu        sync.RWMutex
+	revisions linkRevisions
+}
+
+func NewMemoryLinkHistoryRepository() *MemoryLinkHistoryRepository {
+	return &MemoryLinkHistoryRepository{revisions: make(linkRevisions)}
+}
+
+func (m *MemoryLinkHistoryRepository) Append(ctx context.Context, revision domain.LinkRevision) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.revisions.append(revision)
+}
+
+func (m *MemoryLinkHistoryRepository) ByLinkID(ctx context.Context, linkID string) ([]domain.LinkRevision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.revisions.byLinkID(linkID), nil
+}
+
+// linkRevisions holds the revisions of every link id sorted by revision id
+type linkRevisions map[string][]domain.LinkRevision
+
+// append mirrors the attribute_not_exists(revision) condition of the DynamoDB repository
+func (r linkRevisions) append(revision domain.LinkRevision) error {
+	revisions := r[revision.LinkID]
+	i := sort.Search(len(revisions), func(i int) bool { return revisions[i].Id >= revision.Id })
+	if i < len(revisions) && revisions[i].Id == revision.Id {
+		return fmt.Errorf("revision '%s' of link '%s': %w", revision.Id, revision.LinkID, domain.ErrConflict)
+	}
+	revisions = append(revisions, domain.LinkRevision{})
+	copy(revisions[i+1:], revisions[i:])
+	revisions[i] = revision
+	r[revision.LinkID] = revisions
+	return nil
+}
+
+func (r linkRevisions) byLinkID(linkID string) []domain.LinkRevision {
+	return append([]domain.LinkRevision(nil), r[linkID]...)
+}
+
+// MemoryBulkJobRepository is a concurrency-safe in-memory BulkJobPort, expired jobs are
+// reported as not found like once the DynamoDB TTL removed them
+type MemoryBulkJobRepository struct {
+	mu   sync.RWMutex
+	jobs bulkJobs
+}
+
+func NewMemoryBulkJobRepository() *MemoryBulkJobRepository {
+	return &MemoryBulkJobRepository{jobs: make(bulkJobs)}
+}
+
+func (m *MemoryBulkJobRepository) Create(ctx context.Context, job domain.BulkJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.jobs.create(job)
+}
+
+func (m *MemoryBulkJobRepository) Get(ctx context.Context, id string) (domain.BulkJob, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.jobs.get(id, time.Now())
+}
+
+func (m *MemoryBulkJobRepository) AddResults(ctx context.Context, id string, offset int, results []domain.BulkItemResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := m.jobs.addResults(id, offset, results, time.Now())
+	return err
+}
+
+// bulkJobs holds every job with the results of its chunks by offset
+type bulkJobs map[string]*bulkJobState
+
+type bulkJobState struct {
+	job    domain.BulkJob
+	chunks map[int][]domain.BulkItemResult
+}
+
+// create mirrors the attribute_not_exists(job_id) condition of the DynamoDB repository
+func (b bulkJobs) create(job domain.BulkJob) error {
+	if _, exists := b[job.Id]; exists {
+		return fmt.Errorf("bulk job with id '%s': %w", job.Id, domain.ErrConflict)
+	}
+	job.Results = nil
+	b[job.Id] = &bulkJobState{job: job, chunks: make(map[int][]domain.BulkItemResult)}
+	return nil
+}
+
+func (b bulkJobs) get(id string, now time.Time) (domain.BulkJob, error) {
+	state, exists := b[id]
+	if !exists || !now.Before(state.job.ExpiresAt) {
+		return domain.BulkJob{}, fmt.Errorf("bulk job with id '%s': %w", id, domain.ErrNotFound)
+	}
+
+	offsets := make([]int, 0, len(state.chunks))
+	for offset := range state.chunks {
+		offsets = append(offsets, offset)
+	}
+	sort.Ints(offsets)
+
+	job := state.job
+	job.Results = []domain.BulkItemResult{}
+	for _, offset := range offsets {
+		job.Results = append(job.Results, state.chunks[offset]...)
+	}
+	return job, nil
+}
+
+// addResults mirrors the transaction of the DynamoDB repository, it reports whether the chunk was new
+func (b bulkJobs) addResults(id string, offset int, results []domain.BulkItemResult, at time.Time) (bool, error) {
+	state, exists := b[id]
+	if !exists {
+		return false, fmt.Errorf("bulk job with id '%s': %w", id, domain.ErrNotFound)
+	}
+	if _, recorded := state.chunks[offset]; recorded {
+		return false, nil
+	}
+
+	created, failed := countResults(results)
+	state.chunks[offset] = append([]domain.BulkItemResult(nil), results...)
+	state.job.Processed += len(results)
+	state.job.Created += created
+	state.job.Failed += failed
+	state.job.UpdatedAt = at
+	return true, nil
+}
+
+// MemoryPolicyRuleRepository is a concurrency-safe in-memory PolicyRulePort
+type MemoryPolicyRuleRepository struct {
+	mu    sync.RWMutex
+	rules []domain.PolicyRule
+}
+
+func NewMemoryPolicyRuleRepository(rules ...domain.PolicyRule) *MemoryPolicyRuleRepository {
+	return &MemoryPolicyRuleRepository{rules: rules}
+}
+
+func (m *MemoryPolicyRuleRepository) All(ctx context.Context) ([]domain.PolicyRule, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]domain.PolicyRule(nil), m.rules...), nil
+}
+
+// Set replaces the rules
+func (m *MemoryPolicyRuleRepository) Set(rules ...domain.PolicyRule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules = rules
+}
+
+func getAPIKey(keys map[string]domain.APIKey, id string) (domain.APIKey, error) {
+	key, exists := keys[id]
+	if !exists {
+		return key, fmt.Errorf("API key with id '%s': %w", id, domain.ErrNotFound)
+	}
+	return key, nil
+}
+
+// revokeAPIKey returns the revoked key without storing it, an earlier revocation time is kept
+func revokeAPIKey(keys map[string]domain.APIKey, id string, at time.Time) (domain.APIKey, error) {
+	key, err := getAPIKey(keys, id)
+	if err != nil {
+		return key, err
+	}
+	if key.RevokedAt == nil {
+		key.RevokedAt = &at
+	}
+	return key, nil
+}
+
+func sortedAPIKeys(keys map[string]domain.APIKey) []domain.APIKey {
+	result := make([]domain.APIKey, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, key)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].Id < result[j].Id
+	})
+	return result
+}
+
+func getLink(links map[string]domain.Link, id string) (domain.Link, error) {
+	link, exists := links[id]
+	if !exists {
+		return link, fmt.Errorf("link with id '%s': %w", id, domain.ErrNotFound)
+	}
+	return link, nil
+}
+
+// createLink mirrors the attribute_not_exists(id) condition of the DynamoDB repository
+func createLink(links map[string]domain.Link, link domain.Link) error {
+	if _, exists := links[link.Id]; exists {
+		return fmt.Errorf("link with id '%s': %w", link.Id, domain.ErrConflict)
+	}
+	links[link.Id] = link
+	return nil
+}
+
+// createLinks creates the links one by one, collecting the ids that are already taken
+func createLinks(ctx context.Context, links []domain.Link, create func(context.Context, domain.Link) error) ([]string, error) {
+	var taken []string
+	for _, link := range links {
+		err := create(ctx, link)
+		if errors.Is(err, domain.ErrConflict) {
+			taken = append(taken, link.Id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return taken, nil
+}
+
+// updateLinkDestination mirrors the conditional version update of the DynamoDB repository
+func updateLinkDestination(links map[string]domain.Link, link domain.Link, expectedVersion int64) error {
+	current, exists := links[link.Id]
+	if !exists || current.OwnerID != link.OwnerID {
+		return fmt.Errorf("link with id '%s': %w", link.Id, domain.ErrNotFound)
+	}
+	if current.IsDeleted() {
+		return fmt.Errorf("link with id '%s': %w", link.Id, domain.ErrDeleted)
+	}
+	if current.Version != expectedVersion {
+		return fmt.Errorf("link with id '%s' is at version %d: %w", link.Id, current.Version, domain.ErrVersionConflict)
+	}
+	current.OriginalURL = link.OriginalURL
+	current.Version = link.Version
+	current.UpdatedAt = link.UpdatedAt
+	current.UpdatedBy = link.UpdatedBy
+	current.PreviousURL = link.PreviousURL
+	links[link.Id] = current
+	return nil
+}
+
+// softDeleteLink mirrors the tombstone conditions of the DynamoDB repository
+func softDeleteLink(links map[string]domain.Link, id string, owner string, at time.Time, deletedBy string) error {
+	link, exists := links[id]
+	if !exists || link.OwnerID != owner || link.IsDeleted() {
+		return fmt.Errorf("link with id '%s': %w", id, domain.ErrNotFound)
+	}
+	// DynamoDB stores deleted_at as unix seconds
+	deletedAt := at.Truncate(time.Second)
+	link.DeletedAt = &deletedAt
+	link.DeletedBy = deletedBy
+	links[id] = link
+	return nil
+}
+
+// restoreLink mirrors the deleted_at > :after condition of the DynamoDB repository
+func restoreLink(links map[string]domain.Link, id string, owner string, deletedAfter time.Time) error {
+	link, exists := links[id]
+	if !exists || link.OwnerID != owner || !link.IsDeleted() || !link.DeletedAt.After(deletedAfter) {
+		return fmt.Errorf("deleted link with id '%s': %w", id, domain.ErrNotFound)
+	}
+	link.DeletedAt = nil
+	link.DeletedBy = ""
+	links[id] = link
+	return nil
+}
+
+// deleteLink mirrors the attribute_exists(id) and owner_id conditions of the DynamoDB repository
+func deleteLink(links map[string]domain.Link, id string, owner string) error {
+	if link, exists := links[id]; !exists || link.OwnerID != owner {
+		return fmt.Errorf("link with id '%s': %w", id, domain.ErrNotFound)
+	}
+	delete(links, id)
+	return nil
+}
+
+// incrementClicks mirrors the conditional ADD click_count of the DynamoDB repository
+func incrementClicks(links map[string]domain.Link, id string) error {
+	link, exists := links[id]
+	if !exists {
+		return fmt.Errorf("link with id '%s': %w", id, domain.ErrNotFound)
+	}
+	if link.MaxClicks > 0 && link.ClickCount >= link.MaxClicks {
+		return fmt.Errorf("link with id '%s' reached its click limit: %w", id, domain.ErrExpired)
+	}
+	link.ClickCount++
+	links[id] = link
+	return nil
+}
+
+func ownedLinks(links []domain.Link, owner string) []domain.Link {
+	result := links[:0]
+	for _, link := range links {
+		if link.OwnerID == owner {
+			result = append(result, link)
+		}
+	}
+	return result
+}
+
+// deletedLinks returns the links deleted before the given time
+func deletedLinks(links []domain.Link, before time.Time) []domain.Link {
+	var deleted []domain.Link
+	for _, link := range links {
+		if link.IsDeleted() && link.DeletedAt.Before(before) {
+			deleted = append(deleted, link)
+		}
+	}
+	return deleted
+}
+
+func sortedLinks(links map[string]domain.Link) []domain.Link {
+	result := make([]domain.Link, 0, len(links))
+	for _, link := range links {
+		result = append(result, link)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].Id < result[j].Id
+	})
+	return result
+}
+
+func getStats(stats map[string]domain.Stats, id string) (domain.Stats, error) {
+	stat, exists := stats[id]
+	if !exists {
+		return stat, fmt.Errorf("stats with id '%s': %w", id, domain.ErrNotFound)
+	}
+	return stat, nil
+}
+
+func allStats(domain.Stats) bool {
+	return true
+}
+
+// linkStatsInRange matches the stats of linkID created between from and to, a zero time leaves that side open
+func linkStatsInRange(linkID string, from time.Time, to time.Time) func(domain.Stats) bool {
+	return func(stat domain.Stats) bool {
+		if stat.LinkID != linkID {
+			return false
+		}
+		if !from.IsZero() && stat.CreatedAt.Before(from) {
+			return false
+		}
+		return to.IsZero() || !stat.CreatedAt.After(to)
+	}
+}
+
+// paginateStats pages through stats sorted by sortedStats
+func paginateStats(stats []domain.Stats, limit int32, startKey string) ([]domain.Stats, string, error) {
+	return paginate(stats, func(stat domain.Stats) (time.Time, string) { return stat.CreatedAt, stat.Id }, limit, startKey)
+}
+
+// sortedStats returns the stats matching keep in creation order
+func sortedStats(stats map[string]domain.Stats, keep func(domain.Stats) bool) []domain.Stats {
+	result := []domain.Stats{}
+	for _, stat := range stats {
+		if keep(stat) {
+			result = append(result, stat)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].Id < result[j].Id
+	})
+	return result
+}
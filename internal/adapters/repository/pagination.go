@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// startKeyValue is the JSON form of a key attribute, keys only hold strings and numbers
+type startKeyValue struct {
+	S *string `json:"S,omitempty"`
+	N *string `json:"N,omitempty"`
+}
+
+// encodeStartKey serializes a LastEvaluatedKey, returning an empty string when there are no more pages
+func encodeStartKey(key map[string]ddbtypes.AttributeValue) (string, error) {
+	if len(key) == 0 {
+		return "", nil
+	}
+
+	values := make(map[string]startKeyValue, len(key))
+	for name, attribute := range key {
+		switch v := attribute.(type) {
+		case *ddbtypes.AttributeValueMemberS:
+			values[name] = startKeyValue{S: &v.Value}
+		case *ddbtypes.AttributeValueMemberN:
+			values[name] = startKeyValue{N: &v.Value}
+		default:
+			return "", fmt.Errorf("unsupported key attribute type for '%s'", name)
+		}
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal start key: %w", err)
+	}
+	return string(data), nil
+}
+
+// decodeStartKey is the inverse of encodeStartKey, an empty string starts from the beginning
+func decodeStartKey(startKey string) (map[string]ddbtypes.AttributeValue, error) {
+	if startKey == "" {
+		return nil, nil
+	}
+
+	var values map[string]startKeyValue
+	if err := json.Unmarshal([]byte(startKey), &values); err != nil {
+		return nil, fmt.Errorf("invalid start key: %w", err)
+	}
+
+	key := make(map[string]ddbtypes.AttributeValue, len(values))
+	for name, value := range values {
+		switch {
+		case value.S != nil:
+			key[name] = &ddbtypes.AttributeValueMemberS{Value: *value.S}
+		case value.N != nil:
+			key[name] = &ddbtypes.AttributeValueMemberN{Value: *value.N}
+		default:
+			return nil, fmt.Errorf("invalid start key attribute '%s'", name)
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// PolicyRuleRepository reads the URL policy rules from a DynamoDB table keyed by rule id
+type PolicyRuleRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewPolicyRuleRepository(ctx context.Context, tableName string) (*PolicyRuleRepository, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+
+	client := dynamodb.NewFromConfig(cfg)
+	return &PolicyRuleRepository{
+		client:    client,
+		tableName: tableName,
+	}, nil
+}
+
+func (d *PolicyRuleRepository) All(ctx context.Context) ([]domain.PolicyRule, error) {
+	var rules []domain.PolicyRule
+	var lastEvaluatedKey map[string]ddbtypes.AttributeValue
+
+	// Paginate through all results
+	for {
+		input := &dynamodb.ScanInput{
+			TableName:         &d.tableName,
+			ExclusiveStartKey: lastEvaluatedKey,
+		}
+
+		result, err := d.client.Scan(ctx, input)
+		if err != nil {
+			return rules, fmt.Errorf("failed to get items from DynamoDB: %w", err)
+		}
+
+		var pageRules []domain.PolicyRule
+		err = attributevalue.UnmarshalListOfMaps(result.Items, &pageRules)
+		if err != nil {
+			return rules, fmt.Errorf("failed to unmarshal data from DynamoDB: %w", err)
+		}
+
+		rules = append(rules, pageRules...)
+
+		lastEvaluatedKey = result.LastEvaluatedKey
+		if lastEvaluatedKey == nil {
+			break
+		}
+	}
+
+	return rules, nil
+}
+
+// FilePolicyRuleRepository reads the URL policy rules from a JSON array of rules,
+// the file is read again on every call so edits are picked up on the next reload
+type FilePolicyRuleRepository struct {
+	path string
+}
+
+func NewFilePolicyRuleRepository(path string) *FilePolicyRuleRepository {
+	return &FilePolicyRuleRepository{path: path}
+}
+
+func (f *FilePolicyRuleRepository) All(ctx context.Context) ([]domain.PolicyRule, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var rules []domain.PolicyRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file: %w", err)
+	}
+	return rules, nil
+}
@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	appconfig "github.com/dheeraj-vp/golang-url-shortener/internal/config"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -42,6 +45,9 @@ func (d *StatsRepository) Get(ctx context.Context, id string) (domain.Stats, err
 	if err != nil {
 		return domain.Stats{}, fmt.Errorf("failed to get item from DynamoDB: %w", err)
 	}
+	if result.Item == nil {
+		return domain.Stats{}, fmt.Errorf("stats with id '%s': %w", id, domain.ErrNotFound)
+	}
 
 	stats := domain.Stats{}
 	err = attributevalue.UnmarshalMap(result.Item, &stats)
@@ -72,9 +78,9 @@ func (d *StatsRepository) All(ctx context.Context) ([]domain.Stats, error) {
 }
 
 func (d *StatsRepository) Create(ctx context.Context, stats domain.Stats) error {
-	item, err := attributevalue.MarshalMap(stats)
+	item, err := MarshalStats(stats)
 	if err != nil {
-		return fmt.Errorf("failed to marshal data: %w", err)
+		return err
 	}
 
 	input := &dynamodb.PutItemInput{
@@ -90,40 +96,197 @@ func (d *StatsRepository) Create(ctx context.Context, stats domain.Stats) error
 	return nil
 }
 
+// MarshalStats returns the DynamoDB item of the stats. created_at is always written in UTC, the
+// range queries of LinkStatsKeyCondition only work if every row uses the same zone
+func MarshalStats(stats domain.Stats) (map[string]ddbtypes.AttributeValue, error) {
+	stats.CreatedAt = stats.CreatedAt.UTC()
+	item, err := attributevalue.MarshalMap(stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data: %w", err)
+	}
+	return item, nil
+}
+
 func (d *StatsRepository) Delete(ctx context.Context, id string) error {
 	input := &dynamodb.DeleteItemInput{
 		TableName: &d.tableName,
 		Key: map[string]ddbtypes.AttributeValue{
 			"id": &ddbtypes.AttributeValueMemberS{Value: id},
 		},
+		ConditionExpression: aws.String("attribute_exists(id)"),
 	}
 
 	_, err := d.client.DeleteItem(ctx, input)
 	if err != nil {
+		var condCheckErr *ddbtypes.ConditionalCheckFailedException
+		if errors.As(err, &condCheckErr) {
+			return fmt.Errorf("stats with id '%s': %w", id, domain.ErrNotFound)
+		}
 		return fmt.Errorf("failed to delete item from DynamoDB: %w", err)
 	}
 	return nil
 }
 
+// DeleteByLinkID queries the link's stats and deletes them with BatchWriteItem, 25 keys per request
+func (d *StatsRepository) DeleteByLinkID(ctx context.Context, linkID string) (int, error) {
+	stats, err := d.GetStatsByLinkID(ctx, linkID)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for start := 0; start < len(stats); start += appconfig.MaxBatchWriteItems {
+		end := min(start+appconfig.MaxBatchWriteItems, len(stats))
+		requests := make([]ddbtypes.WriteRequest, 0, end-start)
+		for _, row := range stats[start:end] {
+			requests = append(requests, ddbtypes.WriteRequest{
+				DeleteRequest: &ddbtypes.DeleteRequest{
+					Key: map[string]ddbtypes.AttributeValue{
+						"id": &ddbtypes.AttributeValueMemberS{Value: row.Id},
+					},
+				},
+			})
+		}
+
+		unprocessed, err := batchWrite(ctx, d.client, d.tableName, requests)
+		deleted += len(requests) - unprocessed
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete stats of link '%s', %d deleted: %w", linkID, deleted, err)
+		}
+	}
+	return deleted, nil
+}
+
 func (d *StatsRepository) GetStatsByLinkID(ctx context.Context, linkID string) ([]domain.Stats, error) {
-	input := &dynamodb.ScanInput{
-		TableName: &d.tableName,
-		ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
-			":linkID": &ddbtypes.AttributeValueMemberS{Value: linkID},
-		},
-		FilterExpression: aws.String("link_id = :linkID"),
+	return d.GetStatsByLinkIDInRange(ctx, linkID, time.Time{}, time.Time{})
+}
+
+// GetStatsByLinkIDInRange queries the link_id/created_at index, following every page
+func (d *StatsRepository) GetStatsByLinkIDInRange(ctx context.Context, linkID string, from time.Time, to time.Time) ([]domain.Stats, error) {
+	keyCondition, values := LinkStatsKeyCondition(linkID, from, to)
+
+	stats := []domain.Stats{}
+	var lastEvaluatedKey map[string]ddbtypes.AttributeValue
+
+	// Paginate through all results
+	for {
+		input := &dynamodb.QueryInput{
+			TableName:                 &d.tableName,
+			IndexName:                 aws.String(appconfig.StatsLinkIndexName),
+			KeyConditionExpression:    aws.String(keyCondition),
+			ExpressionAttributeValues: values,
+			ExclusiveStartKey:         lastEvaluatedKey,
+		}
+
+		result, err := d.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query index: %w", err)
+		}
+
+		var pageStats []domain.Stats
+		err = attributevalue.UnmarshalListOfMaps(result.Items, &pageStats)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal data: %w", err)
+		}
+
+		stats = append(stats, filterStats(pageStats, linkStatsInRange(linkID, from, to))...)
+
+		// Check if there are more pages
+		lastEvaluatedKey = result.LastEvaluatedKey
+		if lastEvaluatedKey == nil {
+			break
+		}
 	}
 
-	result, err := d.client.Scan(ctx, input)
+	return stats, nil
+}
+
+// GetStatsPageByLinkID queries one page of the link_id/created_at index
+func (d *StatsRepository) GetStatsPageByLinkID(ctx context.Context, linkID string, from time.Time, to time.Time, limit int32, startKey string) ([]domain.Stats, string, error) {
+	lastKey, err := decodeStartKey(startKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to scan table: %w", err)
+		return nil, "", err
+	}
+
+	keyCondition, values := LinkStatsKeyCondition(linkID, from, to)
+	input := &dynamodb.QueryInput{
+		TableName:                 &d.tableName,
+		IndexName:                 aws.String(appconfig.StatsLinkIndexName),
+		KeyConditionExpression:    aws.String(keyCondition),
+		ExpressionAttributeValues: values,
+		Limit:                     aws.Int32(limit),
+		ExclusiveStartKey:         lastKey,
+	}
+
+	result, err := d.client.Query(ctx, input)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query index: %w", err)
 	}
 
 	stats := []domain.Stats{}
 	err = attributevalue.UnmarshalListOfMaps(result.Items, &stats)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal data: %w", err)
+		return nil, "", fmt.Errorf("failed to unmarshal data: %w", err)
 	}
+	// The page may come up short after dropping the stats outside the range, the next key still follows on
+	stats = filterStats(stats, linkStatsInRange(linkID, from, to))
 
-	return stats, nil
+	nextKey, err := encodeStartKey(result.LastEvaluatedKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return stats, nextKey, nil
+}
+
+// LinkStatsKeyCondition builds the index key condition of the link's stats between from and to,
+// a zero time leaves that side open.
+//
+// created_at is stored as RFC3339Nano in UTC (see MarshalStats), which drops trailing fractional zeros,
+// so the strings only sort chronologically down to the second: within a second "10:00:00.5Z" sorts
+// before "10:00:00Z". The condition therefore covers the whole seconds of from and to, and callers
+// drop the stats outside the exact range with linkStatsInRange
+func LinkStatsKeyCondition(linkID string, from time.Time, to time.Time) (string, map[string]ddbtypes.AttributeValue) {
+	keyCondition := "link_id = :linkID"
+	values := map[string]ddbtypes.AttributeValue{
+		":linkID": &ddbtypes.AttributeValueMemberS{Value: linkID},
+	}
+
+	switch {
+	case !from.IsZero() && !to.IsZero():
+		keyCondition += " AND created_at BETWEEN :from AND :to"
+		values[":from"] = &ddbtypes.AttributeValueMemberS{Value: createdAtLowerBound(from)}
+		values[":to"] = &ddbtypes.AttributeValueMemberS{Value: createdAtUpperBound(to)}
+	case !from.IsZero():
+		keyCondition += " AND created_at >= :from"
+		values[":from"] = &ddbtypes.AttributeValueMemberS{Value: createdAtLowerBound(from)}
+	case !to.IsZero():
+		keyCondition += " AND created_at <= :to"
+		values[":to"] = &ddbtypes.AttributeValueMemberS{Value: createdAtUpperBound(to)}
+	}
+	return keyCondition, values
+}
+
+// createdAtSecondLayout is RFC3339 up to the seconds, without the zone
+const createdAtSecondLayout = "2006-01-02T15:04:05"
+
+// createdAtLowerBound sorts before every created_at within the second of t
+func createdAtLowerBound(t time.Time) string {
+	return t.UTC().Format(createdAtSecondLayout)
+}
+
+// createdAtUpperBound sorts after every created_at within the second of t, "Z" comes after the "." of
+// the fractional seconds
+func createdAtUpperBound(t time.Time) string {
+	return t.UTC().Format(createdAtSecondLayout) + "Z"
+}
+
+// filterStats keeps the stats matched by keep
+func filterStats(stats []domain.Stats, keep func(domain.Stats) bool) []domain.Stats {
+	kept := stats[:0]
+	for _, stat := range stats {
+		if keep(stat) {
+			kept = append(kept, stat)
+		}
+	}
+	return kept
 }
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/uuid"
+)
+
+// toAPIGatewayRequest translates an HTTP request into the payload format 2.0
+// event API Gateway sends to the Lambda functions
+func toAPIGatewayRequest(req *http.Request, routeKey string, params map[string]string) (events.APIGatewayV2HTTPRequest, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return events.APIGatewayV2HTTPRequest{}, err
+	}
+
+	// API Gateway lowercases header names and joins repeated values with commas
+	headers := make(map[string]string, len(req.Header))
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+
+	var queryParams map[string]string
+	if query := req.URL.Query(); len(query) > 0 {
+		queryParams = make(map[string]string, len(query))
+		for name, values := range query {
+			queryParams[name] = strings.Join(values, ",")
+		}
+	}
+
+	var cookies []string
+	for _, cookie := range req.Cookies() {
+		cookies = append(cookies, cookie.String())
+	}
+
+	sourceIP, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		sourceIP = req.RemoteAddr
+	}
+
+	now := time.Now()
+	return events.APIGatewayV2HTTPRequest{
+		Version:               "2.0",
+		RouteKey:              routeKey,
+		RawPath:               req.URL.Path,
+		RawQueryString:        req.URL.RawQuery,
+		Cookies:               cookies,
+		Headers:               headers,
+		QueryStringParameters: queryParams,
+		PathParameters:        params,
+		Body:                  string(body),
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			RouteKey:   routeKey,
+			DomainName: req.Host,
+			RequestID:  uuid.NewString(),
+			Time:       now.Format("02/Jan/2006:15:04:05 -0700"),
+			TimeEpoch:  now.UnixMilli(),
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method:    req.Method,
+				Path:      req.URL.Path,
+				Protocol:  req.Proto,
+				SourceIP:  sourceIP,
+				UserAgent: req.UserAgent(),
+			},
+		},
+	}, nil
+}
+
+// writeAPIGatewayResponse writes a Lambda proxy response back as a plain HTTP response
+func writeAPIGatewayResponse(w http.ResponseWriter, response events.APIGatewayProxyResponse) {
+	for name, value := range response.Headers {
+		w.Header().Set(name, value)
+	}
+	for name, values := range response.MultiValueHeaders {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+
+	body := []byte(response.Body)
+	if response.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(response.Body)
+		if err != nil {
+			http.Error(w, "Invalid base64 response body", http.StatusInternalServerError)
+			return
+		}
+		body = decoded
+	}
+
+	statusCode := response.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
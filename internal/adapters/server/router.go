@@ -0,0 +1,97 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+)
+
+type route struct {
+	method   string
+	pattern  string
+	segments []string
+	handler  handlers.HandlerFunc
+}
+
+// Router mounts the Lambda handlers on plain net/http, using the same
+// "METHOD /path/{param}" route keys as the SAM template
+type Router struct {
+	routes []route
+}
+
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Handle registers a handler for the method and path pattern, e.g. Handle("GET", "/t/{id}", h)
+func (r *Router) Handle(method string, pattern string, handler handlers.HandlerFunc) {
+	r.routes = append(r.routes, route{
+		method:   method,
+		pattern:  pattern,
+		segments: splitPath(pattern),
+		handler:  handler,
+	})
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	pathMatched := false
+	segments := splitPath(req.URL.Path)
+
+	for _, rt := range r.routes {
+		params, ok := rt.match(segments)
+		if !ok {
+			continue
+		}
+		pathMatched = true
+		if rt.method != req.Method {
+			continue
+		}
+
+		event, err := toAPIGatewayRequest(req, rt.method+" "+rt.pattern, params)
+		if err != nil {
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		response, err := rt.handler(req.Context(), event)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeAPIGatewayResponse(w, response)
+		return
+	}
+
+	if pathMatched {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	http.NotFound(w, req)
+}
+
+// match compares the request path with the route pattern and extracts the path parameters
+func (rt route) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(rt.segments) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, segment := range rt.segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if segments[i] == "" {
+				return nil, false
+			}
+			params[segment[1:len(segment)-1]] = segments[i]
+			continue
+		}
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
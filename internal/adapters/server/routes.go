@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+)
+
+// Handlers groups the function handlers served by the API, mirroring the
+// Lambda functions declared in template.yaml
+type Handlers struct {
+	Generate *handlers.GenerateLinkFunctionHandler
+	Bulk     *handlers.BulkGenerateFunctionHandler
+	Import   *handlers.ImportFunctionHandler
+	Redirect *handlers.RedirectFunctionHandler
+	Stats    *handlers.StatsFunctionHandler
+	Export   *handlers.ExportFunctionHandler
+	Update   *handlers.UpdateLinkFunctionHandler
+	History  *handlers.HistoryFunctionHandler
+	Delete   *handlers.DeleteFunctionHandler
+	APIKeys  *handlers.APIKeyFunctionHandler
+	Auth     *handlers.Authenticator
+
+	RateLimiter   *handlers.RateLimiter
+	CreateLimit   domain.RateLimit
+	RedirectLimit domain.RateLimit
+	BulkLimit     domain.RateLimit
+}
+
+// NewAPIRouter registers every API route on a new Router
+func NewAPIRouter(h Handlers) *Router {
+	router := NewRouter()
+	router.Handle(http.MethodPut, "/generate", h.Auth.RequireScope(domain.ScopeCreate, h.RateLimiter.Limit(handlers.RateLimitCreate, h.CreateLimit, h.Generate.CreateShortLink)))
+	router.Handle(http.MethodPost, "/generate/bulk", h.Auth.RequireScope(domain.ScopeCreate, h.RateLimiter.Limit(handlers.RateLimitBulk, h.BulkLimit, h.Bulk.CreateBulk)))
+	router.Handle(http.MethodGet, "/generate/bulk/{id}", h.Auth.RequireScope(domain.ScopeCreate, h.Bulk.JobStatus))
+	router.Handle(http.MethodPost, "/import", h.Auth.RequireScope(domain.ScopeCreate, h.RateLimiter.Limit(handlers.RateLimitBulk, h.BulkLimit, h.Import.Import)))
+	router.Handle(http.MethodGet, "/t/{id}", h.RateLimiter.Limit(handlers.RateLimitRedirect, h.RedirectLimit, h.Redirect.Redirect))
+	router.Handle(http.MethodPost, "/t/{id}", h.RateLimiter.Limit(handlers.RateLimitRedirect, h.RedirectLimit, h.Redirect.Redirect))
+	router.Handle(http.MethodGet, "/stats", h.Auth.RequireScope(domain.ScopeReadStats, h.Stats.Stats))
+	router.Handle(http.MethodGet, "/stats/{id}", h.Auth.RequireScope(domain.ScopeReadStats, h.Stats.GetLinkStats))
+	router.Handle(http.MethodGet, "/export", h.Auth.RequireScope(domain.ScopeReadStats, h.Export.Export))
+	router.Handle(http.MethodPost, "/export", h.Auth.RequireScope(domain.ScopeReadStats, h.Export.Submit))
+	router.Handle(http.MethodGet, "/export/{id}", h.Auth.RequireScope(domain.ScopeReadStats, h.Export.JobStatus))
+	router.Handle(http.MethodGet, "/export/{id}/download", h.Auth.RequireScope(domain.ScopeReadStats, h.Export.Download))
+	router.Handle(http.MethodPatch, "/links/{id}", h.Auth.RequireScope(domain.ScopeUpdate, h.Update.Update))
+	router.Handle(http.MethodGet, "/links/{id}/history", h.Auth.RequireScope(domain.ScopeReadStats, h.History.History))
+	router.Handle(http.MethodPost, "/links/{id}/rollback", h.Auth.RequireScope(domain.ScopeUpdate, h.Update.Rollback))
+	router.Handle(http.MethodDelete, "/delete/{id}", h.Auth.RequireScope(domain.ScopeDelete, h.Delete.Delete))
+	router.Handle(http.MethodPost, "/links/{id}/restore", h.Auth.RequireScope(domain.ScopeDelete, h.Delete.Restore))
+	router.Handle(http.MethodPost, "/notification", handlers.HandleAPIGatewayRequest)
+	router.Handle(http.MethodPost, "/admin/keys", h.Auth.RequireScope(domain.ScopeAdmin, h.APIKeys.Create))
+	router.Handle(http.MethodGet, "/admin/keys", h.Auth.RequireScope(domain.ScopeAdmin, h.APIKeys.List))
+	router.Handle(http.MethodDelete, "/admin/keys/{id}", h.Auth.RequireScope(domain.ScopeAdmin, h.APIKeys.Revoke))
+	return router
+}
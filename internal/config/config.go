@@ -4,6 +4,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -15,6 +17,10 @@ type AppConfig struct {
 	redisDB         int    // Redis DB
 	slackToken      string // Slack token
 	slackChannelID  string // Slack channel ID
+	serverAddress   string // Listen address of the standalone HTTP server
+	storageBackend  string // Storage used by the standalone HTTP server
+	storagePath     string // File of the "file" storage backend
+	exportDir       string // Directory of export files of the standalone HTTP server
 }
 
 func NewConfig() *AppConfig {
@@ -25,6 +31,10 @@ func NewConfig() *AppConfig {
 		redisDB:         0,                   // default value
 		slackToken:      "",                  // default value
 		slackChannelID:  "",                  // default value
+		serverAddress:   ":8080",             // default value
+		storageBackend:  StorageDynamoDB,     // default value
+		storagePath:     "shortener.db",      // default value
+		exportDir:       "exports",           // default value
 	}
 }
 
@@ -70,6 +80,107 @@ func (c *AppConfig) GetStatsTableName() string {
 	return tableName
 }
 
+func (c *AppConfig) GetCounterTableName() string {
+	tableName, ok := os.LookupEnv("CounterTableName")
+	if !ok {
+		log.Printf("Warning: CounterTableName environment variable not set, using default")
+		return "" // Return empty string - caller should handle this
+	}
+	if tableName == "" {
+		log.Printf("Warning: CounterTableName is empty")
+		return ""
+	}
+	return tableName
+}
+
+func (c *AppConfig) GetAPIKeyTableName() string {
+	tableName, ok := os.LookupEnv("APIKeyTableName")
+	if !ok {
+		log.Printf("Warning: APIKeyTableName environment variable not set, using default")
+		return "" // Return empty string - caller should handle this
+	}
+	if tableName == "" {
+		log.Printf("Warning: APIKeyTableName is empty")
+		return ""
+	}
+	return tableName
+}
+
+func (c *AppConfig) GetLinkHistoryTableName() string {
+	tableName, ok := os.LookupEnv("LinkHistoryTableName")
+	if !ok {
+		log.Printf("Warning: LinkHistoryTableName environment variable not set, using default")
+		return "" // Return empty string - caller should handle this
+	}
+	if tableName == "" {
+		log.Printf("Warning: LinkHistoryTableName is empty")
+		return ""
+	}
+	return tableName
+}
+
+func (c *AppConfig) GetBulkJobTableName() string {
+	tableName, ok := os.LookupEnv("BulkJobTableName")
+	if !ok {
+		log.Printf("Warning: BulkJobTableName environment variable not set, using default")
+		return "" // Return empty string - caller should handle this
+	}
+	if tableName == "" {
+		log.Printf("Warning: BulkJobTableName is empty")
+		return ""
+	}
+	return tableName
+}
+
+// GetBulkQueueURL returns the SQS queue of bulk job chunks, empty when it's not configured
+func (c *AppConfig) GetBulkQueueURL() string {
+	return os.Getenv("BulkQueueUrl")
+}
+
+// GetExportQueueURL returns the SQS queue of export jobs, empty when it's not configured
+func (c *AppConfig) GetExportQueueURL() string {
+	return os.Getenv("ExportQueueUrl")
+}
+
+// GetExportStoreParams returns the bucket and S3-compatible endpoint export files are written to,
+// and the directory used by the standalone server when no bucket is set. An empty endpoint is AWS
+func (c *AppConfig) GetExportStoreParams() (string, string, string) {
+	dir, ok := os.LookupEnv("ExportDir")
+	if !ok || dir == "" {
+		dir = c.exportDir
+	}
+	return os.Getenv("ExportBucket"), os.Getenv("ExportEndpoint"), dir
+}
+
+func (c *AppConfig) GetPolicyTableName() string {
+	tableName, ok := os.LookupEnv("PolicyTableName")
+	if !ok {
+		log.Printf("Warning: PolicyTableName environment variable not set, using default")
+		return "" // Return empty string - caller should handle this
+	}
+	if tableName == "" {
+		log.Printf("Warning: PolicyTableName is empty")
+		return ""
+	}
+	return tableName
+}
+
+// GetPolicyFile returns the JSON file of URL policy rules, it takes precedence over the table when set
+func (c *AppConfig) GetPolicyFile() string {
+	return os.Getenv("PolicyFile")
+}
+
+// GetShortDomains returns the comma-separated domains serving our short links, which links can't point at
+func (c *AppConfig) GetShortDomains() []string {
+	var domains []string
+	for _, domain := range strings.Split(os.Getenv("ShortDomains"), ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
 func (c *AppConfig) GetRedisParams() (string, string, int) {
 	address, ok := os.LookupEnv("RedisAddress")
 	if !ok {
@@ -97,3 +208,71 @@ func (c *AppConfig) GetRedisParams() (string, string, int) {
 
 	return address, password, db
 }
+
+func (c *AppConfig) GetServerAddress() string {
+	address, ok := os.LookupEnv("ServerAddress")
+	if !ok || address == "" {
+		return c.serverAddress
+	}
+	return address
+}
+
+func (c *AppConfig) GetStorageParams() (string, string) {
+	backend, ok := os.LookupEnv("StorageBackend")
+	if !ok || backend == "" {
+		backend = c.storageBackend
+	}
+	path, ok := os.LookupEnv("StoragePath")
+	if !ok || path == "" {
+		path = c.storagePath
+	}
+	return backend, path
+}
+
+// GetCursorSecret returns the secret signing pagination cursors, empty when it's not set
+func (c *AppConfig) GetCursorSecret() string {
+	return os.Getenv("CursorSecret")
+}
+
+// GetAdminAPIKey returns the bootstrap admin token, empty when it's disabled
+func (c *AppConfig) GetAdminAPIKey() string {
+	return os.Getenv("AdminAPIKey")
+}
+
+// GetRateLimitParams returns the requests per minute allowed to each client on
+// link creation and redirects, 0 disables the limit
+func (c *AppConfig) GetRateLimitParams() (int, int) {
+	return rateLimitFromEnv("CreateRateLimit", DefaultCreateRateLimit), rateLimitFromEnv("RedirectRateLimit", DefaultRedirectRateLimit)
+}
+
+// GetBulkRateLimit returns the bulk and import requests per hour allowed to each API key, 0 disables the limit
+func (c *AppConfig) GetBulkRateLimit() int {
+	return rateLimitFromEnv("BulkRateLimit", DefaultBulkRateLimit)
+}
+
+func rateLimitFromEnv(name string, defaultLimit int) int {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return defaultLimit
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		log.Printf("Warning: %s environment variable is not a valid limit (%s), using default: %d", name, value, defaultLimit)
+		return defaultLimit
+	}
+	return limit
+}
+
+// GetDeletedLinkRetention returns how long deleted links can be restored before they are purged
+func (c *AppConfig) GetDeletedLinkRetention() time.Duration {
+	days := DefaultDeletedLinkRetentionDays
+	if value := os.Getenv("DeletedLinkRetentionDays"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			log.Printf("Warning: DeletedLinkRetentionDays environment variable is not a valid number of days (%s), using default: %d", value, days)
+		} else {
+			days = parsed
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
@@ -9,12 +9,103 @@ const (
 	MaxRetries    = 3
 )
 
+// Custom alias constants
+const (
+	MinAliasLength = 3
+	MaxAliasLength = 64
+)
+
+// MaxRedirectCacheAge is the longest links can let browsers cache their redirect
+const MaxRedirectCacheAge = 365 * 24 * time.Hour
+
+// MaxTargetRules is how many targeting rules a link can have
+const MaxTargetRules = 20
+
+// A/B split constants, visitors keep their variant in a cookie for VariantCookieMaxAge
+const (
+	MaxVariants         = 10
+	MaxVariantWeight    = 1000
+	VariantCookieName   = "variant"
+	VariantCookieMaxAge = 30 * 24 * time.Hour
+)
+
+// Password-protected link constants. A client gets MaxPasswordAttempts wrong passwords per link
+// before it's locked out for PasswordLockout
+const (
+	MinPasswordLength        = 6
+	MaxPasswordLength        = 128
+	PasswordHashIterations   = 100000
+	MaxPasswordAttempts      = 5
+	PasswordLockout          = 15 * time.Minute
+	PasswordAttemptKeyPrefix = "password:"
+)
+
+// MaxTitleLength is how long a link's title, shown on its preview page, can be
+const MaxTitleLength = 200
+
+// ReservedAliases can't be used as custom aliases (compared case-insensitively)
+var ReservedAliases = []string{
+	"admin",
+	"api",
+	"delete",
+	"generate",
+	"health",
+	"links",
+	"notification",
+	"stats",
+	"static",
+	"t",
+}
+
+// Bulk creation constants, requests above BulkSyncLimit items are processed asynchronously
+// in chunks of BulkChunkSize items
+const (
+	BulkSyncLimit    = 100
+	MaxBulkItems     = 10000
+	BulkChunkSize    = 100
+	BulkJobRetention = 7 * 24 * time.Hour
+)
+
+// MaxImportRows is how many links an import request can hold, larger exports are imported in parts
+const MaxImportRows = 1000
+
+// Export constants, exports are read ExportPageSize links at a time. Synchronous exports are
+// limited to MaxExportSyncBytes to fit in a Lambda response, larger ones have to be asynchronous
+const (
+	ExportPageSize     = 100
+	MaxExportSyncBytes = 5 << 20
+	ExportRetention    = 7 * 24 * time.Hour
+	ExportURLExpiry    = 15 * time.Minute
+)
+
 // Cache constants
 const (
 	DefaultCacheTTL = 24 * time.Hour
 	CacheKeyPrefix  = "url:"
 )
 
+// PolicyReloadInterval is how often the URL policy rules are reloaded
+const PolicyReloadInterval = 30 * time.Second
+
+// Rate limiting constants, the limits are requests per window and client
+const (
+	RateLimitKeyPrefix       = "ratelimit:"
+	RateLimitWindow          = time.Minute
+	DefaultCreateRateLimit   = 60
+	DefaultRedirectRateLimit = 600
+
+	// Bulk requests and imports create up to MaxBulkItems and MaxImportRows links each, so they share
+	// their own hourly limit
+	BulkRateLimitWindow  = time.Hour
+	DefaultBulkRateLimit = 10
+)
+
+// Deleted links can be restored for the retention period, the purge job then removes them and their stats
+const (
+	DefaultDeletedLinkRetentionDays = 30
+	PurgeInterval                   = time.Hour
+)
+
 // HTTP status codes
 const (
 	StatusCreated     = 201
@@ -26,9 +117,31 @@ const (
 
 // DynamoDB constants
 const (
-	DefaultScanLimit   = 20
-	MaxBatchGetItems   = 100
-	DefaultQueryLimit  = 50
+	DefaultScanLimit      = 20
+	MaxPageLimit          = 100
+	MaxBatchWriteItems    = 25
+	MaxTransactWriteItems = 100
+	DefaultQueryLimit     = 50
+)
+
+// BatchWriteItem retries of unprocessed items and TransactWriteItems retries of cancelled transactions,
+// the backoff doubles after every attempt
+const (
+	MaxBatchWriteRetries = 5
+	BatchWriteBackoff    = 50 * time.Millisecond
+)
+
+// DynamoDB indexes
+const (
+	StatsLinkIndexName = "link_id-created_at-index"
+	LinkOwnerIndexName = "owner_id-created_at-index"
+)
+
+// Storage backends of the standalone HTTP server
+const (
+	StorageDynamoDB = "dynamodb"
+	StorageMemory   = "memory"
+	StorageFile     = "file"
 )
 
 // Lambda constants
@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// Scope is a permission granted to an API key
+type Scope string
+
+const (
+	ScopeCreate    Scope = "create"
+	ScopeReadStats Scope = "read-stats"
+	ScopeUpdate    Scope = "update"
+	ScopeDelete    Scope = "delete"
+	ScopeAdmin     Scope = "admin" // Manages API keys and implies every other scope
+)
+
+// IsValid reports whether s is one of the known scopes
+func (s Scope) IsValid() bool {
+	switch s {
+	case ScopeCreate, ScopeReadStats, ScopeUpdate, ScopeDelete, ScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// APIKey is a credential for the management endpoints, only the hash of its secret is stored
+type APIKey struct {
+	Id        string     `dynamodbav:"id" json:"id"`
+	Name      string     `dynamodbav:"name" json:"name"`
+	Workspace string     `dynamodbav:"workspace" json:"workspace"` // Owner of the links created with the key
+	Hash      string     `dynamodbav:"hash" json:"-"`
+	Scopes    []Scope    `dynamodbav:"scopes" json:"scopes"`
+	CreatedAt time.Time  `dynamodbav:"created_at" json:"created_at"`
+	RevokedAt *time.Time `dynamodbav:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
+
+// HasScope reports whether the key grants scope
+func (k APIKey) HasScope(scope Scope) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRevoked reports whether the key has been revoked
+func (k APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BulkItem is one link of a bulk creation request
+type BulkItem struct {
+	Long  string `dynamodbav:"long" json:"long"`
+	Alias string `dynamodbav:"alias,omitempty" json:"alias,omitempty"`
+}
+
+// UnmarshalJSON also accepts a bare URL string, so a bulk request can be a plain list of URLs
+func (i *BulkItem) UnmarshalJSON(data []byte) error {
+	var long string
+	if err := json.Unmarshal(data, &long); err == nil {
+		*i = BulkItem{Long: long}
+		return nil
+	}
+
+	type bulkItem BulkItem // Drops the method to avoid recursion
+	var item bulkItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return err
+	}
+	*i = BulkItem(item)
+	return nil
+}
+
+// BulkItemStatus is the outcome of one bulk item
+type BulkItemStatus string
+
+const (
+	BulkItemCreated BulkItemStatus = "created"
+	BulkItemFailed  BulkItemStatus = "failed"
+)
+
+// BulkItemResult reports what happened to the item at Index of a bulk request
+type BulkItemResult struct {
+	Index  int            `dynamodbav:"index" json:"index"`
+	Long   string         `dynamodbav:"long" json:"long"`
+	Status BulkItemStatus `dynamodbav:"status" json:"status"`
+	Id     string         `dynamodbav:"id,omitempty" json:"id,omitempty"`
+	Error  string         `dynamodbav:"error,omitempty" json:"error,omitempty"`
+	// Reason is the policy reason code of a rejected destination
+	Reason string `dynamodbav:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// BulkJobStatus is the progress of an asynchronous bulk job
+type BulkJobStatus string
+
+const (
+	BulkJobQueued    BulkJobStatus = "queued"
+	BulkJobRunning   BulkJobStatus = "running"
+	BulkJobCompleted BulkJobStatus = "completed"
+)
+
+// BulkJob is a bulk creation request too large to be processed synchronously. Its items are
+// processed in chunks, each chunk adds its results and counts to the job
+type BulkJob struct {
+	Id        string           `dynamodbav:"job_id" json:"id"`
+	OwnerID   string           `dynamodbav:"owner_id" json:"-"`
+	CreatedBy string           `dynamodbav:"created_by,omitempty" json:"-"`
+	Total     int              `dynamodbav:"total" json:"total"`
+	Processed int              `dynamodbav:"processed" json:"processed"`
+	Created   int              `dynamodbav:"created" json:"created"`
+	Failed    int              `dynamodbav:"failed" json:"failed"`
+	CreatedAt time.Time        `dynamodbav:"created_at" json:"created_at"`
+	UpdatedAt time.Time        `dynamodbav:"updated_at" json:"updated_at"`
+	ExpiresAt time.Time        `dynamodbav:"expires_at,unixtime" json:"expires_at"` // Jobs are dropped after the retention period
+	Results   []BulkItemResult `dynamodbav:"-" json:"results"`
+}
+
+// Status derives the job's progress from its counts
+func (j BulkJob) Status() BulkJobStatus {
+	switch {
+	case j.Processed >= j.Total:
+		return BulkJobCompleted
+	case j.Processed > 0:
+		return BulkJobRunning
+	}
+	return BulkJobQueued
+}
+
+// MarshalJSON adds the derived status
+func (j BulkJob) MarshalJSON() ([]byte, error) {
+	type bulkJob BulkJob // Drops the method to avoid recursion
+	return json.Marshal(struct {
+		bulkJob
+		Status BulkJobStatus `json:"status"`
+	}{bulkJob: bulkJob(j), Status: j.Status()})
+}
+
+// BulkChunk is the queue message of a part of a bulk job, Offset is the index of its first item
+type BulkChunk struct {
+	JobID     string     `json:"job_id"`
+	OwnerID   string     `json:"owner_id"`
+	CreatedBy string     `json:"created_by,omitempty"`
+	Domain    string     `json:"domain,omitempty"` // Short domain the request came in on, for the policy's loop check
+	Offset    int        `json:"offset"`
+	Items     []BulkItem `json:"items"`
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// Granularity is the width of a click counter bucket
+type Granularity string
+
+const (
+	GranularityHour Granularity = "hour"
+	GranularityDay  Granularity = "day"
+)
+
+// BucketStart truncates t (in UTC) to the start of its bucket
+func (g Granularity) BucketStart(t time.Time) time.Time {
+	t = t.UTC()
+	if g == GranularityHour {
+		return t.Truncate(time.Hour)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ClickBucket holds the clicks of a link in one hour or day
+type ClickBucket struct {
+	Start     time.Time        `json:"start"`
+	Total     int64            `json:"total"`
+	Platforms map[string]int64 `json:"platforms"`
+	Variants  map[string]int64 `json:"variants,omitempty"` // Only set for links with A/B variants
+}
+
+// ClickSummary aggregates click buckets into totals and a time series
+type ClickSummary struct {
+	Total       int64            `json:"total"`
+	Platforms   map[string]int64 `json:"platforms"`
+	Variants    map[string]int64 `json:"variants,omitempty"`
+	Granularity Granularity      `json:"granularity,omitempty"`
+	Series      []ClickBucket    `json:"series,omitempty"`
+}
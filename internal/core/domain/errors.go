@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound is returned when the requested item doesn't exist
+	ErrNotFound = errors.New("not found")
+	// ErrVersionNotFound is returned when a link exists but never had the requested version, it is an ErrNotFound
+	ErrVersionNotFound = fmt.Errorf("version %w", ErrNotFound)
+	// ErrRevisionNotFound is returned when a link exists but wasn't live at the requested time, it is an ErrNotFound
+	ErrRevisionNotFound = fmt.Errorf("revision %w", ErrNotFound)
+	// ErrConflict is returned when an item with the same key already exists
+	ErrConflict = errors.New("already exists")
+	// ErrExpired is returned when a link is past its expiry date or click limit
+	ErrExpired = errors.New("link has expired")
+	// ErrDeleted is returned when a link has been deleted but not purged yet
+	ErrDeleted = errors.New("link has been deleted")
+	// ErrHistoryNotRecorded is returned when a link change was written but its revision couldn't be appended
+	ErrHistoryNotRecorded = errors.New("history not recorded")
+	// ErrVersionConflict is returned when an item changed since the version the caller read
+	ErrVersionConflict = errors.New("version conflict")
+	// ErrUnauthorized is returned when an API key is missing, unknown or revoked
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrWrongPassword is returned when the password of a protected link doesn't match
+	ErrWrongPassword = errors.New("wrong password")
+	// ErrLocked is returned when a client made too many wrong password attempts on a link
+	ErrLocked = errors.New("too many failed attempts")
+)
@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+// ExportKind is the data an export contains
+type ExportKind string
+
+const (
+	ExportLinks ExportKind = "links" // Links with their click totals
+	ExportStats ExportKind = "stats" // Raw click events
+)
+
+// ExportFormat is the file format of an export
+type ExportFormat string
+
+const (
+	ExportCSV    ExportFormat = "csv"
+	ExportNDJSON ExportFormat = "ndjson"
+)
+
+// ContentType returns the media type of files in the format
+func (f ExportFormat) ContentType() string {
+	if f == ExportCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+// ExportStatus is the progress of an asynchronous export
+type ExportStatus string
+
+const (
+	ExportQueued    ExportStatus = "queued"
+	ExportRunning   ExportStatus = "running"
+	ExportCompleted ExportStatus = "completed"
+)
+
+// ExportJob is an asynchronous export whose file is written to the object store
+type ExportJob struct {
+	Id          string       `json:"id"`
+	OwnerID     string       `json:"owner_id,omitempty"`
+	CreatedBy   string       `json:"created_by,omitempty"`
+	Kind        ExportKind   `json:"type"`
+	Format      ExportFormat `json:"format"`
+	From        *time.Time   `json:"from,omitempty"`
+	To          *time.Time   `json:"to,omitempty"`
+	Status      ExportStatus `json:"status"`
+	Rows        int          `json:"rows"`
+	CreatedAt   time.Time    `json:"created_at"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+	ExpiresAt   time.Time    `json:"expires_at"`             // The file and the job are deleted after this
+	DownloadURL string       `json:"download_url,omitempty"` // Filled in on completed jobs when they are read
+}
+
+// Range returns the job's time range, a zero time leaves that side open
+func (j ExportJob) Range() (time.Time, time.Time) {
+	var from, to time.Time
+	if j.From != nil {
+		from = *j.From
+	}
+	if j.To != nil {
+		to = *j.To
+	}
+	return from, to
+}
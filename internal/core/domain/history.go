@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// RevisionAction is the change that produced a link revision
+type RevisionAction string
+
+const (
+	RevisionCreated    RevisionAction = "created"
+	RevisionUpdated    RevisionAction = "updated"
+	RevisionRolledBack RevisionAction = "rolled_back"
+	RevisionDeleted    RevisionAction = "deleted"
+	RevisionRestored   RevisionAction = "restored"
+)
+
+// LinkRevision is one entry of a link's append-only history. Revisions are kept by link id
+// after the link is purged, so a recreated link's history starts after the previous one's
+type LinkRevision struct {
+	LinkID      string         `dynamodbav:"link_id" json:"link_id"`
+	Id          string         `dynamodbav:"revision" json:"id"` // Sorts chronologically within the link
+	OwnerID     string         `dynamodbav:"owner_id,omitempty" json:"-"`
+	Action      RevisionAction `dynamodbav:"action" json:"action"`
+	Version     int64          `dynamodbav:"version" json:"version"`
+	OriginalURL string         `dynamodbav:"original_url,omitempty" json:"original_url,omitempty"` // Empty for deletions
+	ChangedBy   string         `dynamodbav:"changed_by,omitempty" json:"changed_by,omitempty"`     // API key that made the change
+	ChangedAt   time.Time      `dynamodbav:"changed_at" json:"changed_at"`
+	// RestoredVersion is the version a rollback went back to
+	RestoredVersion int64 `dynamodbav:"restored_version,omitempty" json:"restored_version,omitempty"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+// ImportStatus is the outcome of one imported row
+type ImportStatus string
+
+const (
+	ImportImported ImportStatus = "imported" // Or would be in a dry run
+	ImportSkipped  ImportStatus = "skipped"  // The row is invalid
+	ImportConflict ImportStatus = "conflict" // The id is already taken
+)
+
+// ImportResult reports what happened to the row at Line of an import
+type ImportResult struct {
+	Line   int          `json:"line"`
+	Id     string       `json:"id"`
+	Long   string       `json:"long"`
+	Status ImportStatus `json:"status"`
+	Error  string       `json:"error,omitempty"`
+	// Reason is the policy reason code of a rejected destination
+	Reason string `json:"reason,omitempty"`
+}
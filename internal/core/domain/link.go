@@ -3,8 +3,52 @@ package domain
 import "time"
 
 type Link struct {
-	Id          string    `dynamodbav:"id" json:"id"`
-	OriginalURL string    `dynamodbav:"original_url" json:"original_url"`
-	CreatedAt   time.Time `dynamodbav:"created_at" json:"created_at"`
-	Stats       []Stats   `dynamodbav:"-" json:"stats"`
+	Id             string        `dynamodbav:"id" json:"id"`
+	OwnerID        string        `dynamodbav:"owner_id,omitempty" json:"owner_id,omitempty"` // Workspace of the API key that created the link
+	OriginalURL    string        `dynamodbav:"original_url" json:"original_url"`
+	Title          string        `dynamodbav:"title,omitempty" json:"title,omitempty"` // Shown on the preview page
+	CreatedAt      time.Time     `dynamodbav:"created_at" json:"created_at"`
+	CreatedBy      string        `dynamodbav:"created_by,omitempty" json:"created_by,omitempty"`          // API key that created the link
+	ExpiresAt      *time.Time    `dynamodbav:"expires_at,omitempty,unixtime" json:"expires_at,omitempty"` // Also the DynamoDB TTL attribute
+	MaxClicks      int64         `dynamodbav:"max_clicks,omitempty" json:"max_clicks,omitempty"`
+	ClickCount     int64         `dynamodbav:"click_count,omitempty" json:"click_count,omitempty"`
+	RedirectStatus int           `dynamodbav:"redirect_status,omitempty" json:"redirect_status,omitempty"` // 301, 302, 307 or 308, zero redirects with 302
+	CacheMaxAge    int64         `dynamodbav:"cache_max_age,omitempty" json:"cache_max_age,omitempty"`     // Seconds browsers may cache the redirect, zero disables caching
+	Targets        []TargetRule  `dynamodbav:"targets,omitempty" json:"targets,omitempty"`                 // Checked in order before redirecting to OriginalURL
+	Variants       []Variant     `dynamodbav:"variants,omitempty" json:"variants,omitempty"`               // Visitors no target matches are split over these instead of OriginalURL
+	PasswordHash   string        `dynamodbav:"password_hash,omitempty" json:"-"`                           // Set on protected links, never returned by the API
+	Preview        bool          `dynamodbav:"preview,omitempty" json:"preview,omitempty"`                 // Visitors see the preview page before being redirected
+	Version        int64         `dynamodbav:"version,omitempty" json:"version"`                           // Bumped by every update, links created before updates existed are version 0
+	UpdatedAt      *time.Time    `dynamodbav:"updated_at,omitempty" json:"updated_at,omitempty"`
+	UpdatedBy      string        `dynamodbav:"updated_by,omitempty" json:"updated_by,omitempty"`          // API key of the last update
+	PreviousURL    string        `dynamodbav:"previous_url,omitempty" json:"previous_url,omitempty"`      // Destination before the last update
+	DeletedAt      *time.Time    `dynamodbav:"deleted_at,omitempty,unixtime" json:"deleted_at,omitempty"` // Set while the link waits to be purged
+	DeletedBy      string        `dynamodbav:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+	Clicks         *ClickSummary `dynamodbav:"-" json:"clicks,omitempty"`
+}
+
+// IsExpired reports whether the link has passed its expiry date or used up its clicks
+func (l Link) IsExpired(now time.Time) bool {
+	if l.ExpiresAt != nil && !now.Before(*l.ExpiresAt) {
+		return true
+	}
+	return l.MaxClicks > 0 && l.ClickCount >= l.MaxClicks
+}
+
+// IsProtected reports whether visitors need a password to follow the link
+func (l Link) IsProtected() bool {
+	return l.PasswordHash != ""
+}
+
+// IsDeleted reports whether the link has been deleted and can only be restored
+func (l Link) IsDeleted() bool {
+	return l.DeletedAt != nil
+}
+
+// RemainingLifetime returns how long the link stays valid, or zero if it never expires by date
+func (l Link) RemainingLifetime(now time.Time) time.Duration {
+	if l.ExpiresAt == nil {
+		return 0
+	}
+	return l.ExpiresAt.Sub(now)
 }
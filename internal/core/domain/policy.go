@@ -0,0 +1,55 @@
+package domain
+
+import "fmt"
+
+// PolicyAction is what happens to URLs matching a policy rule
+type PolicyAction string
+
+const (
+	PolicyAllow PolicyAction = "allow"
+	PolicyDeny  PolicyAction = "deny"
+)
+
+// PolicyRuleType is how a rule's pattern is matched
+type PolicyRuleType string
+
+const (
+	// PolicyRuleDomain matches the host and its subdomains
+	PolicyRuleDomain PolicyRuleType = "domain"
+	// PolicyRuleWildcard matches the host against a glob such as "*.example.com"
+	PolicyRuleWildcard PolicyRuleType = "wildcard"
+	// PolicyRuleRegex matches the whole URL against a regular expression
+	PolicyRuleRegex PolicyRuleType = "regex"
+)
+
+// PolicyRule allows or denies the URLs matching its pattern. Once any allow rule exists,
+// only URLs matching one of them can be shortened, deny rules always win
+type PolicyRule struct {
+	Id      string         `dynamodbav:"id" json:"id"`
+	Type    PolicyRuleType `dynamodbav:"type" json:"type"`
+	Pattern string         `dynamodbav:"pattern" json:"pattern"`
+	Action  PolicyAction   `dynamodbav:"action" json:"action"`
+	// Reason overrides the reason code reported for URLs denied by the rule
+	Reason string `dynamodbav:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// Reason codes of policy violations
+const (
+	ReasonInvalidURL        = "invalid_url"
+	ReasonBlockedScheme     = "blocked_scheme"
+	ReasonShortURLLoop      = "short_url_loop"
+	ReasonPrivateAddress    = "private_address"
+	ReasonBlockedDomain     = "blocked_domain"
+	ReasonDomainNotAllowed  = "domain_not_allowed"
+	ReasonPolicyUnavailable = "policy_unavailable" // The rules couldn't be loaded
+)
+
+// PolicyViolation is returned for URLs the policy doesn't allow to be shortened
+type PolicyViolation struct {
+	Reason  string `json:"reason"`
+	Message string `json:"error"`
+}
+
+func (v *PolicyViolation) Error() string {
+	return fmt.Sprintf("policy violation (%s): %s", v.Reason, v.Message)
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// RateLimit allows each client Requests per sliding Window, zero requests disables the limit
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// IsUnlimited reports whether the limit is disabled
+func (l RateLimit) IsUnlimited() bool {
+	return l.Requests <= 0 || l.Window <= 0
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Redirect is how a link redirects, it's what the cache holds for each link
+type Redirect struct {
+	URL         string       `json:"url"`
+	Status      int          `json:"status,omitempty"`
+	CacheMaxAge int64        `json:"cache_max_age,omitempty"`
+	Targets     []TargetRule `json:"targets,omitempty"`
+	Variants    []Variant    `json:"variants,omitempty"`
+	// PasswordHash is set on protected links, which are only redirected once the password is checked
+	PasswordHash string `json:"password_hash,omitempty"`
+	// Preview links show the preview page and only redirect once the visitor continues from it
+	Preview bool `json:"preview,omitempty"`
+	// MaxClicks is set when the click of a protected or preview link still has to be counted, once the
+	// visitor gave the password or continued. Links with a click limit aren't cached
+	MaxClicks int64 `json:"-"`
+}
+
+// Variant is one destination of an A/B split, visitors are spread over the variants by weight
+type Variant struct {
+	Name   string `dynamodbav:"name" json:"name"`
+	URL    string `dynamodbav:"url" json:"url"`
+	Weight int    `dynamodbav:"weight" json:"weight"`
+}
+
+// Redirect returns how the link redirects
+func (l Link) Redirect() Redirect {
+	return Redirect{
+		URL:          l.OriginalURL,
+		Status:       l.RedirectStatus,
+		CacheMaxAge:  l.CacheMaxAge,
+		Targets:      l.Targets,
+		Variants:     l.Variants,
+		PasswordHash: l.PasswordHash,
+		Preview:      l.Preview,
+	}
+}
+
+// IsProtected reports whether the redirect needs a password
+func (r Redirect) IsProtected() bool {
+	return r.PasswordHash != ""
+}
+
+// Target returns the first targeting rule the visitor matches
+func (r Redirect) Target(v Visitor) (TargetRule, bool) {
+	for _, rule := range r.Targets {
+		if rule.Matches(v) {
+			return rule, true
+		}
+	}
+	return TargetRule{}, false
+}
+
+// Variant returns the variant with the name
+func (r Redirect) Variant(name string) (Variant, bool) {
+	for _, variant := range r.Variants {
+		if variant.Name == name {
+			return variant, true
+		}
+	}
+	return Variant{}, false
+}
+
+// PickVariant maps point, any number, onto the variants by weight. The same point always picks
+// the same variant as long as the variants don't change
+func (r Redirect) PickVariant(point uint64) Variant {
+	var total uint64
+	for _, variant := range r.Variants {
+		total += uint64(variant.Weight)
+	}
+	if total == 0 {
+		return Variant{}
+	}
+	point %= total
+	for _, variant := range r.Variants {
+		if point < uint64(variant.Weight) {
+			return variant
+		}
+		point -= uint64(variant.Weight)
+	}
+	return r.Variants[len(r.Variants)-1]
+}
+
+// StatusCode returns the redirect's status code, a temporary 302 unless the link chose another
+func (r Redirect) StatusCode() int {
+	if r.Status == 0 {
+		return http.StatusFound
+	}
+	return r.Status
+}
+
+// CacheControl returns the redirect's Cache-Control header. Redirects aren't cached unless the link
+// allows it, a browser that caches one skips us on repeat visits and those clicks aren't counted,
+// and protected links never are
+func (r Redirect) CacheControl() string {
+	if r.CacheMaxAge <= 0 || r.IsProtected() {
+		return "no-store"
+	}
+	return fmt.Sprintf("public, max-age=%d", r.CacheMaxAge)
+}
+
+// IsValidRedirectStatus reports whether a link can redirect with the status code
+func IsValidRedirectStatus(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
@@ -28,5 +28,6 @@ type Stats struct {
 	Id        string    `dynamodbav:"id" json:"id"`
 	Platform  Platform  `dynamodbav:"platform" json:"platform"`
 	LinkID    string    `dynamodbav:"link_id" json:"link_id"`
+	Variant   string    `dynamodbav:"variant,omitempty" json:"variant,omitempty"` // A/B variant that was served, if the link has any
 	CreatedAt time.Time `dynamodbav:"created_at" json:"created_at"`
 }
@@ -0,0 +1,86 @@
+package domain
+
+import "strings"
+
+// Operating systems and devices targeting rules can match
+const (
+	OSiOS     = "ios"
+	OSAndroid = "android"
+	OSWindows = "windows"
+	OSMacOS   = "macos"
+	OSLinux   = "linux"
+
+	DeviceMobile  = "mobile" // Phones and tablets
+	DeviceDesktop = "desktop"
+)
+
+// Visitor is what targeting rules know about the client following a link
+type Visitor struct {
+	Platform Platform
+	OS       string
+	Device   string
+	Language string // Most preferred Accept-Language tag, lowercased
+}
+
+// TargetRule sends visitors matching all of its conditions to URL instead of the link's destination.
+// Empty conditions match everyone, but a rule has at least one
+type TargetRule struct {
+	Platform string `dynamodbav:"platform,omitempty" json:"platform,omitempty"` // instagram, twitter or youtube
+	OS       string `dynamodbav:"os,omitempty" json:"os,omitempty"`             // ios, android, windows, macos or linux
+	Device   string `dynamodbav:"device,omitempty" json:"device,omitempty"`     // mobile or desktop
+	Language string `dynamodbav:"language,omitempty" json:"language,omitempty"` // "en" also matches "en-us"
+	URL      string `dynamodbav:"url" json:"url"`
+}
+
+// Matches reports whether the visitor meets all of the rule's conditions
+func (r TargetRule) Matches(v Visitor) bool {
+	if r.Platform != "" && !strings.EqualFold(r.Platform, v.Platform.String()) {
+		return false
+	}
+	if r.OS != "" && !strings.EqualFold(r.OS, v.OS) {
+		return false
+	}
+	if r.Device != "" && !strings.EqualFold(r.Device, v.Device) {
+		return false
+	}
+	if r.Language != "" {
+		language := strings.ToLower(r.Language)
+		if v.Language != language && !strings.HasPrefix(v.Language, language+"-") {
+			return false
+		}
+	}
+	return true
+}
+
+// IsEmpty reports whether the rule has no conditions
+func (r TargetRule) IsEmpty() bool {
+	return r.Platform == "" && r.OS == "" && r.Device == "" && r.Language == ""
+}
+
+// IsValidTargetPlatform reports whether rules can match the platform name
+func IsValidTargetPlatform(name string) bool {
+	for _, platform := range []Platform{PlatformInstagram, PlatformTwitter, PlatformYouTube} {
+		if strings.EqualFold(name, platform.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidTargetOS reports whether rules can match the operating system
+func IsValidTargetOS(os string) bool {
+	switch strings.ToLower(os) {
+	case OSiOS, OSAndroid, OSWindows, OSMacOS, OSLinux:
+		return true
+	}
+	return false
+}
+
+// IsValidTargetDevice reports whether rules can match the device
+func IsValidTargetDevice(device string) bool {
+	switch strings.ToLower(device) {
+	case DeviceMobile, DeviceDesktop:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+)
+
+type APIKeyPort interface {
+	All(context.Context) ([]domain.APIKey, error)
+	Get(context.Context, string) (domain.APIKey, error)
+	Create(context.Context, domain.APIKey) error
+	// Revoke marks the key as revoked at the given time, keeping the first revocation time
+	Revoke(context.Context, string, time.Time) error
+}
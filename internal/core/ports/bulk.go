@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+)
+
+type BulkJobPort interface {
+	Create(context.Context, domain.BulkJob) error
+	// Get returns the job with the results recorded so far, ordered by item index
+	Get(context.Context, string) (domain.BulkJob, error)
+	// AddResults records the results of the chunk of the job starting at the given offset and adds them
+	// to the job's counts. A chunk that was already recorded is ignored, so redelivered chunks count once
+	AddResults(context.Context, string, int, []domain.BulkItemResult) error
+}
+
+// BulkQueue hands bulk job chunks to the worker that processes them
+type BulkQueue interface {
+	Enqueue(context.Context, domain.BulkChunk) error
+}
@@ -1,9 +1,16 @@
 package ports
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Cache interface {
 	Set(context.Context, string, string) error
+	SetWithTTL(context.Context, string, string, time.Duration) error
 	Get(context.Context, string) (string, error)
 	Delete(context.Context, string) error
+	// Increment atomically adds one to the counter at the key and returns the new count. A new
+	// counter expires after ttl, incrementing it doesn't extend it
+	Increment(context.Context, string, time.Duration) (int64, error)
 }
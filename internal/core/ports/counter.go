@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+)
+
+// CounterPort keeps pre-aggregated click counters per link, bucketed by hour and day
+type CounterPort interface {
+	// Increment counts one click of the platform and the A/B variant, if any, in both the hour and the day bucket of the given time, all or nothing
+	Increment(context.Context, string, domain.Platform, string, time.Time) error
+	// GetBuckets returns the link's non-empty buckets overlapping from..to in chronological order, a zero time leaves that side open
+	GetBuckets(context.Context, string, domain.Granularity, time.Time, time.Time) ([]domain.ClickBucket, error)
+	// DeleteByLinkID deletes every bucket of the link and returns how many were deleted
+	DeleteByLinkID(context.Context, string) (int, error)
+}
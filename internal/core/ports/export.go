@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+)
+
+// ObjectStore keeps files by key, like an S3 bucket
+type ObjectStore interface {
+	// Put writes the object, reading body until EOF. An object is only visible once it's completely written
+	Put(context.Context, string, string, io.Reader) error
+	// Get opens the object, a missing key is domain.ErrNotFound
+	Get(context.Context, string) (io.ReadCloser, error)
+	// URL returns a link that downloads the object directly for the given duration,
+	// or an empty string when the store can only be read through Get
+	URL(context.Context, string, time.Duration) (string, error)
+}
+
+// ExportQueue hands export jobs to the worker that writes their files
+type ExportQueue interface {
+	Enqueue(context.Context, domain.ExportJob) error
+}
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+)
+
+type LinkHistoryPort interface {
+	// Append records a revision, existing revisions are never changed
+	Append(context.Context, domain.LinkRevision) error
+	// ByLinkID returns every revision recorded for the link id, oldest first
+	ByLinkID(context.Context, string) ([]domain.LinkRevision, error)
+}
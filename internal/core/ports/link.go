@@ -2,13 +2,32 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 )
 
 type LinkPort interface {
 	All(context.Context) ([]domain.Link, error)
+	// DeletedBefore returns the soft-deleted links whose deletion is older than the given time
+	DeletedBefore(context.Context, time.Time) ([]domain.Link, error)
+	// AllByOwner returns up to limit of the owner's links after the start key and the key of the next page, empty on the last one
+	AllByOwner(context.Context, string, int32, string) ([]domain.Link, string, error)
 	Get(context.Context, string) (domain.Link, error)
 	Create(context.Context, domain.Link) error
-	Delete(context.Context, string) error
+	// CreateBatch creates the links whose ids are free and returns the ids that were already taken,
+	// those links aren't written. Like Create it never overwrites an id claimed concurrently
+	CreateBatch(context.Context, []domain.Link) ([]string, error)
+	// Update writes the destination, version and audit fields of the link if it still has the given version and
+	// the link's owner, failing with domain.ErrVersionConflict if it changed and domain.ErrNotFound if it's gone
+	Update(context.Context, domain.Link, int64) error
+	// SoftDelete marks the link with the given id and owner as deleted at the given time by the given key,
+	// a missing, already deleted or another owner's link is reported as not found
+	SoftDelete(context.Context, string, string, time.Time, string) error
+	// Restore clears the deletion of the link with the given id and owner if it was deleted after the given
+	// time, failing with domain.ErrNotFound otherwise
+	Restore(context.Context, string, string, time.Time) error
+	// Delete removes the link with the given id and owner, another owner's link is reported as not found
+	Delete(context.Context, string, string) error
+	IncrementClicks(context.Context, string) error
 }
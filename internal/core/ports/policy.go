@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+)
+
+type PolicyRulePort interface {
+	All(context.Context) ([]domain.PolicyRule, error)
+}
@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+)
+
+type RateLimiter interface {
+	// Allow counts a request for the client key, and returns false and how long to wait once the limit is reached
+	Allow(context.Context, string, domain.RateLimit) (bool, time.Duration, error)
+}
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 )
@@ -11,5 +12,12 @@ type StatsPort interface {
 	Get(context.Context, string) (domain.Stats, error)
 	Create(context.Context, domain.Stats) error
 	Delete(context.Context, string) error
+	// DeleteByLinkID deletes every stats row of the link and returns how many were deleted
+	DeleteByLinkID(context.Context, string) (int, error)
 	GetStatsByLinkID(context.Context, string) ([]domain.Stats, error)
+	// GetStatsByLinkIDInRange returns the link's stats created between from and to (inclusive), a zero time leaves that side open
+	GetStatsByLinkIDInRange(context.Context, string, time.Time, time.Time) ([]domain.Stats, error)
+	// GetStatsPageByLinkID returns up to limit of the link's stats in the range after the start key, in creation
+	// order, and the start key of the next page. An empty start key is the first page, an empty next key the last
+	GetStatsPageByLinkID(context.Context, string, time.Time, time.Time, int32, string) ([]domain.Stats, string, error)
 }
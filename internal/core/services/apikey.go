@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/ports"
+)
+
+// BootstrapKeyID identifies the admin key configured through the environment, its workspace
+// is the empty one holding the links created before workspaces existed
+const BootstrapKeyID = "bootstrap"
+
+type APIKeyService struct {
+	port           ports.APIKeyPort
+	bootstrapToken string
+}
+
+// NewAPIKeyService creates the service, bootstrapToken is an optional admin token
+// that is never stored and is used to create the first keys
+func NewAPIKeyService(p ports.APIKeyPort, bootstrapToken string) *APIKeyService {
+	return &APIKeyService{port: p, bootstrapToken: bootstrapToken}
+}
+
+func (service *APIKeyService) GetAll(ctx context.Context) ([]domain.APIKey, error) {
+	keys, err := service.port.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Create stores a new key and returns it with its token, which can't be recovered later.
+// Tokens have the form "<id>.<secret>"
+func (service *APIKeyService) Create(ctx context.Context, name string, workspace string, scopes []domain.Scope) (domain.APIKey, string, error) {
+	id, err := randomString(8, hex.EncodeToString)
+	if err != nil {
+		return domain.APIKey{}, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	secret, err := randomString(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return domain.APIKey{}, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	key := domain.APIKey{
+		Id:        id,
+		Name:      name,
+		Workspace: workspace,
+		Hash:      hashSecret(secret),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	}
+	if err := service.port.Create(ctx, key); err != nil {
+		return domain.APIKey{}, "", fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return key, id + "." + secret, nil
+}
+
+// Authenticate returns the key a token belongs to, failing with domain.ErrUnauthorized
+// for malformed, unknown and revoked tokens
+func (service *APIKeyService) Authenticate(ctx context.Context, token string) (domain.APIKey, error) {
+	if service.bootstrapToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(service.bootstrapToken)) == 1 {
+		return domain.APIKey{Id: BootstrapKeyID, Name: BootstrapKeyID, Scopes: []domain.Scope{domain.ScopeAdmin}}, nil
+	}
+
+	id, secret, ok := strings.Cut(token, ".")
+	if !ok || id == "" || secret == "" {
+		return domain.APIKey{}, fmt.Errorf("malformed API key: %w", domain.ErrUnauthorized)
+	}
+
+	key, err := service.port.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.APIKey{}, fmt.Errorf("unknown API key '%s': %w", id, domain.ErrUnauthorized)
+		}
+		return domain.APIKey{}, fmt.Errorf("failed to get API key '%s': %w", id, err)
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(key.Hash)) != 1 {
+		return domain.APIKey{}, fmt.Errorf("invalid secret for API key '%s': %w", id, domain.ErrUnauthorized)
+	}
+	if key.IsRevoked() {
+		return domain.APIKey{}, fmt.Errorf("API key '%s' is revoked: %w", id, domain.ErrUnauthorized)
+	}
+
+	return key, nil
+}
+
+func (service *APIKeyService) Revoke(ctx context.Context, id string) error {
+	if err := service.port.Revoke(ctx, id, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke API key '%s': %w", id, err)
+	}
+	return nil
+}
+
+func randomString(size int, encode func([]byte) string) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/ports"
+)
+
+type BulkJobService struct {
+	port      ports.BulkJobPort
+	queue     ports.BulkQueue
+	retention time.Duration
+}
+
+// NewBulkJobService creates the service, jobs and their results are kept for the retention period
+func NewBulkJobService(p ports.BulkJobPort, q ports.BulkQueue, retention time.Duration) *BulkJobService {
+	return &BulkJobService{port: p, queue: q, retention: retention}
+}
+
+// Submit stores a job for the chunks and queues them, returning the job. The chunks' job
+// id is filled in. A chunk that can't be queued fails the request, the chunks queued before
+// it are still processed and the job stays incomplete until it expires
+func (service *BulkJobService) Submit(ctx context.Context, owner string, createdBy string, chunks []domain.BulkChunk) (domain.BulkJob, error) {
+	id, err := randomString(8, hex.EncodeToString)
+	if err != nil {
+		return domain.BulkJob{}, fmt.Errorf("failed to generate bulk job id: %w", err)
+	}
+
+	now := time.Now()
+	job := domain.BulkJob{
+		Id:        id,
+		OwnerID:   owner,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+		ExpiresAt: now.Add(service.retention),
+		Results:   []domain.BulkItemResult{},
+	}
+	for _, chunk := range chunks {
+		job.Total += len(chunk.Items)
+	}
+
+	if err := service.port.Create(ctx, job); err != nil {
+		return domain.BulkJob{}, fmt.Errorf("failed to create bulk job: %w", err)
+	}
+	for _, chunk := range chunks {
+		chunk.JobID = id
+		chunk.OwnerID = owner
+		chunk.CreatedBy = createdBy
+		if err := service.queue.Enqueue(ctx, chunk); err != nil {
+			return domain.BulkJob{}, fmt.Errorf("failed to queue chunk at offset %d of bulk job '%s': %w", chunk.Offset, id, err)
+		}
+	}
+	return job, nil
+}
+
+// GetOwned returns the job with its results only if it belongs to owner, another owner's job is reported as not found
+func (service *BulkJobService) GetOwned(ctx context.Context, id string, owner string) (domain.BulkJob, error) {
+	job, err := service.port.Get(ctx, id)
+	if err != nil {
+		return domain.BulkJob{}, fmt.Errorf("failed to get bulk job '%s': %w", id, err)
+	}
+	if job.OwnerID != owner {
+		return domain.BulkJob{}, fmt.Errorf("bulk job with id '%s' in workspace '%s': %w", id, owner, domain.ErrNotFound)
+	}
+	return job, nil
+}
+
+// Complete records the results of a processed chunk, recording the same chunk again has no effect
+func (service *BulkJobService) Complete(ctx context.Context, chunk domain.BulkChunk, results []domain.BulkItemResult) error {
+	if err := service.port.AddResults(ctx, chunk.JobID, chunk.Offset, results); err != nil {
+		return fmt.Errorf("failed to record bulk job results: %w", err)
+	}
+	return nil
+}
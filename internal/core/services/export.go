@@ -0,0 +1,144 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/ports"
+)
+
+// ExportService keeps asynchronous exports in the object store, each job is a JSON object
+// next to its file under the owner's prefix so no other storage is needed
+type ExportService struct {
+	store     ports.ObjectStore
+	queue     ports.ExportQueue
+	retention time.Duration
+	urlExpiry time.Duration
+}
+
+// NewExportService creates the service, jobs and their files are kept for the retention period
+// and download links handed out for completed jobs are valid for urlExpiry
+func NewExportService(s ports.ObjectStore, q ports.ExportQueue, retention time.Duration, urlExpiry time.Duration) *ExportService {
+	return &ExportService{store: s, queue: q, retention: retention, urlExpiry: urlExpiry}
+}
+
+// Submit stores the job and queues it, returning it with its id, status and dates filled in
+func (service *ExportService) Submit(ctx context.Context, job domain.ExportJob) (domain.ExportJob, error) {
+	id, err := randomString(8, hex.EncodeToString)
+	if err != nil {
+		return domain.ExportJob{}, fmt.Errorf("failed to generate export id: %w", err)
+	}
+
+	now := time.Now()
+	job.Id = id
+	job.Status = domain.ExportQueued
+	job.CreatedAt = now
+	job.ExpiresAt = now.Add(service.retention)
+
+	if err := service.save(ctx, job); err != nil {
+		return domain.ExportJob{}, err
+	}
+	if err := service.queue.Enqueue(ctx, job); err != nil {
+		return domain.ExportJob{}, fmt.Errorf("failed to queue export '%s': %w", id, err)
+	}
+	return job, nil
+}
+
+// GetOwned returns the job only if it belongs to owner, another owner's or an expired job is reported as
+// not found. Completed jobs come with a download link when the store can provide one
+func (service *ExportService) GetOwned(ctx context.Context, id string, owner string) (domain.ExportJob, error) {
+	if _, err := hex.DecodeString(id); err != nil || id == "" {
+		return domain.ExportJob{}, fmt.Errorf("export with id '%s': %w", id, domain.ErrNotFound)
+	}
+
+	object, err := service.store.Get(ctx, exportKey(owner, id, "json"))
+	if err != nil {
+		return domain.ExportJob{}, fmt.Errorf("failed to get export '%s': %w", id, err)
+	}
+	defer object.Close()
+
+	var job domain.ExportJob
+	if err := json.NewDecoder(object).Decode(&job); err != nil {
+		return domain.ExportJob{}, fmt.Errorf("failed to decode export '%s': %w", id, err)
+	}
+	if !time.Now().Before(job.ExpiresAt) {
+		return domain.ExportJob{}, fmt.Errorf("export with id '%s' expired: %w", id, domain.ErrNotFound)
+	}
+
+	if job.Status == domain.ExportCompleted {
+		job.DownloadURL, err = service.store.URL(ctx, exportKey(owner, id, string(job.Format)), service.urlExpiry)
+		if err != nil {
+			return domain.ExportJob{}, fmt.Errorf("failed to get download URL of export '%s': %w", id, err)
+		}
+	}
+	return job, nil
+}
+
+// OpenFile opens the file of a completed job, which the caller must close
+func (service *ExportService) OpenFile(ctx context.Context, job domain.ExportJob) (io.ReadCloser, error) {
+	file, err := service.store.Get(ctx, exportKey(job.OwnerID, job.Id, string(job.Format)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file of export '%s': %w", job.Id, err)
+	}
+	return file, nil
+}
+
+// Start marks the job as running
+func (service *ExportService) Start(ctx context.Context, job domain.ExportJob) error {
+	job.Status = domain.ExportRunning
+	return service.save(ctx, job)
+}
+
+// Complete streams the rows written by write into the job's file and marks the job as completed.
+// When write or the upload fails nothing is stored and the job keeps its status
+func (service *ExportService) Complete(ctx context.Context, job domain.ExportJob, write func(io.Writer) (int, error)) (domain.ExportJob, error) {
+	reader, writer := io.Pipe()
+	rows := make(chan int, 1)
+	go func() {
+		n, err := write(writer)
+		writer.CloseWithError(err)
+		rows <- n
+	}()
+
+	err := service.store.Put(ctx, exportKey(job.OwnerID, job.Id, string(job.Format)), job.Format.ContentType(), reader)
+	// Unblocks write if the upload stopped reading early
+	reader.CloseWithError(err)
+	job.Rows = <-rows
+	if err != nil {
+		return job, fmt.Errorf("failed to write file of export '%s': %w", job.Id, err)
+	}
+
+	now := time.Now()
+	job.Status = domain.ExportCompleted
+	job.CompletedAt = &now
+	return job, service.save(ctx, job)
+}
+
+// save writes the job's JSON object
+func (service *ExportService) save(ctx context.Context, job domain.ExportJob) error {
+	job.DownloadURL = ""
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+	if err := service.store.Put(ctx, exportKey(job.OwnerID, job.Id, "json"), "application/json", bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to save export '%s': %w", job.Id, err)
+	}
+	return nil
+}
+
+// exportKey is the object key of a job's file with the given extension, under its owner's prefix
+// so jobs can only be read by their owner. Links without an owner export under "_"
+func exportKey(owner string, id string, extension string) string {
+	if owner == "" {
+		owner = "_"
+	}
+	return "exports/" + url.PathEscape(owner) + "/" + id + "." + extension
+}
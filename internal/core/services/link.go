@@ -2,20 +2,27 @@ package services
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/ports"
 )
 
 type LinkService struct {
-	port  ports.LinkPort
-	cache ports.Cache
+	port    ports.LinkPort
+	cache   ports.Cache
+	history ports.LinkHistoryPort
 }
 
-func NewLinkService(p ports.LinkPort, c ports.Cache) *LinkService {
-	return &LinkService{port: p, cache: c}
+func NewLinkService(p ports.LinkPort, c ports.Cache, h ports.LinkHistoryPort) *LinkService {
+	return &LinkService{port: p, cache: c, history: h}
 }
 
 func (service *LinkService) GetAll(ctx context.Context) ([]domain.Link, error) {
@@ -26,35 +33,133 @@ func (service *LinkService) GetAll(ctx context.Context) ([]domain.Link, error) {
 	return links, nil
 }
 
+func (service *LinkService) Get(ctx context.Context, id string) (domain.Link, error) {
+	link, err := service.port.Get(ctx, id)
+	if err != nil {
+		return domain.Link{}, fmt.Errorf("failed to get link for identifier '%s': %w", id, err)
+	}
+	return link, nil
+}
+
+// GetOwned returns the link only if it belongs to owner, another owner's link is reported as not found
+func (service *LinkService) GetOwned(ctx context.Context, id string, owner string) (domain.Link, error) {
+	link, err := service.Get(ctx, id)
+	if err != nil {
+		return domain.Link{}, err
+	}
+	if link.OwnerID != owner {
+		return domain.Link{}, fmt.Errorf("link with id '%s' in workspace '%s': %w", id, owner, domain.ErrNotFound)
+	}
+	return link, nil
+}
+
+// GetPage returns up to limit of the owner's links after startKey and the start key of the next page
+func (service *LinkService) GetPage(ctx context.Context, owner string, limit int32, startKey string) ([]domain.Link, string, error) {
+	links, nextKey, err := service.port.AllByOwner(ctx, owner, limit, startKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get links: %w", err)
+	}
+	return links, nextKey, nil
+}
+
 func (service *LinkService) GetOriginalURL(ctx context.Context, shortLinkKey string) (*string, error) {
+	redirect, err := service.GetRedirect(ctx, shortLinkKey)
+	if err != nil {
+		return nil, err
+	}
+	return &redirect.URL, nil
+}
+
+// GetRedirect returns how the link redirects, counting the click of links with a click limit
+func (service *LinkService) GetRedirect(ctx context.Context, shortLinkKey string) (domain.Redirect, error) {
 	// Try cache first (cache-aside pattern)
-	cachedURL, err := service.cache.Get(ctx, shortLinkKey)
-	if err == nil && cachedURL != "" {
+	cached, err := service.cache.Get(ctx, shortLinkKey)
+	if err == nil && cached != "" {
 		// Cache hit
 		log.Printf("Cache hit for key: %s", shortLinkKey)
-		return &cachedURL, nil
+		return decodeRedirect(cached), nil
+	}
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		log.Printf("Failed to read cache for key '%s': %v", shortLinkKey, err)
 	}
 
 	// Cache miss - fetch from database
 	log.Printf("Cache miss for key: %s, fetching from database", shortLinkKey)
-	data, err := service.port.Get(ctx, shortLinkKey)
+	data, err := service.GetActive(ctx, shortLinkKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get short URL for identifier '%s': %w", shortLinkKey, err)
+		return domain.Redirect{}, err
 	}
 
-	// Validate link exists and has URL
-	if data.OriginalURL == "" {
-		return nil, fmt.Errorf("link '%s' not found or has no URL", shortLinkKey)
+	// Links with a click limit are never cached, every visit has to be counted. Visits to protected and
+	// preview links are counted by CountClick once the visitor gave the password or continued
+	if data.MaxClicks > 0 {
+		redirect := data.Redirect()
+		if data.IsProtected() || data.Preview {
+			redirect.MaxClicks = data.MaxClicks
+			return redirect, nil
+		}
+		if err := service.CountClick(ctx, shortLinkKey); err != nil {
+			return domain.Redirect{}, err
+		}
+		return redirect, nil
 	}
 
 	// Populate cache asynchronously to avoid blocking the response
-	go func() {
-		if err := service.cache.Set(context.Background(), shortLinkKey, data.OriginalURL); err != nil {
-			log.Printf("Failed to populate cache for key '%s': %v", shortLinkKey, err)
-		}
-	}()
+	go service.populateCache(data)
+
+	return data.Redirect(), nil
+}
+
+// GetActive returns a link that can still be followed, without counting a click
+func (service *LinkService) GetActive(ctx context.Context, shortLinkKey string) (domain.Link, error) {
+	data, err := service.port.Get(ctx, shortLinkKey)
+	if err != nil {
+		return domain.Link{}, fmt.Errorf("failed to get short URL for identifier '%s': %w", shortLinkKey, err)
+	}
+
+	if data.IsDeleted() {
+		return domain.Link{}, fmt.Errorf("link '%s': %w", shortLinkKey, domain.ErrDeleted)
+	}
+
+	// DynamoDB TTL deletes items lazily, so expired links can still be returned
+	if data.IsExpired(time.Now()) {
+		return domain.Link{}, fmt.Errorf("link '%s': %w", shortLinkKey, domain.ErrExpired)
+	}
+	return data, nil
+}
+
+// CountClick counts a visit to a link with a click limit, failing with domain.ErrExpired once it's used up
+func (service *LinkService) CountClick(ctx context.Context, shortLinkKey string) error {
+	if err := service.port.IncrementClicks(ctx, shortLinkKey); err != nil {
+		return fmt.Errorf("failed to count click for identifier '%s': %w", shortLinkKey, err)
+	}
+	return nil
+}
+
+// CheckPassword checks the password a client gave for a protected link. Attempts are counted per link
+// and client in the cache until the right password resets them, and after config.MaxPasswordAttempts the
+// client gets domain.ErrLocked without the password being checked until config.PasswordLockout has passed
+// since its first attempt
+func (service *LinkService) CheckPassword(ctx context.Context, shortLinkKey string, client string, redirect domain.Redirect, password string) error {
+	key := config.PasswordAttemptKeyPrefix + shortLinkKey + ":" + client
 
-	return &data.OriginalURL, nil
+	// Every attempt is counted before the password is checked, atomically, so parallel guesses can't
+	// all get in under the limit. Like the rate limiter, an unreachable cache doesn't lock everyone out
+	attempts, err := service.cache.Increment(ctx, key, config.PasswordLockout)
+	if err != nil {
+		log.Printf("Failed to count password attempt for key '%s': %v", shortLinkKey, err)
+	}
+	if attempts > config.MaxPasswordAttempts {
+		return fmt.Errorf("link '%s': %w", shortLinkKey, domain.ErrLocked)
+	}
+
+	if !checkPassword(redirect.PasswordHash, password) {
+		return fmt.Errorf("link '%s': %w", shortLinkKey, domain.ErrWrongPassword)
+	}
+	if err := service.cache.Delete(ctx, key); err != nil {
+		log.Printf("Failed to reset password attempts for key '%s': %v", shortLinkKey, err)
+	}
+	return nil
 }
 
 func (service *LinkService) Create(ctx context.Context, link domain.Link) error {
@@ -62,29 +167,365 @@ func (service *LinkService) Create(ctx context.Context, link domain.Link) error
 	if err := service.port.Create(ctx, link); err != nil {
 		return fmt.Errorf("failed to create short URL: %w", err)
 	}
+	historyErr := service.record(ctx, domain.LinkRevision{
+		LinkID:      link.Id,
+		OwnerID:     link.OwnerID,
+		Action:      domain.RevisionCreated,
+		Version:     link.Version,
+		OriginalURL: link.OriginalURL,
+		ChangedBy:   link.CreatedBy,
+		ChangedAt:   link.CreatedAt,
+	})
 
-	// Populate cache asynchronously
-	go func() {
-		if err := service.cache.Set(context.Background(), link.Id, link.OriginalURL); err != nil {
-			log.Printf("Failed to populate cache for new link '%s': %v", link.Id, err)
+	if link.MaxClicks == 0 {
+		// Populate cache asynchronously
+		go service.populateCache(link)
+	}
+
+	return historyErr
+}
+
+// CreateBatch creates the links in as few writes as possible and returns the ids that were already
+// taken, those links aren't created. The cache is left to be filled by the first redirects. Links whose
+// history couldn't be recorded are still created and reported with domain.ErrHistoryNotRecorded
+func (service *LinkService) CreateBatch(ctx context.Context, links []domain.Link) ([]string, error) {
+	taken, err := service.port.CreateBatch(ctx, links)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create short URLs: %w", err)
+	}
+
+	skip := make(map[string]bool, len(taken))
+	for _, id := range taken {
+		skip[id] = true
+	}
+	var historyErrs []error
+	for _, link := range links {
+		if skip[link.Id] {
+			continue
 		}
-	}()
+		historyErrs = append(historyErrs, service.record(ctx, domain.LinkRevision{
+			LinkID:      link.Id,
+			OwnerID:     link.OwnerID,
+			Action:      domain.RevisionCreated,
+			Version:     link.Version,
+			OriginalURL: link.OriginalURL,
+			ChangedBy:   link.CreatedBy,
+			ChangedAt:   link.CreatedAt,
+		}))
+	}
+	return taken, errors.Join(historyErrs...)
+}
 
-	return nil
+// populateCache stores how the link redirects in the cache, never for longer than the link itself lives
+func (service *LinkService) populateCache(link domain.Link) {
+	value, err := encodeRedirect(link.Redirect())
+	if err != nil {
+		log.Printf("Failed to encode cache value for key '%s': %v", link.Id, err)
+		return
+	}
+	if link.ExpiresAt == nil {
+		err = service.cache.Set(context.Background(), link.Id, value)
+	} else if ttl := link.RemainingLifetime(time.Now()); ttl > 0 {
+		err = service.cache.SetWithTTL(context.Background(), link.Id, value, ttl)
+	}
+	if err != nil {
+		log.Printf("Failed to populate cache for key '%s': %v", link.Id, err)
+	}
 }
 
-func (service *LinkService) Delete(ctx context.Context, short string) error {
-	// Delete from database
-	if err := service.port.Delete(ctx, short); err != nil {
-		return fmt.Errorf("failed to delete short URL for identifier '%s': %w", short, err)
+// encodeRedirect returns the cache value of a redirect. Plain redirects are stored as their URL, which
+// is also what the cache held before links had redirect options
+func encodeRedirect(redirect domain.Redirect) (string, error) {
+	if redirect.Status == 0 && redirect.CacheMaxAge == 0 && len(redirect.Targets) == 0 && len(redirect.Variants) == 0 && !redirect.IsProtected() && !redirect.Preview {
+		return redirect.URL, nil
 	}
+	value, err := json.Marshal(redirect)
+	return string(value), err
+}
 
-	// Delete from cache asynchronously
-	go func() {
-		if err := service.cache.Delete(context.Background(), short); err != nil {
-			log.Printf("Failed to delete from cache for key '%s': %v", short, err)
+// decodeRedirect reads a cache value written by encodeRedirect
+func decodeRedirect(value string) domain.Redirect {
+	var redirect domain.Redirect
+	if strings.HasPrefix(value, "{") && json.Unmarshal([]byte(value), &redirect) == nil {
+		return redirect
+	}
+	return domain.Redirect{URL: value}
+}
+
+// Update points the owner's link at a new destination if it's still at version, and returns the updated link.
+// The previous destination is kept on the link and in the link's history
+func (service *LinkService) Update(ctx context.Context, id string, owner string, originalURL string, version int64, updatedBy string) (domain.Link, error) {
+	return service.update(ctx, id, owner, originalURL, version, updatedBy, 0)
+}
+
+// Rollback points the owner's link back at the destination it had at toVersion, if it's still at version.
+// Only versions since the link was last created can be restored
+func (service *LinkService) Rollback(ctx context.Context, id string, owner string, toVersion int64, version int64, updatedBy string) (domain.Link, error) {
+	target, err := service.RollbackTarget(ctx, id, owner, toVersion)
+	if err != nil {
+		return domain.Link{}, err
+	}
+	return service.RollbackTo(ctx, target, version, updatedBy)
+}
+
+// RollbackTarget returns the revision a rollback of the owner's link to toVersion restores, so its
+// destination can be checked before rolling back
+func (service *LinkService) RollbackTarget(ctx context.Context, id string, owner string, toVersion int64) (domain.LinkRevision, error) {
+	revisions, err := service.History(ctx, id, owner)
+	if err != nil {
+		return domain.LinkRevision{}, err
+	}
+
+	var target *domain.LinkRevision
+	for i, revision := range revisions {
+		if revision.Action == domain.RevisionCreated {
+			// Versions restart when a purged link is recreated
+			target = nil
+		}
+		if revision.Action != domain.RevisionDeleted && revision.Version == toVersion {
+			target = &revisions[i]
 		}
-	}()
+	}
+	if target == nil {
+		return domain.LinkRevision{}, fmt.Errorf("version %d of link with id '%s': %w", toVersion, id, domain.ErrVersionNotFound)
+	}
+	return *target, nil
+}
+
+// RollbackTo points the link of a revision returned by RollbackTarget back at its destination, if it's still at version
+func (service *LinkService) RollbackTo(ctx context.Context, target domain.LinkRevision, version int64, updatedBy string) (domain.Link, error) {
+	return service.update(ctx, target.LinkID, target.OwnerID, target.OriginalURL, version, updatedBy, target.Version)
+}
+
+// History returns the revisions of the owner's link id oldest first, including the ones from before it was deleted.
+// Links created before history was recorded get the revisions untrackedHistory rebuilds from the link
+func (service *LinkService) History(ctx context.Context, id string, owner string) ([]domain.LinkRevision, error) {
+	revisions, err := service.history.ByLinkID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history for identifier '%s': %w", id, err)
+	}
+
+	owned := revisions[:0]
+	for _, revision := range revisions {
+		if revision.OwnerID == owner {
+			owned = append(owned, revision)
+		}
+	}
+	if len(owned) == 0 {
+		link, err := service.GetOwned(ctx, id, owner)
+		if err != nil {
+			return nil, fmt.Errorf("history of link with id '%s' in workspace '%s': %w", id, owner, err)
+		}
+		return untrackedHistory(link), nil
+	}
+	return owned, nil
+}
+
+// untrackedHistory rebuilds the history of a link without recorded revisions from what the link itself
+// keeps: its creation, or the version before its last edit, then that edit and its deletion
+func untrackedHistory(link domain.Link) []domain.LinkRevision {
+	revisions := []domain.LinkRevision{{
+		LinkID:      link.Id,
+		OwnerID:     link.OwnerID,
+		Action:      domain.RevisionCreated,
+		Version:     link.Version,
+		OriginalURL: link.OriginalURL,
+		ChangedBy:   link.CreatedBy,
+		ChangedAt:   link.CreatedAt,
+	}}
+	if link.UpdatedAt != nil && link.PreviousURL != "" {
+		revisions[0].Version = link.Version - 1
+		revisions[0].OriginalURL = link.PreviousURL
+		revisions = append(revisions, domain.LinkRevision{
+			LinkID:      link.Id,
+			OwnerID:     link.OwnerID,
+			Action:      domain.RevisionUpdated,
+			Version:     link.Version,
+			OriginalURL: link.OriginalURL,
+			ChangedBy:   link.UpdatedBy,
+			ChangedAt:   *link.UpdatedAt,
+		})
+	}
+	if link.DeletedAt != nil {
+		revisions = append(revisions, domain.LinkRevision{
+			LinkID:    link.Id,
+			OwnerID:   link.OwnerID,
+			Action:    domain.RevisionDeleted,
+			Version:   link.Version,
+			ChangedBy: link.DeletedBy,
+			ChangedAt: *link.DeletedAt,
+		})
+	}
+	return revisions
+}
+
+// RevisionAt returns the revision of the owner's link that was live at the given time,
+// failing with domain.ErrRevisionNotFound if the link didn't exist then
+func (service *LinkService) RevisionAt(ctx context.Context, id string, owner string, at time.Time) (domain.LinkRevision, error) {
+	revisions, err := service.History(ctx, id, owner)
+	if err != nil {
+		return domain.LinkRevision{}, err
+	}
+
+	var live *domain.LinkRevision
+	for i := range revisions {
+		if revisions[i].ChangedAt.After(at) {
+			break
+		}
+		live = &revisions[i]
+	}
+	if live == nil || live.Action == domain.RevisionDeleted {
+		return domain.LinkRevision{}, fmt.Errorf("link with id '%s' at %s: %w", id, at.Format(time.RFC3339), domain.ErrRevisionNotFound)
+	}
+	return *live, nil
+}
 
+func (service *LinkService) update(ctx context.Context, id string, owner string, originalURL string, version int64, updatedBy string, restoredVersion int64) (domain.Link, error) {
+	link, err := service.GetOwned(ctx, id, owner)
+	if err != nil {
+		return domain.Link{}, err
+	}
+	if link.IsDeleted() {
+		return domain.Link{}, fmt.Errorf("link with id '%s': %w", id, domain.ErrDeleted)
+	}
+	if link.Version != version {
+		return domain.Link{}, fmt.Errorf("link with id '%s' is at version %d: %w", id, link.Version, domain.ErrVersionConflict)
+	}
+
+	now := time.Now()
+	updated := link
+	updated.PreviousURL = link.OriginalURL
+	updated.OriginalURL = originalURL
+	updated.Version = version + 1
+	updated.UpdatedAt = &now
+	updated.UpdatedBy = updatedBy
+
+	if err := service.port.Update(ctx, updated, version); err != nil {
+		return domain.Link{}, fmt.Errorf("failed to update short URL for identifier '%s': %w", id, err)
+	}
+	log.Printf("Link '%s' updated to version %d by '%s': '%s' -> '%s'", id, updated.Version, updatedBy, updated.PreviousURL, updated.OriginalURL)
+
+	revision := domain.LinkRevision{
+		LinkID:      id,
+		OwnerID:     owner,
+		Action:      domain.RevisionUpdated,
+		Version:     updated.Version,
+		OriginalURL: updated.OriginalURL,
+		ChangedBy:   updatedBy,
+		ChangedAt:   now,
+	}
+	if restoredVersion > 0 {
+		revision.Action = domain.RevisionRolledBack
+		revision.RestoredVersion = restoredVersion
+	}
+	historyErr := service.record(ctx, revision)
+
+	// Invalidate before returning, a stale cache entry would keep redirecting to the old destination
+	if err := service.cache.Delete(ctx, id); err != nil {
+		log.Printf("Failed to delete from cache for key '%s': %v", id, err)
+	}
+
+	return updated, historyErr
+}
+
+// Delete tombstones the owner's link: it stops redirecting right away but keeps its stats,
+// and can be restored until it is purged
+func (service *LinkService) Delete(ctx context.Context, short string, owner string, deletedBy string) error {
+	link, err := service.GetOwned(ctx, short, owner)
+	if err != nil {
+		return err
+	}
+	if link.IsDeleted() {
+		return fmt.Errorf("link with id '%s': %w", short, domain.ErrDeleted)
+	}
+
+	now := time.Now()
+	if err := service.port.SoftDelete(ctx, short, owner, now, deletedBy); err != nil {
+		return fmt.Errorf("failed to delete short URL for identifier '%s': %w", short, err)
+	}
+	historyErr := service.record(ctx, domain.LinkRevision{
+		LinkID:    short,
+		OwnerID:   owner,
+		Action:    domain.RevisionDeleted,
+		Version:   link.Version,
+		ChangedBy: deletedBy,
+		ChangedAt: now,
+	})
+
+	// Invalidate before returning, a cached destination would keep redirecting
+	if err := service.cache.Delete(ctx, short); err != nil {
+		log.Printf("Failed to delete from cache for key '%s': %v", short, err)
+	}
+
+	return historyErr
+}
+
+// Restore brings back the owner's link if it was deleted after deletedAfter, links deleted
+// earlier fail with domain.ErrExpired as they are due to be purged
+func (service *LinkService) Restore(ctx context.Context, id string, owner string, deletedAfter time.Time, restoredBy string) (domain.Link, error) {
+	link, err := service.GetOwned(ctx, id, owner)
+	if err != nil {
+		return domain.Link{}, err
+	}
+	if !link.IsDeleted() {
+		return domain.Link{}, fmt.Errorf("deleted link with id '%s': %w", id, domain.ErrNotFound)
+	}
+	if !link.DeletedAt.After(deletedAfter) {
+		return domain.Link{}, fmt.Errorf("link with id '%s' was deleted at %s and can't be restored: %w", id, link.DeletedAt.Format(time.RFC3339), domain.ErrExpired)
+	}
+
+	if err := service.port.Restore(ctx, id, owner, deletedAfter); err != nil {
+		return domain.Link{}, fmt.Errorf("failed to restore short URL for identifier '%s': %w", id, err)
+	}
+	historyErr := service.record(ctx, domain.LinkRevision{
+		LinkID:      id,
+		OwnerID:     owner,
+		Action:      domain.RevisionRestored,
+		Version:     link.Version,
+		OriginalURL: link.OriginalURL,
+		ChangedBy:   restoredBy,
+		ChangedAt:   time.Now(),
+	})
+
+	link.DeletedAt = nil
+	link.DeletedBy = ""
+	return link, historyErr
+}
+
+// DeletedBefore returns the links deleted before the given time, which can't be restored anymore
+func (service *LinkService) DeletedBefore(ctx context.Context, before time.Time) ([]domain.Link, error) {
+	links, err := service.port.DeletedBefore(ctx, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted links: %w", err)
+	}
+	return links, nil
+}
+
+// Purge permanently removes a deleted link, its history is kept
+func (service *LinkService) Purge(ctx context.Context, link domain.Link) error {
+	if !link.IsDeleted() {
+		return fmt.Errorf("link with id '%s' isn't deleted and can't be purged", link.Id)
+	}
+	if err := service.port.Delete(ctx, link.Id, link.OwnerID); err != nil {
+		return fmt.Errorf("failed to purge short URL for identifier '%s': %w", link.Id, err)
+	}
+	return nil
+}
+
+// revisionTimeLayout is fixed-width so revision ids sort chronologically
+const revisionTimeLayout = "20060102T150405.000000000Z"
+
+// record appends a revision to the link's history. The link change has already been written and can't
+// be undone, so a failure is returned as domain.ErrHistoryNotRecorded for the caller to report
+func (service *LinkService) record(ctx context.Context, revision domain.LinkRevision) error {
+	suffix, err := randomString(4, hex.EncodeToString)
+	if err == nil {
+		revision.Id = revision.ChangedAt.UTC().Format(revisionTimeLayout) + "-" + suffix
+		err = service.history.Append(ctx, revision)
+	}
+	if err != nil {
+		log.Printf("Failed to record %s revision %d of link '%s': %v", revision.Action, revision.Version, revision.LinkID, err)
+		return fmt.Errorf("%s revision %d of link '%s': %w: %w", revision.Action, revision.Version, revision.LinkID, domain.ErrHistoryNotRecorded, err)
+	}
 	return nil
 }
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/config"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// passwordHashScheme prefixes stored password hashes, "pbkdf2-sha256$<iterations>$<salt>$<key>"
+const passwordHashScheme = "pbkdf2-sha256"
+
+// HashPassword returns a salted PBKDF2 hash of a link's password. Unlike API key secrets, passwords
+// are picked by people and guessable, so the hash is deliberately slow
+func HashPassword(password string) (string, error) {
+	salt, err := randomString(16, base64.RawStdEncoding.EncodeToString)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	key := pbkdf2.Key([]byte(password), []byte(salt), config.PasswordHashIterations, sha256.Size, sha256.New)
+	return strings.Join([]string{passwordHashScheme, strconv.Itoa(config.PasswordHashIterations), salt, base64.RawStdEncoding.EncodeToString(key)}, "$"), nil
+}
+
+// checkPassword reports whether the password matches a hash made by HashPassword
+func checkPassword(hash string, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != passwordHashScheme {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return false
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	key := pbkdf2.Key([]byte(password), []byte(parts[2]), iterations, len(expected), sha256.New)
+	return subtle.ConstantTimeCompare(key, expected) == 1
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/ports"
+)
+
+// allowedSchemes are the only schemes links can redirect to
+var allowedSchemes = map[string]bool{"http": true, "https": true}
+
+// PolicyService decides which URLs can be shortened. Rules are reloaded from the port
+// at most once per reload interval, so edits to the rules apply without a redeploy
+type PolicyService struct {
+	port           ports.PolicyRulePort
+	shortDomains   []string
+	reloadInterval time.Duration
+
+	mu       sync.Mutex
+	rules    []compiledRule
+	loadedAt time.Time
+}
+
+type compiledRule struct {
+	domain.PolicyRule
+	regex *regexp.Regexp
+}
+
+// NewPolicyService creates the service, shortDomains are the hosts serving our own short links
+func NewPolicyService(p ports.PolicyRulePort, shortDomains []string, reloadInterval time.Duration) *PolicyService {
+	return &PolicyService{port: p, shortDomains: shortDomains, reloadInterval: reloadInterval}
+}
+
+// Check returns a *domain.PolicyViolation when the URL can't be shortened. requestDomain is the
+// host the request was sent to, which also serves short links
+func (service *PolicyService) Check(ctx context.Context, rawURL string, requestDomain string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return &domain.PolicyViolation{Reason: domain.ReasonInvalidURL, Message: "Invalid URL format"}
+	}
+	if !allowedSchemes[strings.ToLower(parsed.Scheme)] {
+		return &domain.PolicyViolation{Reason: domain.ReasonBlockedScheme, Message: fmt.Sprintf("Scheme '%s' is not allowed", parsed.Scheme)}
+	}
+	if parsed.Hostname() == "" {
+		return &domain.PolicyViolation{Reason: domain.ReasonInvalidURL, Message: "Invalid URL format"}
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	for _, shortDomain := range append([]string{requestDomain}, service.shortDomains...) {
+		if shortDomain != "" && matchesDomain(host, shortDomain) {
+			return &domain.PolicyViolation{Reason: domain.ReasonShortURLLoop, Message: "URL points back at this shortener"}
+		}
+	}
+	if isPrivateHost(host) {
+		return &domain.PolicyViolation{Reason: domain.ReasonPrivateAddress, Message: "URL points at a private or loopback address"}
+	}
+
+	rules, err := service.currentRules(ctx)
+	if err != nil {
+		// Fail closed, without rules any URL would pass
+		return &domain.PolicyViolation{Reason: domain.ReasonPolicyUnavailable, Message: "URL policy is unavailable, try again later"}
+	}
+	allowListed, allowed := false, false
+	for _, rule := range rules {
+		matched := rule.matches(host, rawURL)
+		if matched && rule.Action == domain.PolicyDeny {
+			reason := rule.Reason
+			if reason == "" {
+				reason = domain.ReasonBlockedDomain
+			}
+			return &domain.PolicyViolation{Reason: reason, Message: "URL is blocked by policy"}
+		}
+		if rule.Action == domain.PolicyAllow {
+			allowListed = true
+			allowed = allowed || matched
+		}
+	}
+	if allowListed && !allowed {
+		return &domain.PolicyViolation{Reason: domain.ReasonDomainNotAllowed, Message: "URL is not on the allow list"}
+	}
+
+	return nil
+}
+
+// currentRules reloads the rules once they are older than the reload interval,
+// keeping the previous rules when the reload fails. It only fails while no load has succeeded yet
+func (service *PolicyService) currentRules(ctx context.Context) ([]compiledRule, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if !service.loadedAt.IsZero() && time.Since(service.loadedAt) < service.reloadInterval {
+		return service.rules, nil
+	}
+
+	rules, err := service.port.All(ctx)
+	if err != nil {
+		if service.loadedAt.IsZero() {
+			return nil, fmt.Errorf("failed to load policy rules: %w", err)
+		}
+		log.Printf("Error loading policy rules, keeping %d previous rules: %v", len(service.rules), err)
+		// Retry on the next interval rather than on every request
+		service.loadedAt = time.Now()
+		return service.rules, nil
+	}
+
+	service.rules = compileRules(rules)
+	service.loadedAt = time.Now()
+	return service.rules, nil
+}
+
+func compileRules(rules []domain.PolicyRule) []compiledRule {
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Action != domain.PolicyAllow && rule.Action != domain.PolicyDeny {
+			log.Printf("Skipping policy rule '%s' with unknown action '%s'", rule.Id, rule.Action)
+			continue
+		}
+
+		c := compiledRule{PolicyRule: rule}
+		switch rule.Type {
+		case domain.PolicyRuleDomain, domain.PolicyRuleWildcard:
+			c.Pattern = strings.TrimSuffix(strings.ToLower(rule.Pattern), ".")
+			if _, err := path.Match(c.Pattern, ""); err != nil {
+				log.Printf("Skipping policy rule '%s' with invalid pattern: %v", rule.Id, err)
+				continue
+			}
+		case domain.PolicyRuleRegex:
+			regex, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				log.Printf("Skipping policy rule '%s' with invalid regex: %v", rule.Id, err)
+				continue
+			}
+			c.regex = regex
+		default:
+			log.Printf("Skipping policy rule '%s' with unknown type '%s'", rule.Id, rule.Type)
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled
+}
+
+func (r compiledRule) matches(host string, rawURL string) bool {
+	switch r.Type {
+	case domain.PolicyRuleDomain:
+		return matchesDomain(host, r.Pattern)
+	case domain.PolicyRuleWildcard:
+		matched, _ := path.Match(r.Pattern, host)
+		return matched
+	case domain.PolicyRuleRegex:
+		return r.regex.MatchString(rawURL)
+	}
+	return false
+}
+
+// matchesDomain reports whether host is parent or one of its subdomains
+func matchesDomain(host string, parent string) bool {
+	parent = strings.TrimSuffix(strings.ToLower(parent), ".")
+	if h, _, err := net.SplitHostPort(parent); err == nil {
+		parent = h
+	}
+	return host == parent || strings.HasSuffix(host, "."+parent)
+}
+
+// isPrivateHost reports whether host is localhost or a loopback, private, link-local or
+// unspecified IP. Hostnames aren't resolved, the redirect goes to the visitor's browser
+func isPrivateHost(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	if ip == nil {
+		var ok bool
+		if ip, ok = parseNumericIPv4(host); !ok {
+			return false
+		}
+	}
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// parseNumericIPv4 parses the IPv4 forms browsers accept besides dotted decimal, like inet_aton:
+// 1-4 parts in decimal, octal with a leading 0 or hex with 0x, the last part filling the remaining
+// bytes, so 2130706433, 0x7f000001, 0177.0.0.1 and 127.1 are all 127.0.0.1
+func parseNumericIPv4(host string) (net.IP, bool) {
+	parts := strings.Split(host, ".")
+	if len(parts) > 4 {
+		return nil, false
+	}
+
+	var value uint64
+	for i, part := range parts {
+		base := 10
+		switch {
+		case len(part) > 1 && (part[:2] == "0x" || part[:2] == "0X"):
+			base, part = 16, part[2:]
+		case len(part) > 1 && part[0] == '0':
+			base, part = 8, part[1:]
+		}
+		var n uint64
+		if part != "" {
+			var err error
+			if n, err = strconv.ParseUint(part, base, 32); err != nil {
+				return nil, false
+			}
+		} else if base != 16 {
+			return nil, false
+		}
+
+		// Every part but the last is one byte, the last fills the rest
+		bits := uint(8 * (4 - i))
+		if i < len(parts)-1 {
+			bits = 8
+		}
+		if n >= 1<<bits {
+			return nil, false
+		}
+		if i < len(parts)-1 {
+			value |= n << uint(8*(3-i))
+		} else {
+			value |= n
+		}
+	}
+	return net.IPv4(byte(value>>24), byte(value>>16), byte(value>>8), byte(value)), true
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/ports"
+)
+
+type RateLimitService struct {
+	limiter ports.RateLimiter
+}
+
+func NewRateLimitService(l ports.RateLimiter) *RateLimitService {
+	return &RateLimitService{limiter: l}
+}
+
+// Allow counts a request from client against the bucket's limit, and returns how long
+// the client has to wait when it's over the limit
+func (s *RateLimitService) Allow(ctx context.Context, bucket string, client string, limit domain.RateLimit) (bool, time.Duration, error) {
+	if limit.IsUnlimited() {
+		return true, 0, nil
+	}
+
+	allowed, retryAfter, err := s.limiter.Allow(ctx, bucket+":"+client, limit)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	return allowed, retryAfter, nil
+}
@@ -3,18 +3,20 @@ package services
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/ports"
 )
 
 type StatsService struct {
-	port  ports.StatsPort
-	cache ports.Cache
+	port     ports.StatsPort
+	counters ports.CounterPort
+	cache    ports.Cache
 }
 
-func NewStatsService(p ports.StatsPort, c ports.Cache) *StatsService {
-	return &StatsService{port: p, cache: c}
+func NewStatsService(p ports.StatsPort, counters ports.CounterPort, c ports.Cache) *StatsService {
+	return &StatsService{port: p, counters: counters, cache: c}
 }
 
 func (service *StatsService) All(ctx context.Context) ([]domain.Stats, error) {
@@ -33,17 +35,32 @@ func (service *StatsService) Get(ctx context.Context, statsID string) (domain.St
 	return stats, nil
 }
 
-func (service *StatsService) Delete(ctx context.Context, linkID string) error {
-	if err := service.port.Delete(ctx, linkID); err != nil {
-		return fmt.Errorf("failed to delete stats for identifier '%s': %w", linkID, err)
+func (service *StatsService) Delete(ctx context.Context, statsID string) error {
+	if err := service.port.Delete(ctx, statsID); err != nil {
+		return fmt.Errorf("failed to delete stats for identifier '%s': %w", statsID, err)
 	}
 	return nil
 }
 
+// DeleteByLinkID deletes every stats row and click counter of the link and returns how many rows were deleted
+func (service *StatsService) DeleteByLinkID(ctx context.Context, linkID string) (int, error) {
+	deleted, err := service.port.DeleteByLinkID(ctx, linkID)
+	if err != nil {
+		return deleted, fmt.Errorf("failed to delete stats for link '%s': %w", linkID, err)
+	}
+	if _, err := service.counters.DeleteByLinkID(ctx, linkID); err != nil {
+		return deleted, fmt.Errorf("failed to delete click counters for link '%s': %w", linkID, err)
+	}
+	return deleted, nil
+}
+
 func (service *StatsService) Create(ctx context.Context, data domain.Stats) error {
 	if err := service.port.Create(ctx, data); err != nil {
 		return fmt.Errorf("failed to create stats: %w", err)
 	}
+	if err := service.counters.Increment(ctx, data.LinkID, data.Platform, data.Variant, data.CreatedAt); err != nil {
+		return fmt.Errorf("failed to count click for identifier '%s': %w", data.LinkID, err)
+	}
 	return nil
 }
 
@@ -54,3 +71,52 @@ func (service *StatsService) GetStatsByLinkID(ctx context.Context, linkID string
 	}
 	return stats, nil
 }
+
+func (service *StatsService) GetStatsByLinkIDInRange(ctx context.Context, linkID string, from time.Time, to time.Time) ([]domain.Stats, error) {
+	stats, err := service.port.GetStatsByLinkIDInRange(ctx, linkID, from, to)
+	if err != nil {
+		return []domain.Stats{}, fmt.Errorf("failed to get stats for identifier '%s': %w", linkID, err)
+	}
+	return stats, nil
+}
+
+// GetStatsPage returns up to limit of the link's stats between from and to after startKey and the start key of the next page
+func (service *StatsService) GetStatsPage(ctx context.Context, linkID string, from time.Time, to time.Time, limit int32, startKey string) ([]domain.Stats, string, error) {
+	stats, nextKey, err := service.port.GetStatsPageByLinkID(ctx, linkID, from, to, limit, startKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get stats for identifier '%s': %w", linkID, err)
+	}
+	return stats, nextKey, nil
+}
+
+// GetClickSummary adds up the link's click counters overlapping from..to into totals and a time series.
+// Counters only hold whole buckets, so a range that starts or ends inside a bucket takes in all of its
+// clicks. A to on a bucket boundary doesn't take in the bucket starting there
+func (service *StatsService) GetClickSummary(ctx context.Context, linkID string, granularity domain.Granularity, from time.Time, to time.Time) (domain.ClickSummary, error) {
+	if !to.IsZero() {
+		to = to.Add(-time.Nanosecond)
+	}
+	buckets, err := service.counters.GetBuckets(ctx, linkID, granularity, from, to)
+	if err != nil {
+		return domain.ClickSummary{}, fmt.Errorf("failed to get click counters for identifier '%s': %w", linkID, err)
+	}
+
+	summary := domain.ClickSummary{
+		Platforms:   map[string]int64{},
+		Granularity: granularity,
+		Series:      buckets,
+	}
+	for _, bucket := range buckets {
+		summary.Total += bucket.Total
+		for platform, count := range bucket.Platforms {
+			summary.Platforms[platform] += count
+		}
+		for variant, count := range bucket.Variants {
+			if summary.Variants == nil {
+				summary.Variants = map[string]int64{}
+			}
+			summary.Variants[variant] += count
+		}
+	}
+	return summary, nil
+}
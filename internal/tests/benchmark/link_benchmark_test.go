@@ -16,7 +16,7 @@ func GetService() *services.LinkService {
 	cache := cache.NewRedisCache("localhost:6379", "", 0)
 	mockLinkRepo := mock.NewMockLinkRepo()
 
-	linkService := services.NewLinkService(mockLinkRepo, cache)
+	linkService := services.NewLinkService(mockLinkRepo, cache, mock.NewMockHistoryRepo())
 
 	return linkService
 }
@@ -3,7 +3,11 @@ package mock
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
 	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 )
 
 type MockRedisCache struct {
@@ -24,15 +28,21 @@ func (m *MockRedisCache) Set(ctx context.Context, key string, val string) error
 	return nil
 }
 
+func (m *MockRedisCache) SetWithTTL(ctx context.Context, key string, val string, ttl time.Duration) error {
+	m.Store[key] = val
+	m.TTL[key] = time.Now().Add(ttl)
+	return nil
+}
+
 func (m *MockRedisCache) Get(ctx context.Context, key string) (string, error) {
 	val, ok := m.Store[key]
 	if !ok {
-		return "", errors.New("key not found")
+		return "", fmt.Errorf("key '%s': %w", key, domain.ErrNotFound)
 	}
 	if time.Now().After(m.TTL[key]) {
 		delete(m.Store, key)
 		delete(m.TTL, key)
-		return "", errors.New("key expired")
+		return "", fmt.Errorf("key '%s' expired: %w", key, domain.ErrNotFound)
 	}
 	return val, nil
 }
@@ -46,3 +56,15 @@ func (m *MockRedisCache) Delete(ctx context.Context, key string) error {
 	delete(m.TTL, key)
 	return nil
 }
+
+func (m *MockRedisCache) Increment(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	count := int64(0)
+	if val, err := m.Get(ctx, key); err == nil {
+		count, _ = strconv.ParseInt(val, 10, 64)
+	} else {
+		m.TTL[key] = time.Now().Add(ttl)
+	}
+	count++
+	m.Store[key] = strconv.FormatInt(count, 10)
+	return count, nil
+}
@@ -3,12 +3,17 @@ package mock
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"sync"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 )
 
 // ImprovedMockCache is an enhanced mock implementation of the Cache interface for testing
 type ImprovedMockCache struct {
 	data      map[string]string
+	ttl       map[string]time.Duration
 	mu        sync.RWMutex
 	getCount  int
 	setCount  int
@@ -20,6 +25,7 @@ type ImprovedMockCache struct {
 func NewImprovedMockCache() *ImprovedMockCache {
 	return &ImprovedMockCache{
 		data: make(map[string]string),
+		ttl:  make(map[string]time.Duration),
 	}
 }
 
@@ -37,6 +43,25 @@ func (m *ImprovedMockCache) Set(ctx context.Context, key string, val string) err
 	return nil
 }
 
+// SetWithTTL stores a key-value pair in the mock cache and records its TTL
+func (m *ImprovedMockCache) SetWithTTL(ctx context.Context, key string, val string, ttl time.Duration) error {
+	if err := m.Set(ctx, key, val); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ttl[key] = ttl
+	return nil
+}
+
+// GetTTL returns the TTL recorded by SetWithTTL for a key
+func (m *ImprovedMockCache) GetTTL(key string) time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.ttl[key]
+}
+
 // Get retrieves a value from the mock cache
 func (m *ImprovedMockCache) Get(ctx context.Context, key string) (string, error) {
 	m.mu.RLock()
@@ -49,7 +74,7 @@ func (m *ImprovedMockCache) Get(ctx context.Context, key string) (string, error)
 	m.getCount++
 	val, exists := m.data[key]
 	if !exists {
-		return "", nil // Return empty string for cache miss (like Redis)
+		return "", fmt.Errorf("key '%s': %w", key, domain.ErrNotFound)
 	}
 	return val, nil
 }
@@ -65,9 +90,28 @@ func (m *ImprovedMockCache) Delete(ctx context.Context, key string) error {
 
 	m.delCount++
 	delete(m.data, key)
+	delete(m.ttl, key)
 	return nil
 }
 
+// Increment atomically adds one to the counter at the key, recording its TTL when it's created
+func (m *ImprovedMockCache) Increment(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		return 0, fmt.Errorf("mock cache: increment operation failed")
+	}
+
+	count, _ := strconv.ParseInt(m.data[key], 10, 64)
+	if count == 0 {
+		m.ttl[key] = ttl
+	}
+	count++
+	m.data[key] = strconv.FormatInt(count, 10)
+	return count, nil
+}
+
 // SetFailureMode enables or disables failure simulation
 func (m *ImprovedMockCache) SetFailureMode(fail bool) {
 	m.mu.Lock()
@@ -101,6 +145,7 @@ func (m *ImprovedMockCache) Clear() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.data = make(map[string]string)
+	m.ttl = make(map[string]time.Duration)
 	m.getCount = 0
 	m.setCount = 0
 	m.delCount = 0
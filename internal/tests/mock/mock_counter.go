@@ -0,0 +1,79 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+)
+
+type MockCounterRepo struct {
+	mu      sync.Mutex
+	Buckets map[string]map[domain.Granularity]map[time.Time]domain.ClickBucket
+}
+
+func NewMockCounterRepo() *MockCounterRepo {
+	return &MockCounterRepo{
+		Buckets: make(map[string]map[domain.Granularity]map[time.Time]domain.ClickBucket),
+	}
+}
+
+func (m *MockCounterRepo) Increment(ctx context.Context, linkID string, platform domain.Platform, variant string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Buckets[linkID] == nil {
+		m.Buckets[linkID] = make(map[domain.Granularity]map[time.Time]domain.ClickBucket)
+	}
+	for _, granularity := range []domain.Granularity{domain.GranularityHour, domain.GranularityDay} {
+		if m.Buckets[linkID][granularity] == nil {
+			m.Buckets[linkID][granularity] = make(map[time.Time]domain.ClickBucket)
+		}
+		start := granularity.BucketStart(at)
+		bucket, ok := m.Buckets[linkID][granularity][start]
+		if !ok {
+			bucket = domain.ClickBucket{Start: start, Platforms: make(map[string]int64)}
+		}
+		bucket.Total++
+		bucket.Platforms[platform.String()]++
+		if variant != "" {
+			if bucket.Variants == nil {
+				bucket.Variants = make(map[string]int64)
+			}
+			bucket.Variants[variant]++
+		}
+		m.Buckets[linkID][granularity][start] = bucket
+	}
+	return nil
+}
+
+func (m *MockCounterRepo) GetBuckets(ctx context.Context, linkID string, granularity domain.Granularity, from time.Time, to time.Time) ([]domain.ClickBucket, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buckets := []domain.ClickBucket{}
+	for start, bucket := range m.Buckets[linkID][granularity] {
+		if (!from.IsZero() && start.Before(granularity.BucketStart(from))) || (!to.IsZero() && start.After(to)) {
+			continue
+		}
+		buckets = append(buckets, bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Start.Before(buckets[j].Start)
+	})
+	return buckets, nil
+}
+
+func (m *MockCounterRepo) DeleteByLinkID(ctx context.Context, linkID string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := 0
+	for _, buckets := range m.Buckets[linkID] {
+		deleted += len(buckets)
+	}
+	delete(m.Buckets, linkID)
+	return deleted, nil
+}
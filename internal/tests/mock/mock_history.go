@@ -0,0 +1,49 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+)
+
+type MockHistoryRepo struct {
+	mu         sync.Mutex
+	Revisions  []domain.LinkRevision
+	shouldFail bool
+}
+
+func NewMockHistoryRepo() *MockHistoryRepo {
+	return &MockHistoryRepo{}
+}
+
+func (m *MockHistoryRepo) Append(ctx context.Context, revision domain.LinkRevision) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.shouldFail {
+		return fmt.Errorf("mock history: append operation failed")
+	}
+	m.Revisions = append(m.Revisions, revision)
+	return nil
+}
+
+// SetFailureMode enables or disables failure simulation of appends
+func (m *MockHistoryRepo) SetFailureMode(fail bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.shouldFail = fail
+}
+
+func (m *MockHistoryRepo) ByLinkID(ctx context.Context, linkID string) ([]domain.LinkRevision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var revisions []domain.LinkRevision
+	for _, revision := range m.Revisions {
+		if revision.LinkID == linkID {
+			revisions = append(revisions, revision)
+		}
+	}
+	return revisions, nil
+}
@@ -2,6 +2,9 @@ package mock
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 )
@@ -13,8 +16,8 @@ type MockLinkRepo struct {
 
 func NewMockLinkRepo() *MockLinkRepo {
 	return &MockLinkRepo{
-		Links: MockLinkData,
-		Stats: MockStatsData,
+		Links: append([]domain.Link(nil), MockLinkData...),
+		Stats: append([]domain.Stats(nil), MockStatsData...),
 	}
 }
 
@@ -22,6 +25,42 @@ func (m *MockLinkRepo) All(ctx context.Context) ([]domain.Link, error) {
 	return m.Links, nil
 }
 
+func (m *MockLinkRepo) DeletedBefore(ctx context.Context, before time.Time) ([]domain.Link, error) {
+	var deleted []domain.Link
+	for _, link := range m.Links {
+		if link.IsDeleted() && link.DeletedAt.Before(before) {
+			deleted = append(deleted, link)
+		}
+	}
+	return deleted, nil
+}
+
+func (m *MockLinkRepo) AllByOwner(ctx context.Context, owner string, limit int32, startKey string) ([]domain.Link, string, error) {
+	var links []domain.Link
+	for _, link := range m.Links {
+		if link.OwnerID == owner {
+			links = append(links, link)
+		}
+	}
+
+	start := 0
+	if startKey != "" {
+		var err error
+		if start, err = strconv.Atoi(startKey); err != nil {
+			return nil, "", fmt.Errorf("invalid start key")
+		}
+	}
+	if start > len(links) {
+		start = len(links)
+	}
+
+	end := start + int(limit)
+	if end >= len(links) {
+		return links[start:], "", nil
+	}
+	return links[start:end], strconv.Itoa(end), nil
+}
+
 func (m *MockLinkRepo) Get(ctx context.Context, id string) (domain.Link, error) {
 	for _, link := range m.Links {
 		if link.Id == id {
@@ -29,21 +68,89 @@ func (m *MockLinkRepo) Get(ctx context.Context, id string) (domain.Link, error)
 		}
 	}
 
-	return domain.Link{}, nil
+	return domain.Link{}, fmt.Errorf("link with id '%s': %w", id, domain.ErrNotFound)
 }
 
 func (m *MockLinkRepo) Create(ctx context.Context, link domain.Link) error {
+	for _, existing := range m.Links {
+		if existing.Id == link.Id {
+			return fmt.Errorf("link with id '%s': %w", link.Id, domain.ErrConflict)
+		}
+	}
 	m.Links = append(m.Links, link)
 	return nil
 }
 
-func (m *MockLinkRepo) Delete(ctx context.Context, id string) error {
+func (m *MockLinkRepo) CreateBatch(ctx context.Context, links []domain.Link) ([]string, error) {
+	var taken []string
+	for _, link := range links {
+		if err := m.Create(ctx, link); err != nil {
+			taken = append(taken, link.Id)
+		}
+	}
+	return taken, nil
+}
+
+func (m *MockLinkRepo) Update(ctx context.Context, link domain.Link, expectedVersion int64) error {
+	for i, existing := range m.Links {
+		if existing.Id == link.Id && existing.OwnerID == link.OwnerID {
+			if existing.Version != expectedVersion {
+				return fmt.Errorf("link with id '%s' is at version %d: %w", link.Id, existing.Version, domain.ErrVersionConflict)
+			}
+			link.ClickCount = existing.ClickCount
+			m.Links[i] = link
+			return nil
+		}
+	}
+
+	return fmt.Errorf("link with id '%s': %w", link.Id, domain.ErrNotFound)
+}
+
+func (m *MockLinkRepo) SoftDelete(ctx context.Context, id string, owner string, at time.Time, deletedBy string) error {
 	for i, link := range m.Links {
-		if link.Id == id {
+		if link.Id == id && link.OwnerID == owner && !link.IsDeleted() {
+			m.Links[i].DeletedAt = &at
+			m.Links[i].DeletedBy = deletedBy
+			return nil
+		}
+	}
+
+	return fmt.Errorf("link with id '%s': %w", id, domain.ErrNotFound)
+}
+
+func (m *MockLinkRepo) Restore(ctx context.Context, id string, owner string, deletedAfter time.Time) error {
+	for i, link := range m.Links {
+		if link.Id == id && link.OwnerID == owner && link.IsDeleted() && link.DeletedAt.After(deletedAfter) {
+			m.Links[i].DeletedAt = nil
+			m.Links[i].DeletedBy = ""
+			return nil
+		}
+	}
+
+	return fmt.Errorf("deleted link with id '%s': %w", id, domain.ErrNotFound)
+}
+
+func (m *MockLinkRepo) Delete(ctx context.Context, id string, owner string) error {
+	for i, link := range m.Links {
+		if link.Id == id && link.OwnerID == owner {
 			m.Links = append(m.Links[:i], m.Links[i+1:]...)
 			return nil
 		}
 	}
 
-	return nil
+	return fmt.Errorf("link with id '%s': %w", id, domain.ErrNotFound)
+}
+
+func (m *MockLinkRepo) IncrementClicks(ctx context.Context, id string) error {
+	for i, link := range m.Links {
+		if link.Id == id {
+			if link.MaxClicks > 0 && link.ClickCount >= link.MaxClicks {
+				return fmt.Errorf("link with id '%s' reached its click limit: %w", id, domain.ErrExpired)
+			}
+			m.Links[i].ClickCount++
+			return nil
+		}
+	}
+
+	return fmt.Errorf("link with id '%s': %w", id, domain.ErrNotFound)
 }
@@ -0,0 +1,49 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+)
+
+// MockObjectStore keeps objects in memory, like a local store it has no download URLs
+type MockObjectStore struct {
+	mu      sync.Mutex
+	Objects map[string][]byte
+}
+
+func NewMockObjectStore() *MockObjectStore {
+	return &MockObjectStore{Objects: make(map[string][]byte)}
+}
+
+func (m *MockObjectStore) Put(ctx context.Context, key string, contentType string, body io.Reader) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Objects[key] = data
+	return nil
+}
+
+func (m *MockObjectStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, exists := m.Objects[key]
+	if !exists {
+		return nil, fmt.Errorf("object '%s': %w", key, domain.ErrNotFound)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *MockObjectStore) URL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "", nil
+}
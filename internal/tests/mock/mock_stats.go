@@ -2,6 +2,9 @@ package mock
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 )
@@ -12,7 +15,7 @@ type MockStatsRepo struct {
 
 func NewMockStatsRepo() *MockStatsRepo {
 	return &MockStatsRepo{
-		Stats: MockStatsData,
+		Stats: append([]domain.Stats(nil), MockStatsData...),
 	}
 }
 
@@ -22,7 +25,7 @@ func (m *MockStatsRepo) Get(ctx context.Context, id string) (domain.Stats, error
 			return stats, nil
 		}
 	}
-	return domain.Stats{}, nil
+	return domain.Stats{}, fmt.Errorf("stats with id '%s': %w", id, domain.ErrNotFound)
 }
 
 func (m *MockStatsRepo) All(ctx context.Context) ([]domain.Stats, error) {
@@ -42,7 +45,19 @@ func (m *MockStatsRepo) Delete(ctx context.Context, id string) error {
 		}
 	}
 
-	return nil
+	return fmt.Errorf("stats with id '%s': %w", id, domain.ErrNotFound)
+}
+
+func (m *MockStatsRepo) DeleteByLinkID(ctx context.Context, linkID string) (int, error) {
+	kept := m.Stats[:0]
+	for _, stats := range m.Stats {
+		if stats.LinkID != linkID {
+			kept = append(kept, stats)
+		}
+	}
+	deleted := len(m.Stats) - len(kept)
+	m.Stats = kept
+	return deleted, nil
 }
 
 func (m *MockStatsRepo) GetStatsByLinkID(ctx context.Context, linkID string) ([]domain.Stats, error) {
@@ -54,3 +69,38 @@ func (m *MockStatsRepo) GetStatsByLinkID(ctx context.Context, linkID string) ([]
 	}
 	return stats, nil
 }
+
+func (m *MockStatsRepo) GetStatsByLinkIDInRange(ctx context.Context, linkID string, from time.Time, to time.Time) ([]domain.Stats, error) {
+	var stats []domain.Stats
+	for _, stat := range m.Stats {
+		if stat.LinkID != linkID {
+			continue
+		}
+		if (!from.IsZero() && stat.CreatedAt.Before(from)) || (!to.IsZero() && stat.CreatedAt.After(to)) {
+			continue
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+func (m *MockStatsRepo) GetStatsPageByLinkID(ctx context.Context, linkID string, from time.Time, to time.Time, limit int32, startKey string) ([]domain.Stats, string, error) {
+	stats, _ := m.GetStatsByLinkIDInRange(ctx, linkID, from, to)
+
+	start := 0
+	if startKey != "" {
+		var err error
+		if start, err = strconv.Atoi(startKey); err != nil {
+			return nil, "", fmt.Errorf("invalid start key")
+		}
+	}
+	if start > len(stats) {
+		start = len(stats)
+	}
+
+	end := start + int(limit)
+	if end >= len(stats) {
+		return stats[start:], "", nil
+	}
+	return stats[start:end], strconv.Itoa(end), nil
+}
@@ -0,0 +1,124 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireScope(t *testing.T) {
+	ctx := context.Background()
+	apiKeyService := services.NewAPIKeyService(repository.NewMemoryAPIKeyRepository(), testAdminKey)
+	auth := handlers.NewAuthenticator(apiKeyService)
+
+	_, readToken, err := apiKeyService.Create(ctx, "dashboard", "team-a", []domain.Scope{domain.ScopeReadStats})
+	require.NoError(t, err)
+	revokedKey, revokedToken, err := apiKeyService.Create(ctx, "old", "team-a", []domain.Scope{domain.ScopeReadStats})
+	require.NoError(t, err)
+	require.NoError(t, apiKeyService.Revoke(ctx, revokedKey.Id))
+
+	var authenticated domain.APIKey
+	protected := auth.RequireScope(domain.ScopeReadStats, func(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+		authenticated, _ = handlers.APIKeyFromContext(ctx)
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+	})
+	deleteOnly := auth.RequireScope(domain.ScopeDelete, protected)
+
+	tests := []struct {
+		name               string
+		handler            handlers.HandlerFunc
+		headers            map[string]string
+		expectedStatusCode int
+		expectedKeyName    string
+	}{
+		{name: "missing key", handler: protected, headers: nil, expectedStatusCode: 401},
+		{name: "malformed key", handler: protected, headers: map[string]string{"x-api-key": "garbage"}, expectedStatusCode: 401},
+		{name: "wrong secret", handler: protected, headers: map[string]string{"x-api-key": strings.Split(readToken, ".")[0] + ".wrong"}, expectedStatusCode: 401},
+		{name: "revoked key", handler: protected, headers: map[string]string{"x-api-key": revokedToken}, expectedStatusCode: 401},
+		{name: "x-api-key header", handler: protected, headers: map[string]string{"x-api-key": readToken}, expectedStatusCode: 200, expectedKeyName: "dashboard"},
+		{name: "bearer token", handler: protected, headers: map[string]string{"authorization": "Bearer " + readToken}, expectedStatusCode: 200, expectedKeyName: "dashboard"},
+		{name: "missing scope", handler: deleteOnly, headers: map[string]string{"x-api-key": readToken}, expectedStatusCode: 403},
+		{name: "bootstrap admin", handler: deleteOnly, headers: map[string]string{"authorization": "Bearer " + testAdminKey}, expectedStatusCode: 200, expectedKeyName: services.BootstrapKeyID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticated = domain.APIKey{}
+			response, err := tt.handler(ctx, events.APIGatewayV2HTTPRequest{Headers: tt.headers})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, response.StatusCode)
+			assert.Equal(t, tt.expectedKeyName, authenticated.Name)
+		})
+	}
+}
+
+func TestFileAPIKeysSurviveReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "shortener.db")
+
+	store, err := repository.OpenFileStore(path)
+	require.NoError(t, err)
+	key, token, err := services.NewAPIKeyService(store.APIKeyRepository(), "").Create(ctx, "cli", "team-a", []domain.Scope{domain.ScopeCreate})
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	store, err = repository.OpenFileStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	authenticated, err := services.NewAPIKeyService(store.APIKeyRepository(), "").Authenticate(ctx, token)
+	assert.NoError(t, err)
+	assert.Equal(t, key.Id, authenticated.Id)
+}
+
+func TestServerAPIKeyLifecycle(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	do := func(method string, path string, token string, body string) *http.Response {
+		req, _ := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/stats", "", "").StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodPost, "/admin/keys", "", `{"name": "x", "scopes": ["admin"]}`).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/admin/keys", testAdminKey, `{"name": "x", "workspace": "team-a", "scopes": ["everything"]}`).StatusCode)
+
+	resp := do(http.MethodPost, "/admin/keys", testAdminKey, `{"name": "reporting", "workspace": "team-a", "scopes": ["read-stats"]}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var created handlers.CreateAPIKeyResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	assert.NotEmpty(t, created.Token)
+
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/stats", created.Token, "").StatusCode)
+	assert.Equal(t, http.StatusForbidden, do(http.MethodDelete, "/delete/testid1", created.Token, "").StatusCode)
+	assert.Equal(t, http.StatusForbidden, do(http.MethodGet, "/admin/keys", created.Token, "").StatusCode)
+
+	resp = do(http.MethodGet, "/admin/keys", testAdminKey, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var keys []map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&keys))
+	require.Len(t, keys, 1)
+	assert.NotContains(t, keys[0], "hash")
+
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/admin/keys/"+created.Id, testAdminKey, "").StatusCode)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/admin/keys/unknown", testAdminKey, "").StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/stats", created.Token, "").StatusCode)
+}
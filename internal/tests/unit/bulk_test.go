@@ -0,0 +1,282 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/ports"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/tests/mock"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalLinkRepositoriesCreateBatch(t *testing.T) {
+	ctx := context.Background()
+
+	for _, repos := range newLocalRepositories(t) {
+		t.Run(repos.name, func(t *testing.T) {
+			require.NoError(t, repos.links.Create(ctx, domain.Link{Id: "batch1", OriginalURL: "https://example.com/first"}))
+
+			taken, err := repos.links.CreateBatch(ctx, []domain.Link{
+				{Id: "batch1", OriginalURL: "https://example.com/overwrite"},
+				{Id: "batch2", OriginalURL: "https://example.com/second"},
+				{Id: "batch3", OriginalURL: "https://example.com/third"},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"batch1"}, taken)
+
+			// Taken ids are left alone
+			link, err := repos.links.Get(ctx, "batch1")
+			require.NoError(t, err)
+			assert.Equal(t, "https://example.com/first", link.OriginalURL)
+
+			all, err := repos.links.All(ctx)
+			require.NoError(t, err)
+			assert.Len(t, all, 3)
+		})
+	}
+}
+
+func TestLocalBulkJobRepositories(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "shortener.db")
+	store, err := repository.OpenFileStore(path)
+	require.NoError(t, err)
+
+	repos := map[string]ports.BulkJobPort{
+		"memory": repository.NewMemoryBulkJobRepository(),
+		"file":   store.BulkJobRepository(),
+	}
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			job := domain.BulkJob{Id: "job1", OwnerID: "team", Total: 3, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+			require.NoError(t, repo.Create(ctx, job))
+			err := repo.Create(ctx, job)
+			assert.True(t, errors.Is(err, domain.ErrConflict), "got %v", err)
+
+			got, err := repo.Get(ctx, "job1")
+			require.NoError(t, err)
+			assert.Equal(t, domain.BulkJobQueued, got.Status())
+			assert.Empty(t, got.Results)
+
+			second := []domain.BulkItemResult{{Index: 2, Status: domain.BulkItemFailed, Error: "Invalid URL format"}}
+			first := []domain.BulkItemResult{
+				{Index: 0, Status: domain.BulkItemCreated, Id: "a"},
+				{Index: 1, Status: domain.BulkItemCreated, Id: "b"},
+			}
+			require.NoError(t, repo.AddResults(ctx, "job1", 2, second))
+			got, err = repo.Get(ctx, "job1")
+			require.NoError(t, err)
+			assert.Equal(t, domain.BulkJobRunning, got.Status())
+
+			// Redelivered chunks are only counted once
+			require.NoError(t, repo.AddResults(ctx, "job1", 0, first))
+			require.NoError(t, repo.AddResults(ctx, "job1", 0, first))
+
+			got, err = repo.Get(ctx, "job1")
+			require.NoError(t, err)
+			assert.Equal(t, domain.BulkJobCompleted, got.Status())
+			assert.Equal(t, "team", got.OwnerID)
+			assert.Equal(t, []int{3, 2, 1}, []int{got.Processed, got.Created, got.Failed})
+			require.Len(t, got.Results, 3)
+			for i, result := range got.Results {
+				assert.Equal(t, i, result.Index)
+			}
+
+			err = repo.AddResults(ctx, "missing", 0, first)
+			assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+
+			require.NoError(t, repo.Create(ctx, domain.BulkJob{Id: "expired", Total: 1, ExpiresAt: now.Add(-time.Minute)}))
+			_, err = repo.Get(ctx, "expired")
+			assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+		})
+	}
+
+	// Jobs survive a reopen, expired ones are compacted away
+	require.NoError(t, store.Close())
+	store, err = repository.OpenFileStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	got, err := store.BulkJobRepository().Get(ctx, "job1")
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 2, 1}, []int{got.Processed, got.Created, got.Failed})
+	assert.Len(t, got.Results, 3)
+	require.NoError(t, store.BulkJobRepository().Create(ctx, domain.BulkJob{Id: "expired", ExpiresAt: time.Now().Add(time.Hour)}))
+}
+
+func TestBulkGenerateUnit(t *testing.T) {
+	linkService := services.NewLinkService(mock.NewMockLinkRepo(), mock.NewImprovedMockCache(), mock.NewMockHistoryRepo())
+	policyService := NewTestPolicyService(domain.PolicyRule{Id: "1", Type: domain.PolicyRuleDomain, Pattern: "evil.com", Action: domain.PolicyDeny})
+	apiHandler := handlers.NewBulkGenerateFunctionHandler(linkService, policyService, services.NewBulkJobService(repository.NewMemoryBulkJobRepository(), nil, time.Hour))
+	ctx := handlers.WithAPIKey(context.Background(), domain.APIKey{Id: services.BootstrapKeyID})
+
+	tests := []struct {
+		name               string
+		body               string
+		contentType        string
+		expectedStatusCode int
+		expected           []domain.BulkItemResult
+	}{
+		{
+			name:               "json",
+			body:               `["https://example.com/bulk/one", {"long": "https://example.com/bulk/two", "alias": "bulk-two"}, "ftp://example.com/file", "https://evil.com/bulk/three", {"long": "https://example.com/bulk/four", "alias": "testid1"}, {"long": "https://example.com/bulk/five", "alias": "admin"}]`,
+			expectedStatusCode: http.StatusOK,
+			expected: []domain.BulkItemResult{
+				{Index: 0, Long: "https://example.com/bulk/one", Status: domain.BulkItemCreated},
+				{Index: 1, Long: "https://example.com/bulk/two", Status: domain.BulkItemCreated, Id: "bulk-two"},
+				{Index: 2, Long: "ftp://example.com/file", Status: domain.BulkItemFailed, Error: "Scheme 'ftp' is not allowed", Reason: domain.ReasonBlockedScheme},
+				{Index: 3, Long: "https://evil.com/bulk/three", Status: domain.BulkItemFailed, Error: "URL is blocked by policy", Reason: domain.ReasonBlockedDomain},
+				{Index: 4, Long: "https://example.com/bulk/four", Status: domain.BulkItemFailed, Error: "Alias 'testid1' is already in use"},
+				{Index: 5, Long: "https://example.com/bulk/five", Status: domain.BulkItemFailed, Error: "Alias is reserved"},
+			},
+		},
+		{
+			name:               "csv",
+			body:               "long,alias\nhttps://example.com/bulk/csv1\n\"https://example.com/bulk/csv2\", bulk-csv\nhttps://x.io\n",
+			contentType:        "text/csv; charset=utf-8",
+			expectedStatusCode: http.StatusOK,
+			expected: []domain.BulkItemResult{
+				{Index: 0, Long: "https://example.com/bulk/csv1", Status: domain.BulkItemCreated},
+				{Index: 1, Long: "https://example.com/bulk/csv2", Status: domain.BulkItemCreated, Id: "bulk-csv"},
+				{Index: 2, Long: "https://x.io", Status: domain.BulkItemFailed, Error: "URL must be at least 15 characters long"},
+			},
+		},
+		{name: "invalid json", body: `{"long": "https://example.com/bulk"}`, expectedStatusCode: http.StatusBadRequest},
+		{name: "too many columns", body: "https://example.com/bulk,alias,extra\n", contentType: "text/csv", expectedStatusCode: http.StatusBadRequest},
+		{name: "empty", body: `[]`, expectedStatusCode: http.StatusBadRequest},
+		{name: "too large", body: `[` + strings.Repeat(`"https://example.com/bulk",`, 10000) + `"https://example.com/bulk"]`, expectedStatusCode: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayV2HTTPRequest{Body: tt.body, Headers: map[string]string{"content-type": tt.contentType}}
+			request.RequestContext.HTTP.Method = http.MethodPost
+			response, err := apiHandler.CreateBulk(ctx, request)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedStatusCode, response.StatusCode, response.Body)
+			if tt.expected == nil {
+				return
+			}
+
+			var body handlers.BulkResponse
+			require.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+			require.Len(t, body.Results, len(tt.expected))
+			for i, result := range body.Results {
+				if tt.expected[i].Status == domain.BulkItemCreated && tt.expected[i].Id == "" {
+					assert.Len(t, result.Id, 8)
+					result.Id = ""
+				}
+				assert.Equal(t, tt.expected[i], result)
+			}
+			assert.Equal(t, len(tt.expected), body.Created+body.Failed)
+
+			for _, result := range body.Results {
+				if result.Status == domain.BulkItemCreated {
+					link, err := linkService.Get(context.Background(), result.Id)
+					require.NoError(t, err)
+					assert.Equal(t, result.Long, link.OriginalURL)
+				}
+			}
+		})
+	}
+}
+
+func TestBulkChunkRedelivery(t *testing.T) {
+	ctx := context.Background()
+	linkRepo := mock.NewMockLinkRepo()
+	jobRepo := repository.NewMemoryBulkJobRepository()
+	linkService := services.NewLinkService(linkRepo, mock.NewImprovedMockCache(), mock.NewMockHistoryRepo())
+	worker := handlers.NewBulkGenerateFunctionHandler(linkService, NewTestPolicyService(), services.NewBulkJobService(jobRepo, nil, time.Hour))
+
+	chunk := domain.BulkChunk{JobID: "redelivered", OwnerID: "team", Offset: 100, Items: []domain.BulkItem{
+		{Long: "https://example.com/redelivered/one"},
+		{Long: "https://example.com/redelivered/two"},
+		{Long: "https://example.com/redelivered/three", Alias: "redelivered-three"},
+	}}
+	links := len(linkRepo.Links)
+
+	// The links are created but recording the results fails, so SQS delivers the chunk again
+	require.Error(t, worker.Process(ctx, chunk))
+	assert.Len(t, linkRepo.Links, links+3)
+
+	require.NoError(t, jobRepo.Create(ctx, domain.BulkJob{Id: "redelivered", OwnerID: "team", Total: 3, ExpiresAt: time.Now().Add(time.Hour)}))
+	require.NoError(t, worker.Process(ctx, chunk))
+	require.NoError(t, worker.Process(ctx, chunk))
+	assert.Len(t, linkRepo.Links, links+3)
+
+	job, err := jobRepo.Get(ctx, "redelivered")
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 3, 0}, []int{job.Processed, job.Created, job.Failed})
+	for _, result := range job.Results {
+		link, err := linkService.Get(ctx, result.Id)
+		require.NoError(t, err)
+		assert.Equal(t, result.Long, link.OriginalURL)
+	}
+	assert.Equal(t, "redelivered-three", job.Results[2].Id)
+}
+
+func TestServerBulkJob(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	urls := make([]string, 150)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://example.com/bulk/%d", i)
+	}
+	urls[120] = "not a url at all"
+	body, _ := json.Marshal(urls)
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/generate/bulk", strings.NewReader(string(body)))
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	location := resp.Header.Get("Location")
+	require.True(t, strings.HasPrefix(location, "/generate/bulk/"), location)
+
+	var job struct {
+		Status  domain.BulkJobStatus    `json:"status"`
+		Total   int                     `json:"total"`
+		Created int                     `json:"created"`
+		Failed  int                     `json:"failed"`
+		Results []domain.BulkItemResult `json:"results"`
+	}
+	require.Eventually(t, func() bool {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+location, nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminKey)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			return false
+		}
+		defer resp.Body.Close()
+		return json.NewDecoder(resp.Body).Decode(&job) == nil && job.Status == domain.BulkJobCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, 150, job.Total)
+	assert.Equal(t, 149, job.Created)
+	assert.Equal(t, 1, job.Failed)
+	require.Len(t, job.Results, 150)
+	assert.Equal(t, domain.BulkItemFailed, job.Results[120].Status)
+
+	req, _ = http.NewRequest(http.MethodGet, srv.URL+"/generate/bulk/missing", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -2,7 +2,9 @@ package unit
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
@@ -14,7 +16,7 @@ func TestCacheHitScenario(t *testing.T) {
 	// Setup
 	mockLinkRepo := mock.NewMockLinkRepo()
 	mockCache := mock.NewImprovedMockCache()
-	linkService := services.NewLinkService(mockLinkRepo, mockCache)
+	linkService := services.NewLinkService(mockLinkRepo, mockCache, mock.NewMockHistoryRepo())
 	ctx := context.Background()
 
 	// Pre-populate cache
@@ -37,7 +39,7 @@ func TestCacheMissScenario(t *testing.T) {
 	// Setup
 	mockLinkRepo := mock.NewMockLinkRepo()
 	mockCache := mock.NewImprovedMockCache()
-	linkService := services.NewLinkService(mockLinkRepo, mockCache)
+	linkService := services.NewLinkService(mockLinkRepo, mockCache, mock.NewMockHistoryRepo())
 	ctx := context.Background()
 
 	// Add data to repository but not cache
@@ -60,8 +62,9 @@ func TestCacheMissScenario(t *testing.T) {
 func TestCreateLinkPopulatesCache(t *testing.T) {
 	// Setup
 	mockLinkRepo := mock.NewMockLinkRepo()
+	mockLinkRepo.Links = []domain.Link{}
 	mockCache := mock.NewImprovedMockCache()
-	linkService := services.NewLinkService(mockLinkRepo, mockCache)
+	linkService := services.NewLinkService(mockLinkRepo, mockCache, mock.NewMockHistoryRepo())
 	ctx := context.Background()
 
 	// Create a new link
@@ -73,17 +76,20 @@ func TestCreateLinkPopulatesCache(t *testing.T) {
 	err := linkService.Create(ctx, newLink)
 	assert.NoError(t, err)
 
-	// Note: Cache population is async in the service, so we can't directly test it
-	// But we can verify the link was created in the repository
+	// The link is created in the repository and the cache is populated asynchronously
 	assert.Len(t, mockLinkRepo.Links, 1)
 	assert.Equal(t, newLink.Id, mockLinkRepo.Links[0].Id)
+	assert.Eventually(t, func() bool {
+		cached, err := mockCache.Get(ctx, newLink.Id)
+		return err == nil && cached == newLink.OriginalURL
+	}, time.Second, 10*time.Millisecond)
 }
 
 func TestDeleteLinkRemovesFromCache(t *testing.T) {
 	// Setup
 	mockLinkRepo := mock.NewMockLinkRepo()
 	mockCache := mock.NewImprovedMockCache()
-	linkService := services.NewLinkService(mockLinkRepo, mockCache)
+	linkService := services.NewLinkService(mockLinkRepo, mockCache, mock.NewMockHistoryRepo())
 	ctx := context.Background()
 
 	// Add data
@@ -95,18 +101,21 @@ func TestDeleteLinkRemovesFromCache(t *testing.T) {
 	mockCache.Set(ctx, testID, testURL)
 
 	// Delete the link
-	err := linkService.Delete(ctx, testID)
+	err := linkService.Delete(ctx, testID, "", "")
 	assert.NoError(t, err)
 
-	// Verify link was deleted from repository
-	assert.Len(t, mockLinkRepo.Links, 0)
+	// Verify link was tombstoned in the repository and dropped from the cache
+	assert.Len(t, mockLinkRepo.Links, 1)
+	assert.True(t, mockLinkRepo.Links[0].IsDeleted())
+	_, err = mockCache.Get(ctx, testID)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
 }
 
 func TestCacheFailureHandling(t *testing.T) {
 	// Setup
 	mockLinkRepo := mock.NewMockLinkRepo()
 	mockCache := mock.NewImprovedMockCache()
-	linkService := services.NewLinkService(mockLinkRepo, mockCache)
+	linkService := services.NewLinkService(mockLinkRepo, mockCache, mock.NewMockHistoryRepo())
 	ctx := context.Background()
 
 	// Add data to repository
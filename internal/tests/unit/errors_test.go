@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/tests/mock"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorResponse(t *testing.T) {
+	tests := []struct {
+		name               string
+		err                error
+		expectedStatusCode int
+	}{
+		{name: "not found", err: fmt.Errorf("failed to get link: %w", domain.ErrNotFound), expectedStatusCode: 404},
+		{name: "conflict", err: fmt.Errorf("failed to create link: %w", domain.ErrConflict), expectedStatusCode: 409},
+		{name: "expired", err: fmt.Errorf("failed to count click: %w", domain.ErrExpired), expectedStatusCode: 410},
+		{name: "deleted", err: fmt.Errorf("failed to get link: %w", domain.ErrDeleted), expectedStatusCode: 410},
+		{name: "other", err: errors.New("connection refused"), expectedStatusCode: 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := handlers.ErrorResponse(tt.err)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, response.StatusCode)
+		})
+	}
+}
+
+func TestDeleteLinkUnit(t *testing.T) {
+	mockCache := mock.NewImprovedMockCache()
+	linkService := services.NewLinkService(mock.NewMockLinkRepo(), mockCache, mock.NewMockHistoryRepo())
+	apiHandler := handlers.NewDeleteFunctionHandler(linkService, time.Hour)
+
+	tests := []struct {
+		id                 string
+		expectedStatusCode int
+	}{
+		{id: "testid1", expectedStatusCode: 204},
+		{id: "testid1", expectedStatusCode: 410},
+		{id: "nonexistentid", expectedStatusCode: 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			response, err := apiHandler.Delete(context.Background(), events.APIGatewayV2HTTPRequest{
+				PathParameters: map[string]string{"id": tt.id},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, response.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/tests/mock"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiredLinkIsRejected(t *testing.T) {
+	mockLinkRepo := mock.NewMockLinkRepo()
+	mockCache := mock.NewImprovedMockCache()
+	linkService := services.NewLinkService(mockLinkRepo, mockCache, mock.NewMockHistoryRepo())
+	ctx := context.Background()
+
+	expiredAt := time.Now().Add(-time.Hour)
+	mockLinkRepo.Links = []domain.Link{
+		{Id: "expired1", OriginalURL: "https://example.com/expired", ExpiresAt: &expiredAt},
+	}
+
+	result, err := linkService.GetOriginalURL(ctx, "expired1")
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, domain.ErrExpired))
+	assert.Equal(t, 0, mockCache.GetSetCount())
+}
+
+func TestCacheTTLFollowsLinkLifetime(t *testing.T) {
+	mockLinkRepo := mock.NewMockLinkRepo()
+	mockCache := mock.NewImprovedMockCache()
+	linkService := services.NewLinkService(mockLinkRepo, mockCache, mock.NewMockHistoryRepo())
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(10 * time.Minute)
+	mockLinkRepo.Links = []domain.Link{
+		{Id: "shortlived", OriginalURL: "https://example.com/short-lived", ExpiresAt: &expiresAt},
+	}
+
+	result, err := linkService.GetOriginalURL(ctx, "shortlived")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/short-lived", *result)
+
+	// Cache population is async
+	assert.Eventually(t, func() bool { return mockCache.GetSetCount() == 1 }, time.Second, 10*time.Millisecond)
+	ttl := mockCache.GetTTL("shortlived")
+	assert.True(t, ttl > 0 && ttl <= 10*time.Minute)
+}
+
+func TestMaxClicksLimit(t *testing.T) {
+	mockLinkRepo := mock.NewMockLinkRepo()
+	mockCache := mock.NewImprovedMockCache()
+	linkService := services.NewLinkService(mockLinkRepo, mockCache, mock.NewMockHistoryRepo())
+	statsService := services.NewStatsService(mock.NewMockStatsRepo(), mock.NewMockCounterRepo(), mockCache)
+	apiHandler := handlers.NewRedirectFunctionHandler(linkService, statsService)
+
+	mockLinkRepo.Links = []domain.Link{
+		{Id: "twice", OriginalURL: "https://example.com/twice", MaxClicks: 2},
+	}
+
+	request := events.APIGatewayV2HTTPRequest{RawPath: "/t/twice"}
+	for i := 0; i < 2; i++ {
+		response, err := apiHandler.Redirect(context.Background(), request)
+		assert.NoError(t, err)
+		assert.Equal(t, 302, response.StatusCode)
+	}
+
+	response, err := apiHandler.Redirect(context.Background(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, 410, response.StatusCode)
+	assert.Equal(t, "Link has expired", response.Body)
+
+	// Click limited links must never be served from the cache
+	assert.Equal(t, 0, mockCache.GetSetCount())
+}
+
+func TestGenerateRejectsPastExpiry(t *testing.T) {
+	mockCache := mock.NewImprovedMockCache()
+	linkService := services.NewLinkService(mock.NewMockLinkRepo(), mockCache, mock.NewMockHistoryRepo())
+	statsService := services.NewStatsService(mock.NewMockStatsRepo(), mock.NewMockCounterRepo(), mockCache)
+	apiHandler := handlers.NewGenerateLinkFunctionHandler(linkService, statsService, NewTestPolicyService())
+
+	body := `{"long": "https://example.com/campaign", "expires_at": "2001-01-01T00:00:00Z"}`
+	response, err := apiHandler.CreateShortLink(context.Background(), events.APIGatewayV2HTTPRequest{Body: body})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Equal(t, "Expiry date must be in the future", response.Body)
+}
@@ -0,0 +1,228 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/objectstore"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/tests/mock"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStatsRepositoriesPagination(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, repos := range newLocalRepositories(t) {
+		t.Run(repos.name, func(t *testing.T) {
+			for i := 0; i < 5; i++ {
+				require.NoError(t, repos.stats.Create(ctx, domain.Stats{Id: fmt.Sprintf("page%d", i), LinkID: "link0", CreatedAt: start.Add(time.Duration(i) * time.Hour)}))
+			}
+			require.NoError(t, repos.stats.Create(ctx, domain.Stats{Id: "other", LinkID: "link1", CreatedAt: start}))
+
+			var ids []string
+			startKey := ""
+			for pages := 1; ; pages++ {
+				stats, nextKey, err := repos.stats.GetStatsPageByLinkID(ctx, "link0", time.Time{}, time.Time{}, 2, startKey)
+				require.NoError(t, err)
+				for _, stat := range stats {
+					ids = append(ids, stat.Id)
+				}
+				if nextKey == "" {
+					assert.Equal(t, 3, pages)
+					break
+				}
+				startKey = nextKey
+			}
+			assert.Equal(t, []string{"page0", "page1", "page2", "page3", "page4"}, ids)
+
+			stats, nextKey, err := repos.stats.GetStatsPageByLinkID(ctx, "link0", start.Add(time.Hour), start.Add(3*time.Hour), 10, "")
+			require.NoError(t, err)
+			assert.Len(t, stats, 3)
+			assert.Empty(t, nextKey)
+
+			_, _, err = repos.stats.GetStatsPageByLinkID(ctx, "link0", time.Time{}, time.Time{}, 2, "bogus")
+			assert.Error(t, err)
+			for _, limit := range []int32{0, -1} {
+				_, _, err = repos.stats.GetStatsPageByLinkID(ctx, "link0", time.Time{}, time.Time{}, limit, "")
+				assert.Error(t, err, "limit %d", limit)
+			}
+		})
+	}
+}
+
+func TestLocalObjectStore(t *testing.T) {
+	ctx := context.Background()
+	store := objectstore.NewLocalObjectStore(t.TempDir())
+
+	require.NoError(t, store.Put(ctx, "exports/team/a.csv", "text/csv", strings.NewReader("id\n1\n")))
+	file, err := store.Get(ctx, "exports/team/a.csv")
+	require.NoError(t, err)
+	data, err := io.ReadAll(file)
+	file.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "id\n1\n", string(data))
+
+	// A failed upload leaves the previous object alone
+	err = store.Put(ctx, "exports/team/a.csv", "text/csv", io.MultiReader(strings.NewReader("partial"), errReader{}))
+	assert.Error(t, err)
+	file, err = store.Get(ctx, "exports/team/a.csv")
+	require.NoError(t, err)
+	data, _ = io.ReadAll(file)
+	file.Close()
+	assert.Equal(t, "id\n1\n", string(data))
+
+	_, err = store.Get(ctx, "exports/team/missing.csv")
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+	assert.Error(t, store.Put(ctx, "../escape.csv", "text/csv", strings.NewReader("x")))
+
+	url, err := store.URL(ctx, "exports/team/a.csv", time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, url)
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestExportUnit(t *testing.T) {
+	ctx := context.Background()
+	linkRepo := repository.NewMemoryLinkRepository()
+	statsService := services.NewStatsService(repository.NewMemoryStatsRepository(), repository.NewMemoryCounterRepository(), mock.NewImprovedMockCache())
+	linkService := services.NewLinkService(linkRepo, mock.NewImprovedMockCache(), mock.NewMockHistoryRepo())
+	apiHandler := handlers.NewExportFunctionHandler(linkService, statsService, services.NewExportService(mock.NewMockObjectStore(), nil, time.Hour, time.Minute))
+	authCtx := handlers.WithAPIKey(ctx, domain.APIKey{Id: services.BootstrapKeyID})
+
+	// More links than an export page
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 150; i++ {
+		require.NoError(t, linkRepo.Create(ctx, domain.Link{Id: fmt.Sprintf("exp%03d", i), OriginalURL: fmt.Sprintf("https://example.com/export/%d", i), CreatedAt: start.Add(time.Duration(i) * time.Minute)}))
+	}
+	require.NoError(t, linkRepo.Create(ctx, domain.Link{Id: "foreign", OwnerID: "other", OriginalURL: "https://example.com/foreign", CreatedAt: start}))
+	variants := []string{"", "b", "a"}
+	for i, platform := range []domain.Platform{domain.PlatformTwitter, domain.PlatformTwitter, domain.PlatformYouTube} {
+		require.NoError(t, statsService.Create(ctx, domain.Stats{Id: fmt.Sprintf("click%d", i), LinkID: "exp149", Platform: platform, Variant: variants[i], CreatedAt: start.Add(time.Duration(i) * 24 * time.Hour)}))
+	}
+	require.NoError(t, statsService.Create(ctx, domain.Stats{Id: "click-foreign", LinkID: "foreign", CreatedAt: start}))
+
+	export := func(params map[string]string) events.APIGatewayProxyResponse {
+		response, err := apiHandler.Export(authCtx, events.APIGatewayV2HTTPRequest{QueryStringParameters: params})
+		require.NoError(t, err)
+		return response
+	}
+
+	t.Run("links csv", func(t *testing.T) {
+		response := export(nil)
+		require.Equal(t, http.StatusOK, response.StatusCode, response.Body)
+		assert.Equal(t, "text/csv; charset=utf-8", response.Headers["Content-Type"])
+		assert.Equal(t, `attachment; filename="links.csv"`, response.Headers["Content-Disposition"])
+
+		records, err := csv.NewReader(strings.NewReader(response.Body)).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 151)
+		assert.Equal(t, []string{"id", "original_url", "created_at", "created_by", "expires_at", "max_clicks", "click_count", "version", "updated_at", "deleted_at", "clicks", "clicks_instagram", "clicks_twitter", "clicks_youtube", "clicks_unknown"}, records[0])
+		assert.Equal(t, []string{"exp149", "https://example.com/export/149", "2024-01-01T02:29:00Z", "", "", "0", "0", "0", "", "", "3", "0", "2", "1", "0"}, records[150])
+	})
+
+	t.Run("links ndjson in range", func(t *testing.T) {
+		response := export(map[string]string{"format": "ndjson", "from": "2024-01-02T00:00:00Z"})
+		require.Equal(t, http.StatusOK, response.StatusCode, response.Body)
+		assert.Equal(t, "application/x-ndjson", response.Headers["Content-Type"])
+
+		lines := strings.Split(strings.TrimSuffix(response.Body, "\n"), "\n")
+		require.Len(t, lines, 150)
+		var link domain.Link
+		require.NoError(t, json.Unmarshal([]byte(lines[149]), &link))
+		assert.Equal(t, "exp149", link.Id)
+		require.NotNil(t, link.Clicks)
+		assert.Equal(t, int64(2), link.Clicks.Total)
+		assert.Nil(t, link.Clicks.Series)
+	})
+
+	t.Run("stats csv", func(t *testing.T) {
+		response := export(map[string]string{"type": "stats", "to": "2024-01-02T00:00:00Z"})
+		require.Equal(t, http.StatusOK, response.StatusCode, response.Body)
+		assert.Equal(t, "id,link_id,platform,created_at,variant\nclick0,exp149,Twitter,2024-01-01T00:00:00Z,\nclick1,exp149,Twitter,2024-01-02T00:00:00Z,b\n", response.Body)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, params := range []map[string]string{
+			{"type": "users"},
+			{"format": "xml"},
+			{"from": "yesterday"},
+		} {
+			response := export(params)
+			assert.Equal(t, http.StatusBadRequest, response.StatusCode, params)
+		}
+	})
+}
+
+func TestServerExport(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	do := func(method string, path string) *http.Response {
+		req, _ := http.NewRequest(method, srv.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminKey)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := do(http.MethodPost, "/export?type=stats&format=ndjson")
+	resp.Body.Close()
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	location := resp.Header.Get("Location")
+	require.True(t, strings.HasPrefix(location, "/export/"), location)
+
+	// Not downloadable before it completes, or it already has
+	resp = do(http.MethodGet, location+"/download")
+	resp.Body.Close()
+	assert.Contains(t, []int{http.StatusConflict, http.StatusOK}, resp.StatusCode)
+
+	var job domain.ExportJob
+	require.Eventually(t, func() bool {
+		resp := do(http.MethodGet, location)
+		defer resp.Body.Close()
+		return resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(&job) == nil && job.Status == domain.ExportCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, domain.ExportStats, job.Kind)
+	assert.Equal(t, 3, job.Rows)
+	assert.Equal(t, location+"/download", job.DownloadURL)
+
+	resp = do(http.MethodGet, job.DownloadURL)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+	var rows int
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var stat domain.Stats
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &stat))
+		rows++
+	}
+	assert.Equal(t, 3, rows)
+
+	for _, path := range []string{"/export/0123abcd", "/export/not-hex", "/export/0123abcd/download"} {
+		resp := do(http.MethodGet, path)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, path)
+	}
+}
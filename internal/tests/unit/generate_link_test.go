@@ -4,7 +4,6 @@ import (
 	"context"
 	"testing"
 
-	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/cache"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/tests/mock"
@@ -16,9 +15,9 @@ func TestGenerateLinkUnit(t *testing.T) {
 	mockLinkRepo := mock.NewMockLinkRepo()
 	mockStats := mock.NewMockStatsRepo()
 	mockCache := mock.NewImprovedMockCache() // Use mock cache instead of real Redis
-	linkService := services.NewLinkService(mockLinkRepo, mockCache)
-	statsService := services.NewStatsService(mockStats, mockCache)
-	apiHandler := handlers.NewGenerateLinkFunctionHandler(linkService, statsService)
+	linkService := services.NewLinkService(mockLinkRepo, mockCache, mock.NewMockHistoryRepo())
+	statsService := services.NewStatsService(mockStats, mock.NewMockCounterRepo(), mockCache)
+	apiHandler := handlers.NewGenerateLinkFunctionHandler(linkService, statsService, NewTestPolicyService())
 
 	tests := []struct {
 		longURL            string
@@ -27,7 +26,7 @@ func TestGenerateLinkUnit(t *testing.T) {
 	}{
 		{
 			longURL:            "https://example.com/link1",
-			expectedStatusCode: 200,
+			expectedStatusCode: 201,
 			expectedBody:       "",
 		},
 		{
@@ -51,10 +50,72 @@ func TestGenerateLinkUnit(t *testing.T) {
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expectedStatusCode, response.StatusCode)
 
-			if tt.expectedStatusCode != 200 {
+			if tt.expectedStatusCode != 201 {
 				assert.Equal(t, tt.expectedBody, response.Body)
 			}
 		})
 	}
+}
+
+func TestGenerateLinkWithAliasUnit(t *testing.T) {
+	mockLinkRepo := mock.NewMockLinkRepo()
+	mockStats := mock.NewMockStatsRepo()
+	mockCache := mock.NewImprovedMockCache()
+	linkService := services.NewLinkService(mockLinkRepo, mockCache, mock.NewMockHistoryRepo())
+	statsService := services.NewStatsService(mockStats, mock.NewMockCounterRepo(), mockCache)
+	apiHandler := handlers.NewGenerateLinkFunctionHandler(linkService, statsService, NewTestPolicyService())
+
+	tests := []struct {
+		name               string
+		alias              string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "valid alias",
+			alias:              "spring-sale",
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "alias already in use",
+			alias:              "testid1",
+			expectedStatusCode: 409,
+			expectedBody:       "Alias 'testid1' is already in use",
+		},
+		{
+			name:               "reserved alias",
+			alias:              "Stats",
+			expectedStatusCode: 400,
+			expectedBody:       "Alias is reserved",
+		},
+		{
+			name:               "alias too short",
+			alias:              "ab",
+			expectedStatusCode: 400,
+			expectedBody:       "Alias must be 3-64 characters long and contain only letters, digits, '-' or '_'",
+		},
+		{
+			name:               "alias with invalid characters",
+			alias:              "spring/sale",
+			expectedStatusCode: 400,
+			expectedBody:       "Alias must be 3-64 characters long and contain only letters, digits, '-' or '_'",
+		},
+	}
 
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"long": "https://example.com/campaign", "alias": "` + tt.alias + `"}`
+			request := events.APIGatewayV2HTTPRequest{Body: body}
+			response, err := apiHandler.CreateShortLink(context.Background(), request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, response.StatusCode)
+
+			if tt.expectedStatusCode != 201 {
+				assert.Equal(t, tt.expectedBody, response.Body)
+			} else {
+				assert.Contains(t, response.Body, `"id":"`+tt.alias+`"`)
+			}
+		})
+	}
 }
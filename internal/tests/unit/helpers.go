@@ -4,7 +4,9 @@ import (
 	"context"
 
 	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/cache"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
 )
 
 func FillCache(cache *cache.RedisCache, links []domain.Link) error {
@@ -16,3 +18,8 @@ func FillCache(cache *cache.RedisCache, links []domain.Link) error {
 	}
 	return nil
 }
+
+// NewTestPolicyService returns a policy service with the given rules and no reload delay
+func NewTestPolicyService(rules ...domain.PolicyRule) *services.PolicyService {
+	return services.NewPolicyService(repository.NewMemoryPolicyRuleRepository(rules...), []string{"sho.rt"}, 0)
+}
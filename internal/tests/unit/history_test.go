@@ -0,0 +1,346 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/ports"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/tests/mock"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalLinkHistoryRepositories(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "shortener.db")
+	store, err := repository.OpenFileStore(path)
+	require.NoError(t, err)
+
+	repos := []struct {
+		name    string
+		history ports.LinkHistoryPort
+	}{
+		{name: "memory", history: repository.NewMemoryLinkHistoryRepository()},
+		{name: "file", history: store.LinkHistoryRepository()},
+	}
+
+	for _, repo := range repos {
+		t.Run(repo.name, func(t *testing.T) {
+			// Appended out of order, read back sorted by revision id
+			require.NoError(t, repo.history.Append(ctx, domain.LinkRevision{LinkID: "hist1", Id: "2", Version: 2, OriginalURL: "https://example.com/b"}))
+			require.NoError(t, repo.history.Append(ctx, domain.LinkRevision{LinkID: "hist1", Id: "1", Version: 1, OriginalURL: "https://example.com/a"}))
+			require.NoError(t, repo.history.Append(ctx, domain.LinkRevision{LinkID: "other", Id: "1", Version: 1}))
+
+			err := repo.history.Append(ctx, domain.LinkRevision{LinkID: "hist1", Id: "1", Version: 1})
+			assert.True(t, errors.Is(err, domain.ErrConflict), "got %v", err)
+
+			revisions, err := repo.history.ByLinkID(ctx, "hist1")
+			require.NoError(t, err)
+			require.Len(t, revisions, 2)
+			assert.Equal(t, int64(1), revisions[0].Version)
+			assert.Equal(t, int64(2), revisions[1].Version)
+
+			revisions, err = repo.history.ByLinkID(ctx, "missing")
+			require.NoError(t, err)
+			assert.Empty(t, revisions)
+		})
+	}
+
+	// Revisions are replayed from the journal
+	require.NoError(t, store.Close())
+	store, err = repository.OpenFileStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	revisions, err := store.LinkHistoryRepository().ByLinkID(ctx, "hist1")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "https://example.com/b", revisions[1].OriginalURL)
+}
+
+func TestLinkHistoryAndRollback(t *testing.T) {
+	ctx := context.Background()
+	linkService := services.NewLinkService(repository.NewMemoryLinkRepository(), mock.NewImprovedMockCache(), repository.NewMemoryLinkHistoryRepository())
+
+	created := time.Now().Add(-time.Hour)
+	require.NoError(t, linkService.Create(ctx, domain.Link{Id: "hist1", OwnerID: "team", OriginalURL: "https://example.com/v1", Version: 1, CreatedAt: created, CreatedBy: "key1"}))
+	_, err := linkService.Update(ctx, "hist1", "team", "https://example.com/v2", 1, "key2")
+	require.NoError(t, err)
+	_, err = linkService.Update(ctx, "hist1", "team", "https://example.com/v3", 2, "key2")
+	require.NoError(t, err)
+
+	// Rolling back needs the current version too
+	_, err = linkService.Rollback(ctx, "hist1", "team", 1, 2, "key1")
+	assert.True(t, errors.Is(err, domain.ErrVersionConflict), "got %v", err)
+	_, err = linkService.Rollback(ctx, "hist1", "team", 7, 3, "key1")
+	assert.True(t, errors.Is(err, domain.ErrVersionNotFound), "got %v", err)
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+
+	link, err := linkService.Rollback(ctx, "hist1", "team", 1, 3, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/v1", link.OriginalURL)
+	assert.Equal(t, "https://example.com/v3", link.PreviousURL)
+	assert.Equal(t, int64(4), link.Version)
+
+	revisions, err := linkService.History(ctx, "hist1", "team")
+	require.NoError(t, err)
+	require.Len(t, revisions, 4)
+	assert.Equal(t, domain.RevisionCreated, revisions[0].Action)
+	assert.Equal(t, "key1", revisions[0].ChangedBy)
+	assert.Equal(t, domain.RevisionUpdated, revisions[1].Action)
+	assert.Equal(t, "https://example.com/v2", revisions[1].OriginalURL)
+	assert.Equal(t, domain.RevisionRolledBack, revisions[3].Action)
+	assert.Equal(t, int64(1), revisions[3].RestoredVersion)
+	assert.Equal(t, "https://example.com/v1", revisions[3].OriginalURL)
+
+	// Another workspace can't see the history
+	_, err = linkService.History(ctx, "hist1", "other")
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+
+	revision, err := linkService.RevisionAt(ctx, "hist1", "team", created.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), revision.Version, "the updates happened an hour after the link was created")
+	_, err = linkService.RevisionAt(ctx, "hist1", "team", created.Add(-time.Minute))
+	assert.True(t, errors.Is(err, domain.ErrRevisionNotFound), "got %v", err)
+
+	// History outlives the link, but versions from before it was purged can't be restored
+	require.NoError(t, linkService.Delete(ctx, "hist1", "team", "key1"))
+	_, err = linkService.RevisionAt(ctx, "hist1", "team", time.Now())
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+
+	deleted, err := linkService.Get(ctx, "hist1")
+	require.NoError(t, err)
+	require.NoError(t, linkService.Purge(ctx, deleted))
+	require.NoError(t, linkService.Create(ctx, domain.Link{Id: "hist1", OwnerID: "team", OriginalURL: "https://example.com/new", Version: 1, CreatedAt: time.Now()}))
+	_, err = linkService.Rollback(ctx, "hist1", "team", 2, 1, "key1")
+	assert.True(t, errors.Is(err, domain.ErrVersionNotFound), "got %v", err)
+
+	revisions, err = linkService.History(ctx, "hist1", "team")
+	require.NoError(t, err)
+	assert.Len(t, revisions, 6)
+	assert.Equal(t, domain.RevisionDeleted, revisions[4].Action)
+}
+
+func TestHistoryUnit(t *testing.T) {
+	linkService := services.NewLinkService(mock.NewMockLinkRepo(), mock.NewImprovedMockCache(), mock.NewMockHistoryRepo())
+	updateHandler := handlers.NewUpdateLinkFunctionHandler(linkService, NewTestPolicyService())
+	historyHandler := handlers.NewHistoryFunctionHandler(linkService)
+	ctx := handlers.WithAPIKey(context.Background(), domain.APIKey{Id: services.BootstrapKeyID})
+
+	request := events.APIGatewayV2HTTPRequest{Body: `{"long": "https://example.com/edited", "version": 0}`, PathParameters: map[string]string{"id": "testid1"}}
+	response, err := updateHandler.Update(ctx, request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode, response.Body)
+
+	tests := []struct {
+		name               string
+		id                 string
+		body               string
+		expectedStatusCode int
+		expectedMessage    string
+	}{
+		{name: "missing target", id: "testid1", body: `{"version": 1}`, expectedStatusCode: http.StatusBadRequest},
+		{name: "missing version", id: "testid1", body: `{"to_version": 1}`, expectedStatusCode: http.StatusBadRequest},
+		{name: "unknown version", id: "testid1", body: `{"to_version": 5, "version": 1}`, expectedStatusCode: http.StatusNotFound, expectedMessage: "Version not found"},
+		{name: "untracked link", id: "testid2", body: `{"to_version": 0, "version": 0}`, expectedStatusCode: http.StatusOK},
+		{name: "unknown link", id: "missing", body: `{"to_version": 0, "version": 0}`, expectedStatusCode: http.StatusNotFound, expectedMessage: "Link not found"},
+		{name: "stale version", id: "testid1", body: `{"to_version": 1, "version": 0}`, expectedStatusCode: http.StatusConflict},
+		{name: "rollback", id: "testid1", body: `{"to_version": 1, "version": 1}`, expectedStatusCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayV2HTTPRequest{Body: tt.body, PathParameters: map[string]string{"id": tt.id}}
+			response, err := updateHandler.Rollback(ctx, request)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, response.StatusCode, response.Body)
+			assert.Contains(t, response.Body, tt.expectedMessage)
+		})
+	}
+
+	response, err = historyHandler.History(ctx, events.APIGatewayV2HTTPRequest{PathParameters: map[string]string{"id": "testid1"}})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode, response.Body)
+
+	var history handlers.LinkHistory
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &history))
+	require.Len(t, history.Revisions, 2)
+	assert.Equal(t, domain.RevisionUpdated, history.Revisions[0].Action)
+	assert.Equal(t, domain.RevisionRolledBack, history.Revisions[1].Action)
+	assert.Equal(t, int64(1), history.Revisions[1].RestoredVersion)
+	assert.Equal(t, services.BootstrapKeyID, history.Revisions[1].ChangedBy)
+	assert.NotContains(t, response.Body, "owner")
+
+	response, err = historyHandler.History(ctx, events.APIGatewayV2HTTPRequest{
+		PathParameters:        map[string]string{"id": "testid1"},
+		QueryStringParameters: map[string]string{"at": "yesterday"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response, err = historyHandler.History(ctx, events.APIGatewayV2HTTPRequest{
+		PathParameters:        map[string]string{"id": "testid1"},
+		QueryStringParameters: map[string]string{"at": "2000-01-01T00:00:00Z"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	assert.Contains(t, response.Body, "Link didn't exist at that time")
+
+	response, err = historyHandler.History(ctx, events.APIGatewayV2HTTPRequest{
+		PathParameters:        map[string]string{"id": "testid1"},
+		QueryStringParameters: map[string]string{"at": time.Now().Add(time.Minute).Format(time.RFC3339)},
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode, response.Body)
+
+	var revision domain.LinkRevision
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &revision))
+	assert.Equal(t, int64(2), revision.Version)
+	assert.Equal(t, "https://example.com/edited", revision.OriginalURL)
+}
+
+func TestUntrackedLinkHistory(t *testing.T) {
+	ctx := context.Background()
+	historyRepo := mock.NewMockHistoryRepo()
+	linkService := services.NewLinkService(repository.NewMemoryLinkRepository(), mock.NewImprovedMockCache(), historyRepo)
+
+	// Created and edited before history was recorded
+	created := time.Now().Add(-48 * time.Hour)
+	updated := time.Now().Add(-24 * time.Hour)
+	historyRepo.SetFailureMode(true)
+	err := linkService.Create(ctx, domain.Link{
+		Id:          "legacy1",
+		OwnerID:     "team",
+		OriginalURL: "https://example.com/v2",
+		PreviousURL: "https://example.com/v1",
+		Version:     2,
+		CreatedAt:   created,
+		CreatedBy:   "key1",
+		UpdatedAt:   &updated,
+		UpdatedBy:   "key2",
+	})
+	assert.True(t, errors.Is(err, domain.ErrHistoryNotRecorded), "got %v", err)
+	historyRepo.SetFailureMode(false)
+
+	revisions, err := linkService.History(ctx, "legacy1", "team")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, domain.RevisionCreated, revisions[0].Action)
+	assert.Equal(t, int64(1), revisions[0].Version)
+	assert.Equal(t, "https://example.com/v1", revisions[0].OriginalURL)
+	assert.True(t, created.Equal(revisions[0].ChangedAt))
+	assert.Equal(t, domain.RevisionUpdated, revisions[1].Action)
+	assert.Equal(t, int64(2), revisions[1].Version)
+	assert.Equal(t, "key2", revisions[1].ChangedBy)
+
+	_, err = linkService.History(ctx, "legacy1", "other")
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+
+	revision, err := linkService.RevisionAt(ctx, "legacy1", "team", created.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/v1", revision.OriginalURL)
+
+	link, err := linkService.Rollback(ctx, "legacy1", "team", 1, 2, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/v1", link.OriginalURL)
+	assert.Equal(t, int64(3), link.Version)
+
+	// A failed history write is reported even though the change went through
+	historyRepo.SetFailureMode(true)
+	_, err = linkService.Update(ctx, "legacy1", "team", "https://example.com/v4", 3, "key1")
+	assert.True(t, errors.Is(err, domain.ErrHistoryNotRecorded), "got %v", err)
+	link, err = linkService.Get(ctx, "legacy1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/v4", link.OriginalURL)
+
+	err = linkService.Delete(ctx, "legacy1", "team", "key1")
+	assert.True(t, errors.Is(err, domain.ErrHistoryNotRecorded), "got %v", err)
+	response, _ := handlers.ErrorResponse(err)
+	assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+	assert.Contains(t, response.Body, "couldn't be recorded")
+}
+
+func TestRollbackChecksPolicy(t *testing.T) {
+	linkService := services.NewLinkService(repository.NewMemoryLinkRepository(), mock.NewImprovedMockCache(), repository.NewMemoryLinkHistoryRepository())
+	rules := repository.NewMemoryPolicyRuleRepository()
+	updateHandler := handlers.NewUpdateLinkFunctionHandler(linkService, services.NewPolicyService(rules, []string{"sho.rt"}, 0))
+	ctx := handlers.WithAPIKey(context.Background(), domain.APIKey{Id: services.BootstrapKeyID})
+
+	rollback := func(id string, body string) events.APIGatewayProxyResponse {
+		response, err := updateHandler.Rollback(ctx, events.APIGatewayV2HTTPRequest{Body: body, PathParameters: map[string]string{"id": id}})
+		require.NoError(t, err)
+		return response
+	}
+
+	// Created before the domain was denied
+	require.NoError(t, linkService.Create(ctx, domain.Link{Id: "policy1", OriginalURL: "https://evil.com/page", Version: 1, CreatedAt: time.Now()}))
+	_, err := linkService.Update(ctx, "policy1", "", "https://example.com/page", 1, "key1")
+	require.NoError(t, err)
+	rules.Set(domain.PolicyRule{Id: "1", Type: domain.PolicyRuleDomain, Pattern: "evil.com", Action: domain.PolicyDeny})
+
+	response := rollback("policy1", `{"to_version": 1, "version": 2}`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode, response.Body)
+	assert.Contains(t, response.Body, `"reason":"`+domain.ReasonBlockedDomain+`"`)
+
+	// Created through the service, so it never went through the policy
+	require.NoError(t, linkService.Create(ctx, domain.Link{Id: "policy2", OriginalURL: "http://10.0.0.1/admin", Version: 1, CreatedAt: time.Now()}))
+	_, err = linkService.Update(ctx, "policy2", "", "https://example.com/admin", 1, "key1")
+	require.NoError(t, err)
+
+	response = rollback("policy2", `{"to_version": 1, "version": 2}`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode, response.Body)
+	assert.Contains(t, response.Body, `"reason":"`+domain.ReasonPrivateAddress+`"`)
+
+	// Neither link moved
+	for _, id := range []string{"policy1", "policy2"} {
+		link, err := linkService.Get(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), link.Version)
+		assert.True(t, strings.HasPrefix(link.OriginalURL, "https://example.com/"), link.OriginalURL)
+	}
+
+	rules.Set()
+	assert.Equal(t, http.StatusOK, rollback("policy1", `{"to_version": 1, "version": 2}`).StatusCode)
+}
+
+func TestServerLinkHistory(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPatch, srv.URL+"/links/testid3", strings.NewReader(`{"long": "https://example.com/moved", "version": 0}`))
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	req, _ = http.NewRequest(http.MethodPost, srv.URL+"/links/testid3/rollback", strings.NewReader(`{"to_version": 1, "version": 1}`))
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	req, _ = http.NewRequest(http.MethodGet, srv.URL+"/links/testid3/history", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var history handlers.LinkHistory
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&history))
+	assert.Equal(t, "testid3", history.LinkID)
+	assert.Len(t, history.Revisions, 2)
+}
@@ -0,0 +1,153 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/tests/mock"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportUnit(t *testing.T) {
+	ctx := handlers.WithAPIKey(context.Background(), domain.APIKey{Id: services.BootstrapKeyID})
+	policyService := NewTestPolicyService(domain.PolicyRule{Id: "1", Type: domain.PolicyRuleDomain, Pattern: "evil.com", Action: domain.PolicyDeny})
+
+	bitly := "Bitlink,Long URL,Created At\n" +
+		"bit.ly/abc,https://example.com/bitly/abc,2021-03-04T05:06:07+0000\n" +
+		"https://bit.ly/testid1,https://example.com/bitly/taken,2021-03-04T05:06:07+0000\n" +
+		"bit.ly/abc,https://example.com/bitly/again,\n" +
+		"bit.ly/evil,https://evil.com/bitly,\n" +
+		"bit.ly/when,https://example.com/bitly/when,yesterday\n" +
+		"bit.ly/has space,https://example.com/bitly/space,\n"
+
+	run := func(t *testing.T, linkService *services.LinkService, req events.APIGatewayV2HTTPRequest) (int, handlers.ImportReport) {
+		response, err := handlers.NewImportFunctionHandler(linkService, policyService).Import(ctx, req)
+		require.NoError(t, err)
+		var report handlers.ImportReport
+		if response.StatusCode == http.StatusOK {
+			require.NoError(t, json.Unmarshal([]byte(response.Body), &report))
+		}
+		return response.StatusCode, report
+	}
+	newLinkService := func() *services.LinkService {
+		return services.NewLinkService(mock.NewMockLinkRepo(), mock.NewImprovedMockCache(), mock.NewMockHistoryRepo())
+	}
+	csvRequest := func(body string, params map[string]string) events.APIGatewayV2HTTPRequest {
+		return events.APIGatewayV2HTTPRequest{Body: body, Headers: map[string]string{"content-type": "text/csv"}, QueryStringParameters: params}
+	}
+
+	t.Run("bitly csv", func(t *testing.T) {
+		linkService := newLinkService()
+		status, report := run(t, linkService, csvRequest(bitly, nil))
+		require.Equal(t, http.StatusOK, status)
+		assert.False(t, report.DryRun)
+		assert.Equal(t, []int{6, 1, 4, 1}, []int{report.Total, report.Imported, report.Skipped, report.Conflicts})
+
+		results := report.Results
+		assert.Equal(t, domain.ImportResult{Line: 2, Id: "abc", Long: "https://example.com/bitly/abc", Status: domain.ImportImported}, results[0])
+		assert.Equal(t, domain.ImportConflict, results[1].Status)
+		assert.Equal(t, "Id 'testid1' is already in use", results[1].Error)
+		assert.Equal(t, "Duplicate of line 2", results[2].Error)
+		assert.Equal(t, domain.ImportSkipped, results[3].Status)
+		assert.NotEmpty(t, results[3].Reason)
+		assert.Equal(t, "Invalid creation date 'yesterday'", results[4].Error)
+		assert.Equal(t, domain.ImportSkipped, results[5].Status)
+
+		link, err := linkService.Get(ctx, "abc")
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/bitly/abc", link.OriginalURL)
+		assert.True(t, link.CreatedAt.Equal(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)), link.CreatedAt)
+		assert.Equal(t, int64(1), link.Version)
+
+		// The taken id keeps its destination
+		link, err = linkService.Get(ctx, "testid1")
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/link1", link.OriginalURL)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		linkService := newLinkService()
+		status, report := run(t, linkService, csvRequest(bitly, map[string]string{"dry_run": "true"}))
+		require.Equal(t, http.StatusOK, status)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, []int{6, 1, 4, 1}, []int{report.Total, report.Imported, report.Skipped, report.Conflicts})
+
+		_, err := linkService.Get(ctx, "abc")
+		assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("yourls json", func(t *testing.T) {
+		linkService := newLinkService()
+		body := `{"result": "success", "links": {
+			"link_2": {"shorturl": "https://sho.rt/second", "url": "https://example.com/yourls/second", "timestamp": "2020-01-02 03:04:05"},
+			"link_1": {"shorturl": "https://sho.rt/first", "url": "https://example.com/yourls/first", "timestamp": "1577836800"}
+		}}`
+		status, report := run(t, linkService, events.APIGatewayV2HTTPRequest{Body: body})
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, 2, report.Imported, report.Results)
+		assert.Equal(t, "first", report.Results[0].Id)
+		assert.Equal(t, "second", report.Results[1].Id)
+
+		link, err := linkService.Get(ctx, "first")
+		require.NoError(t, err)
+		assert.True(t, link.CreatedAt.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)), link.CreatedAt)
+		link, err = linkService.Get(ctx, "second")
+		require.NoError(t, err)
+		assert.True(t, link.CreatedAt.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)), link.CreatedAt)
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		body := "{\"id\": \"nd1\", \"original_url\": \"https://example.com/nd/1\"}\n{\"id\": \"nd2\", \"original_url\": \"https://example.com/nd/2\"}\n"
+		status, report := run(t, newLinkService(), events.APIGatewayV2HTTPRequest{Body: body})
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 2, report.Imported, report.Results)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for name, test := range map[string]struct {
+			req    events.APIGatewayV2HTTPRequest
+			status int
+		}{
+			"missing column": {csvRequest("id,created\nabc,2020-01-01\n", nil), http.StatusBadRequest},
+			"empty":          {csvRequest("", nil), http.StatusBadRequest},
+			"bad json":       {events.APIGatewayV2HTTPRequest{Body: "{"}, http.StatusBadRequest},
+			"bad dry run":    {csvRequest(bitly, map[string]string{"dry_run": "maybe"}), http.StatusBadRequest},
+			"too many":       {csvRequest("id,url\n"+strings.Repeat("a,https://example.com/many\n", 1001), nil), http.StatusRequestEntityTooLarge},
+		} {
+			status, _ := run(t, newLinkService(), test.req)
+			assert.Equal(t, test.status, status, name)
+		}
+	})
+}
+
+func TestServerImport(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/import", strings.NewReader("keyword,url\nsrvimp,https://example.com/server/import\n"))
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	req.Header.Set("Content-Type", "text/csv")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var report handlers.ImportReport
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(t, 1, report.Imported, report.Results)
+
+	resp, err = http.Get(srv.URL + "/import")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.NotEqual(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,316 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type localRepositories struct {
+	name  string
+	links ports.LinkPort
+	stats ports.StatsPort
+}
+
+func newLocalRepositories(t *testing.T) []localRepositories {
+	store, err := repository.OpenFileStore(filepath.Join(t.TempDir(), "shortener.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	return []localRepositories{
+		{name: "memory", links: repository.NewMemoryLinkRepository(), stats: repository.NewMemoryStatsRepository()},
+		{name: "file", links: store.LinkRepository(), stats: store.StatsRepository()},
+	}
+}
+
+func TestLocalLinkRepositories(t *testing.T) {
+	ctx := context.Background()
+
+	for _, repos := range newLocalRepositories(t) {
+		t.Run(repos.name, func(t *testing.T) {
+			link := domain.Link{Id: "local1", OriginalURL: "https://example.com/local", CreatedAt: time.Now()}
+			require.NoError(t, repos.links.Create(ctx, link))
+
+			// Same collision detection as attribute_not_exists(id)
+			err := repos.links.Create(ctx, domain.Link{Id: "local1", OriginalURL: "https://example.com/other"})
+			assert.True(t, errors.Is(err, domain.ErrConflict))
+
+			got, err := repos.links.Get(ctx, "local1")
+			assert.NoError(t, err)
+			assert.Equal(t, "https://example.com/local", got.OriginalURL)
+
+			_, err = repos.links.Get(ctx, "missing")
+			assert.True(t, errors.Is(err, domain.ErrNotFound))
+
+			all, err := repos.links.All(ctx)
+			assert.NoError(t, err)
+			assert.Len(t, all, 1)
+
+			assert.NoError(t, repos.links.Delete(ctx, "local1", ""))
+			all, err = repos.links.All(ctx)
+			assert.NoError(t, err)
+			assert.Len(t, all, 0)
+
+			err = repos.links.Delete(ctx, "local1", "")
+			assert.True(t, errors.Is(err, domain.ErrNotFound))
+		})
+	}
+}
+
+func TestLocalLinkRepositoriesClickLimit(t *testing.T) {
+	ctx := context.Background()
+
+	for _, repos := range newLocalRepositories(t) {
+		t.Run(repos.name, func(t *testing.T) {
+			require.NoError(t, repos.links.Create(ctx, domain.Link{Id: "limited", OriginalURL: "https://example.com/limited", MaxClicks: 1}))
+
+			assert.NoError(t, repos.links.IncrementClicks(ctx, "limited"))
+			err := repos.links.IncrementClicks(ctx, "limited")
+			assert.True(t, errors.Is(err, domain.ErrExpired))
+
+			got, _ := repos.links.Get(ctx, "limited")
+			assert.Equal(t, int64(1), got.ClickCount)
+
+			err = repos.links.IncrementClicks(ctx, "missing")
+			assert.True(t, errors.Is(err, domain.ErrNotFound))
+		})
+	}
+}
+
+func TestLocalLinkRepositoriesConcurrentCreate(t *testing.T) {
+	ctx := context.Background()
+
+	for _, repos := range newLocalRepositories(t) {
+		t.Run(repos.name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			var mu sync.Mutex
+			created := 0
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if repos.links.Create(ctx, domain.Link{Id: "contended", OriginalURL: "https://example.com/contended"}) == nil {
+						mu.Lock()
+						created++
+						mu.Unlock()
+					}
+				}()
+			}
+			wg.Wait()
+
+			assert.Equal(t, 1, created)
+		})
+	}
+}
+
+func TestLocalStatsRepositories(t *testing.T) {
+	ctx := context.Background()
+
+	for _, repos := range newLocalRepositories(t) {
+		t.Run(repos.name, func(t *testing.T) {
+			for i := 0; i < 3; i++ {
+				require.NoError(t, repos.stats.Create(ctx, domain.Stats{
+					Id:        fmt.Sprintf("stat%d", i),
+					LinkID:    fmt.Sprintf("link%d", i%2),
+					Platform:  domain.PlatformTwitter,
+					CreatedAt: time.Now(),
+				}))
+			}
+
+			stats, err := repos.stats.GetStatsByLinkID(ctx, "link0")
+			assert.NoError(t, err)
+			assert.Len(t, stats, 2)
+
+			none, err := repos.stats.GetStatsByLinkID(ctx, "unknown")
+			assert.NoError(t, err)
+			assert.Len(t, none, 0)
+
+			assert.NoError(t, repos.stats.Delete(ctx, "stat0"))
+			all, err := repos.stats.All(ctx)
+			assert.NoError(t, err)
+			assert.Len(t, all, 2)
+
+			_, err = repos.stats.Get(ctx, "stat0")
+			assert.True(t, errors.Is(err, domain.ErrNotFound))
+			err = repos.stats.Delete(ctx, "stat0")
+			assert.True(t, errors.Is(err, domain.ErrNotFound))
+		})
+	}
+}
+
+func TestLocalStatsRepositoriesDeleteByLinkID(t *testing.T) {
+	ctx := context.Background()
+
+	for _, repos := range newLocalRepositories(t) {
+		t.Run(repos.name, func(t *testing.T) {
+			// More rows than fit in one DynamoDB batch
+			for i := 0; i < 60; i++ {
+				require.NoError(t, repos.stats.Create(ctx, domain.Stats{Id: fmt.Sprintf("purge%d", i), LinkID: "purged", CreatedAt: time.Now()}))
+			}
+			require.NoError(t, repos.stats.Create(ctx, domain.Stats{Id: "kept0", LinkID: "kept", CreatedAt: time.Now()}))
+
+			deleted, err := repos.stats.DeleteByLinkID(ctx, "purged")
+			require.NoError(t, err)
+			assert.Equal(t, 60, deleted)
+
+			stats, err := repos.stats.GetStatsByLinkID(ctx, "purged")
+			require.NoError(t, err)
+			assert.Empty(t, stats)
+			stats, err = repos.stats.GetStatsByLinkID(ctx, "kept")
+			require.NoError(t, err)
+			assert.Len(t, stats, 1)
+
+			deleted, err = repos.stats.DeleteByLinkID(ctx, "purged")
+			require.NoError(t, err)
+			assert.Equal(t, 0, deleted)
+		})
+	}
+}
+
+func TestFileStorePersistsAcrossReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "shortener.db")
+
+	store, err := repository.OpenFileStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.LinkRepository().Create(ctx, domain.Link{Id: "kept", OriginalURL: "https://example.com/kept"}))
+	require.NoError(t, store.LinkRepository().Create(ctx, domain.Link{Id: "dropped", OriginalURL: "https://example.com/dropped"}))
+	require.NoError(t, store.LinkRepository().Delete(ctx, "dropped", ""))
+	require.NoError(t, store.StatsRepository().Create(ctx, domain.Stats{Id: "stat1", LinkID: "kept"}))
+	require.NoError(t, store.StatsRepository().Create(ctx, domain.Stats{Id: "stat2", LinkID: "dropped"}))
+	_, err = store.StatsRepository().DeleteByLinkID(ctx, "dropped")
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	// Simulate a crash in the middle of appending an entry
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"op":"put_link","link":{"id":"tor`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	store, err = repository.OpenFileStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	links, err := store.LinkRepository().All(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, links, 1)
+	assert.Equal(t, "kept", links[0].Id)
+
+	stats, err := store.StatsRepository().GetStatsByLinkID(ctx, "kept")
+	assert.NoError(t, err)
+	assert.Len(t, stats, 1)
+	stats, err = store.StatsRepository().GetStatsByLinkID(ctx, "dropped")
+	assert.NoError(t, err)
+	assert.Empty(t, stats)
+}
+
+func TestLocalCounterRepositories(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "shortener.db")
+	store, err := repository.OpenFileStore(path)
+	require.NoError(t, err)
+
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	counters := map[string]ports.CounterPort{
+		"memory": repository.NewMemoryCounterRepository(),
+		"file":   store.CounterRepository(),
+	}
+
+	for name, counter := range counters {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, counter.Increment(ctx, "counted", domain.PlatformTwitter, "", day.Add(time.Hour)))
+			require.NoError(t, counter.Increment(ctx, "counted", domain.PlatformTwitter, "", day.Add(90*time.Minute)))
+			require.NoError(t, counter.Increment(ctx, "counted", domain.PlatformYouTube, "", day.Add(26*time.Hour)))
+
+			daily, err := counter.GetBuckets(ctx, "counted", domain.GranularityDay, time.Time{}, time.Time{})
+			assert.NoError(t, err)
+			require.Len(t, daily, 2)
+			assert.Equal(t, day, daily[0].Start)
+			assert.Equal(t, int64(2), daily[0].Total)
+			assert.Equal(t, int64(2), daily[0].Platforms["Twitter"])
+
+			hourly, err := counter.GetBuckets(ctx, "counted", domain.GranularityHour, day, day.Add(24*time.Hour))
+			assert.NoError(t, err)
+			require.Len(t, hourly, 1)
+			assert.Equal(t, day.Add(time.Hour), hourly[0].Start)
+
+			// Purging a link removes its hour and day buckets
+			require.NoError(t, counter.Increment(ctx, "purged", domain.PlatformTwitter, "", day))
+			deleted, err := counter.DeleteByLinkID(ctx, "purged")
+			require.NoError(t, err)
+			assert.Equal(t, 2, deleted)
+			daily, err = counter.GetBuckets(ctx, "purged", domain.GranularityDay, time.Time{}, time.Time{})
+			assert.NoError(t, err)
+			assert.Empty(t, daily)
+		})
+	}
+
+	// Compaction folds the clicks into buckets without losing counts
+	require.NoError(t, store.Close())
+	store, err = repository.OpenFileStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	daily, err := store.CounterRepository().GetBuckets(ctx, "counted", domain.GranularityDay, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	require.Len(t, daily, 2)
+	assert.Equal(t, int64(2), daily[0].Total)
+	assert.Equal(t, int64(1), daily[1].Platforms["YouTube"])
+	daily, err = store.CounterRepository().GetBuckets(ctx, "purged", domain.GranularityDay, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Empty(t, daily)
+}
+
+func TestLocalLinkRepositoriesPagination(t *testing.T) {
+	ctx := context.Background()
+	created := time.Now()
+
+	for _, repos := range newLocalRepositories(t) {
+		t.Run(repos.name, func(t *testing.T) {
+			for i := 0; i < 5; i++ {
+				require.NoError(t, repos.links.Create(ctx, domain.Link{
+					Id:          fmt.Sprintf("page%d", i),
+					OriginalURL: "https://example.com/page",
+					CreatedAt:   created.Add(time.Duration(i) * time.Second),
+				}))
+			}
+
+			var ids []string
+			startKey := ""
+			for pages := 0; pages < 5; pages++ {
+				links, nextKey, err := repos.links.AllByOwner(ctx, "", 2, startKey)
+				require.NoError(t, err)
+				for _, link := range links {
+					ids = append(ids, link.Id)
+				}
+				if nextKey == "" {
+					break
+				}
+				startKey = nextKey
+
+				// Deleting an already returned link doesn't shift the next page
+				require.NoError(t, repos.links.Delete(ctx, links[0].Id, ""))
+			}
+
+			assert.Equal(t, []string{"page0", "page1", "page2", "page3", "page4"}, ids)
+
+			for _, limit := range []int32{0, -1} {
+				_, _, err := repos.links.AllByOwner(ctx, "", limit, "")
+				assert.Error(t, err, "limit %d", limit)
+			}
+		})
+	}
+}
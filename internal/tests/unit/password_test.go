@@ -0,0 +1,224 @@
+package unit
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/tests/mock"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordProtectedLinks(t *testing.T) {
+	mockCache := mock.NewImprovedMockCache()
+	linkRepo := mock.NewMockLinkRepo()
+	linkService := services.NewLinkService(linkRepo, mockCache, mock.NewMockHistoryRepo())
+	statsService := services.NewStatsService(mock.NewMockStatsRepo(), mock.NewMockCounterRepo(), mockCache)
+	generateHandler := handlers.NewGenerateLinkFunctionHandler(linkService, statsService, NewTestPolicyService())
+	redirectHandler := handlers.NewRedirectFunctionHandler(linkService, statsService)
+	ctx := handlers.WithAPIKey(context.Background(), domain.APIKey{Id: services.BootstrapKeyID})
+
+	generate := func(body string) events.APIGatewayProxyResponse {
+		response, err := generateHandler.CreateShortLink(ctx, events.APIGatewayV2HTTPRequest{Body: body})
+		require.NoError(t, err)
+		return response
+	}
+	visit := func(id string, sourceIP string, password string) events.APIGatewayProxyResponse {
+		req := events.APIGatewayV2HTTPRequest{RawPath: "/t/" + id, Headers: map[string]string{}}
+		req.RequestContext.HTTP.Method = http.MethodGet
+		req.RequestContext.HTTP.SourceIP = sourceIP
+		if password != "" {
+			req.Headers["x-link-password"] = password
+		}
+		response, err := redirectHandler.Redirect(context.Background(), req)
+		require.NoError(t, err)
+		return response
+	}
+
+	response := generate(`{"long": "https://example.com/internal/docs", "password": "short"}`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response = generate(`{"long": "https://example.com/internal/docs", "password": "open sesame", "cache_max_age": 3600}`)
+	require.Equal(t, http.StatusCreated, response.StatusCode, response.Body)
+	assert.NotContains(t, response.Body, "pbkdf2")
+	var link domain.Link
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &link))
+	stored, err := linkService.Get(context.Background(), link.Id)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(stored.PasswordHash, "pbkdf2-sha256$"), stored.PasswordHash)
+
+	t.Run("form", func(t *testing.T) {
+		response := visit(link.Id, "192.0.2.1", "")
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		assert.Equal(t, "text/html; charset=utf-8", response.Headers["Content-Type"])
+		assert.Contains(t, response.Body, `<form method="post">`)
+		assert.Empty(t, response.Headers["Location"])
+
+		// The form posts the password back to the link, API Gateway may base64 encode it
+		req := events.APIGatewayV2HTTPRequest{
+			RawPath:         "/t/" + link.Id,
+			Headers:         map[string]string{"content-type": "application/x-www-form-urlencoded"},
+			Body:            base64.StdEncoding.EncodeToString([]byte("password=open+sesame")),
+			IsBase64Encoded: true,
+		}
+		req.RequestContext.HTTP.Method = http.MethodPost
+		req.RequestContext.HTTP.SourceIP = "192.0.2.1"
+		response, err := redirectHandler.Redirect(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusSeeOther, response.StatusCode)
+		assert.Equal(t, "https://example.com/internal/docs", response.Headers["Location"])
+		assert.Equal(t, "no-store", response.Headers["Cache-Control"])
+	})
+
+	t.Run("form with 307", func(t *testing.T) {
+		response := generate(`{"long": "https://example.com/internal/api", "password": "open sesame", "redirect_status": 307}`)
+		require.Equal(t, http.StatusCreated, response.StatusCode, response.Body)
+		var temporary domain.Link
+		require.NoError(t, json.Unmarshal([]byte(response.Body), &temporary))
+
+		// A 307 would make the browser post the password to the destination too
+		req := events.APIGatewayV2HTTPRequest{
+			RawPath: "/t/" + temporary.Id,
+			Headers: map[string]string{"content-type": "application/x-www-form-urlencoded"},
+			Body:    "password=open+sesame",
+		}
+		req.RequestContext.HTTP.Method = http.MethodPost
+		req.RequestContext.HTTP.SourceIP = "192.0.2.5"
+		response, err := redirectHandler.Redirect(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusSeeOther, response.StatusCode)
+		assert.Equal(t, "https://example.com/internal/api", response.Headers["Location"])
+		assert.Equal(t, "no-store", response.Headers["Cache-Control"])
+
+		// Header unlocks keep the link's own status
+		assert.Equal(t, http.StatusTemporaryRedirect, visit(temporary.Id, "192.0.2.5", "open sesame").StatusCode)
+	})
+
+	t.Run("header", func(t *testing.T) {
+		response := visit(link.Id, "192.0.2.2", "open sesam")
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		assert.Contains(t, response.Body, "Wrong password.")
+
+		response = visit(link.Id, "192.0.2.2", "open sesame")
+		assert.Equal(t, http.StatusFound, response.StatusCode)
+		assert.Equal(t, "https://example.com/internal/docs", response.Headers["Location"])
+	})
+
+	t.Run("lockout", func(t *testing.T) {
+		// A right password resets the count
+		for i := 0; i < 4; i++ {
+			assert.Equal(t, http.StatusUnauthorized, visit(link.Id, "203.0.113.9", "guess").StatusCode)
+		}
+		assert.Equal(t, http.StatusFound, visit(link.Id, "203.0.113.9", "open sesame").StatusCode)
+
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, http.StatusUnauthorized, visit(link.Id, "203.0.113.9", "guess").StatusCode)
+		}
+		response := visit(link.Id, "203.0.113.9", "open sesame")
+		assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+		assert.Equal(t, "900", response.Headers["Retry-After"])
+		assert.Empty(t, response.Headers["Location"])
+
+		// Other clients aren't locked out
+		assert.Equal(t, http.StatusFound, visit(link.Id, "203.0.113.10", "open sesame").StatusCode)
+	})
+
+	t.Run("concurrent lockout", func(t *testing.T) {
+		// Parallel guesses can't all be checked before the lockout starts
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		statuses := map[int]int{}
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				status := visit(link.Id, "203.0.113.20", "guess").StatusCode
+				mu.Lock()
+				statuses[status]++
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, map[int]int{http.StatusUnauthorized: 5, http.StatusTooManyRequests: 15}, statuses)
+		assert.Equal(t, http.StatusTooManyRequests, visit(link.Id, "203.0.113.20", "open sesame").StatusCode)
+		assert.Equal(t, 15*time.Minute, mockCache.GetTTL("password:"+link.Id+":ip:203.0.113.20"))
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		assert.Eventually(t, func() bool {
+			_, err := mockCache.Get(context.Background(), link.Id)
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, http.StatusUnauthorized, visit(link.Id, "192.0.2.3", "").StatusCode)
+		assert.Equal(t, http.StatusFound, visit(link.Id, "192.0.2.3", "open sesame").StatusCode)
+	})
+
+	t.Run("click limit", func(t *testing.T) {
+		response := generate(`{"long": "https://example.com/internal/once", "password": "open sesame", "max_clicks": 1}`)
+		require.Equal(t, http.StatusCreated, response.StatusCode, response.Body)
+		var limited domain.Link
+		require.NoError(t, json.Unmarshal([]byte(response.Body), &limited))
+
+		// Only visits with the right password use up clicks
+		assert.Equal(t, http.StatusUnauthorized, visit(limited.Id, "192.0.2.4", "").StatusCode)
+		assert.Equal(t, http.StatusUnauthorized, visit(limited.Id, "192.0.2.4", "wrong password").StatusCode)
+		assert.Equal(t, http.StatusFound, visit(limited.Id, "192.0.2.4", "open sesame").StatusCode)
+		assert.Equal(t, http.StatusGone, visit(limited.Id, "192.0.2.4", "open sesame").StatusCode)
+	})
+}
+
+func TestFileStoreKeepsPasswordHash(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store.db")
+	hash, err := services.HashPassword("open sesame")
+	require.NoError(t, err)
+
+	store, err := repository.OpenFileStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.LinkRepository().Create(ctx, domain.Link{Id: "locked", OriginalURL: "https://example.com/locked", PasswordHash: hash}))
+	require.NoError(t, store.Close())
+
+	store, err = repository.OpenFileStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	link, err := store.LinkRepository().Get(ctx, "locked")
+	require.NoError(t, err)
+	assert.Equal(t, hash, link.PasswordHash)
+}
+
+func TestPasswordHashKnownAnswers(t *testing.T) {
+	linkService := services.NewLinkService(mock.NewMockLinkRepo(), mock.NewImprovedMockCache(), mock.NewMockHistoryRepo())
+
+	// PBKDF2-HMAC-SHA256 test vectors of RFC 7914, section 11
+	tests := []struct {
+		password   string
+		salt       string
+		iterations string
+		key        string
+	}{
+		{password: "passwd", salt: "salt", iterations: "1", key: "VawEblbjCJ/sFpHCJUS2BflBhSFt3gRl5oudV8INrLxJypzM8Xm2RZkWZLOdd+8xfHG4RbHjC9UJESBB06GXgw"},
+		{password: "Password", salt: "NaCl", iterations: "80000", key: "TdzY9guYviGDDO5e8icB+WQaRBjQTAQUrv8Ih2s0q1ah1CWhIlgzVJrbhBtRybMXaicr3ruh0HhHj2Kzl/M8jQ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			hash := strings.Join([]string{"pbkdf2-sha256", tt.iterations, tt.salt, tt.key}, "$")
+			redirect := domain.Redirect{PasswordHash: hash}
+			assert.NoError(t, linkService.CheckPassword(context.Background(), "vector", "key:"+tt.password, redirect, tt.password))
+			err := linkService.CheckPassword(context.Background(), "vector", "key:"+tt.password, redirect, tt.password+"!")
+			assert.True(t, errors.Is(err, domain.ErrWrongPassword), "got %v", err)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/tests/mock"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	policy := NewTestPolicyService(
+		domain.PolicyRule{Id: "1", Type: domain.PolicyRuleDomain, Pattern: "evil.com", Action: domain.PolicyDeny},
+		domain.PolicyRule{Id: "2", Type: domain.PolicyRuleWildcard, Pattern: "*.phish.net", Action: domain.PolicyDeny, Reason: "phishing"},
+		domain.PolicyRule{Id: "3", Type: domain.PolicyRuleRegex, Pattern: `\.exe$`, Action: domain.PolicyDeny, Reason: "executable"},
+		domain.PolicyRule{Id: "4", Type: domain.PolicyRuleRegex, Pattern: `(invalid`, Action: domain.PolicyDeny},
+	)
+
+	tests := []struct {
+		url            string
+		requestDomain  string
+		expectedReason string
+	}{
+		{url: "https://example.com/page", expectedReason: ""},
+		{url: "javascript:alert(1)", expectedReason: domain.ReasonBlockedScheme},
+		{url: "https:///no-host", expectedReason: domain.ReasonInvalidURL},
+		{url: "ftp://example.com/file", expectedReason: domain.ReasonBlockedScheme},
+		{url: "https://sho.rt/t/abc", expectedReason: domain.ReasonShortURLLoop},
+		{url: "https://api.example.org/t/abc", requestDomain: "api.example.org", expectedReason: domain.ReasonShortURLLoop},
+		{url: "http://localhost:8080/admin", expectedReason: domain.ReasonPrivateAddress},
+		{url: "http://127.0.0.1/", expectedReason: domain.ReasonPrivateAddress},
+		{url: "http://10.1.2.3/", expectedReason: domain.ReasonPrivateAddress},
+		{url: "http://169.254.169.254/latest/meta-data", expectedReason: domain.ReasonPrivateAddress},
+		{url: "http://[::1]/", expectedReason: domain.ReasonPrivateAddress},
+		{url: "http://2130706433/", expectedReason: domain.ReasonPrivateAddress},
+		{url: "http://0x7f000001/", expectedReason: domain.ReasonPrivateAddress},
+		{url: "http://0177.0.0.1/", expectedReason: domain.ReasonPrivateAddress},
+		{url: "http://127.1/", expectedReason: domain.ReasonPrivateAddress},
+		{url: "http://0xa9.0376.43518/", expectedReason: domain.ReasonPrivateAddress},
+		{url: "http://134744072/", expectedReason: ""},
+		{url: "http://1e100.net/", expectedReason: ""},
+		{url: "https://evil.com/", expectedReason: domain.ReasonBlockedDomain},
+		{url: "https://www.EVIL.com./", expectedReason: domain.ReasonBlockedDomain},
+		{url: "https://notevil.com/", expectedReason: ""},
+		{url: "https://login.phish.net/", expectedReason: "phishing"},
+		{url: "https://example.com/setup.exe", expectedReason: "executable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := policy.Check(context.Background(), tt.url, tt.requestDomain)
+			if tt.expectedReason == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var violation *domain.PolicyViolation
+			require.True(t, errors.As(err, &violation), "expected a policy violation, got %v", err)
+			assert.Equal(t, tt.expectedReason, violation.Reason)
+		})
+	}
+}
+
+func TestPolicyAllowList(t *testing.T) {
+	policy := NewTestPolicyService(
+		domain.PolicyRule{Id: "1", Type: domain.PolicyRuleDomain, Pattern: "example.com", Action: domain.PolicyAllow},
+		domain.PolicyRule{Id: "2", Type: domain.PolicyRuleDomain, Pattern: "private.example.com", Action: domain.PolicyDeny},
+	)
+
+	assert.NoError(t, policy.Check(context.Background(), "https://docs.example.com/", ""))
+
+	var violation *domain.PolicyViolation
+	require.True(t, errors.As(policy.Check(context.Background(), "https://other.org/", ""), &violation))
+	assert.Equal(t, domain.ReasonDomainNotAllowed, violation.Reason)
+
+	// Deny rules win over allow rules
+	require.True(t, errors.As(policy.Check(context.Background(), "https://private.example.com/", ""), &violation))
+	assert.Equal(t, domain.ReasonBlockedDomain, violation.Reason)
+}
+
+func TestPolicyHotReload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[]`), 0o600))
+
+	policy := services.NewPolicyService(repository.NewFilePolicyRuleRepository(path), nil, 0)
+	assert.NoError(t, policy.Check(context.Background(), "https://example.com/", ""))
+
+	require.NoError(t, os.WriteFile(path, []byte(`[{"id": "1", "type": "domain", "pattern": "example.com", "action": "deny"}]`), 0o600))
+	assert.Error(t, policy.Check(context.Background(), "https://example.com/", ""))
+
+	// A broken file keeps the previous rules
+	require.NoError(t, os.WriteFile(path, []byte(`not json`), 0o600))
+	assert.Error(t, policy.Check(context.Background(), "https://example.com/", ""))
+}
+
+func TestPolicyFailsClosed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(path, []byte(`not json`), 0o600))
+	policy := services.NewPolicyService(repository.NewFilePolicyRuleRepository(path), nil, time.Hour)
+
+	// Without rules that ever loaded nothing passes, and every check tries again
+	var violation *domain.PolicyViolation
+	require.True(t, errors.As(policy.Check(context.Background(), "https://example.com/", ""), &violation))
+	assert.Equal(t, domain.ReasonPolicyUnavailable, violation.Reason)
+	response, _ := handlers.PolicyViolationResponse(violation)
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+
+	require.NoError(t, os.WriteFile(path, []byte(`[]`), 0o600))
+	assert.NoError(t, policy.Check(context.Background(), "https://example.com/", ""))
+}
+
+func TestGenerateLinkPolicyViolation(t *testing.T) {
+	mockCache := mock.NewImprovedMockCache()
+	linkService := services.NewLinkService(mock.NewMockLinkRepo(), mockCache, mock.NewMockHistoryRepo())
+	statsService := services.NewStatsService(mock.NewMockStatsRepo(), mock.NewMockCounterRepo(), mockCache)
+	policy := NewTestPolicyService(domain.PolicyRule{Id: "1", Type: domain.PolicyRuleDomain, Pattern: "evil.com", Action: domain.PolicyDeny})
+	apiHandler := handlers.NewGenerateLinkFunctionHandler(linkService, statsService, policy)
+
+	response, err := apiHandler.CreateShortLink(context.Background(), events.APIGatewayV2HTTPRequest{Body: `{"long": "https://evil.com/login"}`})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "application/json", response.Headers["Content-Type"])
+
+	var violation domain.PolicyViolation
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &violation))
+	assert.Equal(t, domain.ReasonBlockedDomain, violation.Reason)
+	assert.NotEmpty(t, violation.Message)
+}
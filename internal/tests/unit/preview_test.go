@@ -0,0 +1,129 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/tests/mock"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkPreview(t *testing.T) {
+	mockCache := mock.NewImprovedMockCache()
+	linkService := services.NewLinkService(mock.NewMockLinkRepo(), mockCache, mock.NewMockHistoryRepo())
+	statsService := services.NewStatsService(mock.NewMockStatsRepo(), mock.NewMockCounterRepo(), mockCache)
+	generateHandler := handlers.NewGenerateLinkFunctionHandler(linkService, statsService, NewTestPolicyService())
+	redirectHandler := handlers.NewRedirectFunctionHandler(linkService, statsService)
+	ctx := handlers.WithAPIKey(context.Background(), domain.APIKey{Id: services.BootstrapKeyID})
+
+	generate := func(body string) domain.Link {
+		response, err := generateHandler.CreateShortLink(ctx, events.APIGatewayV2HTTPRequest{Body: body})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, response.StatusCode, response.Body)
+		var link domain.Link
+		require.NoError(t, json.Unmarshal([]byte(response.Body), &link))
+		return link
+	}
+	visit := func(path string, method string) events.APIGatewayProxyResponse {
+		req := events.APIGatewayV2HTTPRequest{RawPath: path, Headers: map[string]string{}}
+		req.RequestContext.HTTP.Method = method
+		response, err := redirectHandler.Redirect(context.Background(), req)
+		require.NoError(t, err)
+		return response
+	}
+	clicks := func(id string) int64 {
+		summary, err := statsService.GetClickSummary(context.Background(), id, domain.GranularityDay, time.Time{}, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		return summary.Total
+	}
+
+	response, err := generateHandler.CreateShortLink(ctx, events.APIGatewayV2HTTPRequest{Body: `{"long": "https://example.com/preview", "title": "` + strings.Repeat("a", 201) + `"}`})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Contains(t, response.Body, "Title cannot be longer")
+
+	t.Run("plus suffix", func(t *testing.T) {
+		link := generate(`{"long": "https://example.com/preview/docs?a=1&b=<2>", "title": "Team <docs>"}`)
+		assert.Equal(t, "Team <docs>", link.Title)
+
+		assert.Equal(t, http.StatusFound, visit("/t/"+link.Id, http.MethodGet).StatusCode)
+		require.Eventually(t, func() bool { return clicks(link.Id) == 1 }, time.Second, 10*time.Millisecond)
+
+		response := visit("/t/"+link.Id+"+", http.MethodGet)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "text/html; charset=utf-8", response.Headers["Content-Type"])
+		assert.Equal(t, "no-store", response.Headers["Cache-Control"])
+		assert.Empty(t, response.Headers["Location"])
+		assert.Contains(t, response.Body, "<h1>Team &lt;docs&gt;</h1>")
+		assert.Contains(t, response.Body, "https://example.com/preview/docs?a=1&amp;b=&lt;2&gt;")
+		assert.Contains(t, response.Body, "Created "+link.CreatedAt.Format("January 2, 2006")+", clicked 1 time.")
+		assert.Contains(t, response.Body, `<form method="post" action="/t/`+link.Id+`">`)
+
+		// Previews aren't clicks
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, int64(1), clicks(link.Id))
+
+		assert.Equal(t, http.StatusNotFound, visit("/t/missing+", http.MethodGet).StatusCode)
+	})
+
+	t.Run("preview mode", func(t *testing.T) {
+		link := generate(`{"long": "https://example.com/preview/mode", "preview": true, "max_clicks": 1}`)
+		assert.True(t, link.Preview)
+
+		// Every preview shows the page without using up the click
+		for i := 0; i < 3; i++ {
+			response := visit("/t/"+link.Id, http.MethodGet)
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.Contains(t, response.Body, "<h1>Link preview</h1>")
+			assert.Contains(t, response.Body, "clicked 0 times.")
+		}
+
+		response := visit("/t/"+link.Id, http.MethodPost)
+		assert.Equal(t, http.StatusSeeOther, response.StatusCode)
+		assert.Equal(t, "https://example.com/preview/mode", response.Headers["Location"])
+		assert.Equal(t, http.StatusGone, visit("/t/"+link.Id, http.MethodPost).StatusCode)
+		assert.Equal(t, http.StatusGone, visit("/t/"+link.Id+"+", http.MethodGet).StatusCode)
+	})
+
+	t.Run("continue with 307", func(t *testing.T) {
+		// Continuing posts the form, a 307 would make the browser post it to the destination too
+		link := generate(`{"long": "https://example.com/preview/temporary", "preview": true, "redirect_status": 307}`)
+		response := visit("/t/"+link.Id, http.MethodPost)
+		assert.Equal(t, http.StatusSeeOther, response.StatusCode)
+		assert.Equal(t, "https://example.com/preview/temporary", response.Headers["Location"])
+		assert.Equal(t, "no-store", response.Headers["Cache-Control"])
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		link := generate(`{"long": "https://example.com/preview/cached", "preview": true}`)
+		assert.Equal(t, http.StatusOK, visit("/t/"+link.Id, http.MethodGet).StatusCode)
+		require.Eventually(t, func() bool {
+			_, err := mockCache.Get(context.Background(), link.Id)
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, http.StatusOK, visit("/t/"+link.Id, http.MethodGet).StatusCode)
+		assert.Equal(t, http.StatusSeeOther, visit("/t/"+link.Id, http.MethodPost).StatusCode)
+	})
+
+	t.Run("protected", func(t *testing.T) {
+		link := generate(`{"long": "https://example.com/preview/secret", "password": "open sesame", "preview": true}`)
+		response := visit("/t/"+link.Id, http.MethodGet)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.NotContains(t, response.Body, "secret")
+		assert.Contains(t, response.Body, "password protected")
+
+		// Continuing asks for the password
+		response = visit("/t/"+link.Id, http.MethodPost)
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		assert.Contains(t, response.Body, `name="password"`)
+	})
+}
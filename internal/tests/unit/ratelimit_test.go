@@ -0,0 +1,100 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/cache"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	limiter := cache.NewMemoryRateLimiter()
+	limit := domain.RateLimit{Requests: 2, Window: 100 * time.Millisecond}
+
+	for i := 0; i < limit.Requests; i++ {
+		allowed, _, err := limiter.Allow(ctx, "client", limit)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	allowed, retryAfter, err := limiter.Allow(ctx, "client", limit)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.True(t, retryAfter > 0 && retryAfter <= limit.Window, "retry after %v", retryAfter)
+
+	// Other clients have their own window
+	allowed, _, err = limiter.Allow(ctx, "other", limit)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	time.Sleep(retryAfter)
+	allowed, _, err = limiter.Allow(ctx, "client", limit)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestRedisRateLimiterFallsBackToMemory(t *testing.T) {
+	unreachable := cache.NewRedisCache("127.0.0.1:1", "", 0)
+	limiter := cache.NewRedisRateLimiter(unreachable, cache.NewMemoryRateLimiter())
+	limit := domain.RateLimit{Requests: 1, Window: time.Minute}
+
+	allowed, _, err := limiter.Allow(context.Background(), "client", limit)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, retryAfter, err := limiter.Allow(context.Background(), "client", limit)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Greater(t, retryAfter, time.Duration(0))
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	rateLimiter := handlers.NewRateLimiter(services.NewRateLimitService(cache.NewMemoryRateLimiter()))
+	limit := domain.RateLimit{Requests: 1, Window: time.Minute}
+	handler := rateLimiter.Limit(handlers.RateLimitRedirect, limit, func(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayProxyResponse, error) {
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+	})
+
+	fromIP := func(ip string) events.APIGatewayV2HTTPRequest {
+		req := events.APIGatewayV2HTTPRequest{}
+		req.RequestContext.HTTP.SourceIP = ip
+		return req
+	}
+	withKey := handlers.WithAPIKey(context.Background(), domain.APIKey{Id: "key1"})
+
+	tests := []struct {
+		name               string
+		ctx                context.Context
+		req                events.APIGatewayV2HTTPRequest
+		expectedStatusCode int
+	}{
+		{name: "first request", ctx: context.Background(), req: fromIP("192.0.2.1"), expectedStatusCode: http.StatusOK},
+		{name: "same IP over the limit", ctx: context.Background(), req: fromIP("192.0.2.1"), expectedStatusCode: http.StatusTooManyRequests},
+		{name: "other IP", ctx: context.Background(), req: fromIP("192.0.2.2"), expectedStatusCode: http.StatusOK},
+		{name: "API key from a limited IP", ctx: withKey, req: fromIP("192.0.2.1"), expectedStatusCode: http.StatusOK},
+		{name: "same API key from another IP", ctx: withKey, req: fromIP("192.0.2.3"), expectedStatusCode: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := handler(tt.ctx, tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, response.StatusCode)
+			if tt.expectedStatusCode == http.StatusTooManyRequests {
+				seconds, err := strconv.Atoi(response.Headers["Retry-After"])
+				require.NoError(t, err)
+				assert.True(t, seconds >= 1 && seconds <= 60, "Retry-After %d", seconds)
+			}
+		})
+	}
+}
@@ -2,22 +2,28 @@ package unit
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/cache"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/tests/mock"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRedirectLinkUnit(t *testing.T) {
 	mockLinkRepo := mock.NewMockLinkRepo()
 	cache := cache.NewRedisCache("localhost:6379", "", 0)
 	FillCache(cache, mockLinkRepo.Links)
-	linkService := services.NewLinkService(mockLinkRepo, cache)
-	statsService := services.NewStatsService(mock.NewMockStatsRepo(), cache)
+	linkService := services.NewLinkService(mockLinkRepo, cache, mock.NewMockHistoryRepo())
+	statsService := services.NewStatsService(mock.NewMockStatsRepo(), mock.NewMockCounterRepo(), cache)
 	apiHandler := handlers.NewRedirectFunctionHandler(linkService, statsService)
 
 	tests := []struct {
@@ -28,19 +34,19 @@ func TestRedirectLinkUnit(t *testing.T) {
 	}{
 		{
 			shortLink:        "testid1",
-			expectStatusCode: 301,
+			expectStatusCode: 302,
 			expectLocation:   "https://example.com/link1",
 			expectBody:       "",
 		},
 		{
 			shortLink:        "testid2",
-			expectStatusCode: 301,
+			expectStatusCode: 302,
 			expectLocation:   "https://example.com/link2",
 			expectBody:       "",
 		},
 		{
 			shortLink:        "testid3",
-			expectStatusCode: 301,
+			expectStatusCode: 302,
 			expectLocation:   "https://example.com/link3",
 			expectBody:       "",
 		},
@@ -72,3 +78,256 @@ func TestRedirectLinkUnit(t *testing.T) {
 		})
 	}
 }
+
+func TestRedirectOptions(t *testing.T) {
+	mockCache := mock.NewImprovedMockCache()
+	linkService := services.NewLinkService(mock.NewMockLinkRepo(), mockCache, mock.NewMockHistoryRepo())
+	statsService := services.NewStatsService(mock.NewMockStatsRepo(), mock.NewMockCounterRepo(), mockCache)
+	generateHandler := handlers.NewGenerateLinkFunctionHandler(linkService, statsService, NewTestPolicyService())
+	redirectHandler := handlers.NewRedirectFunctionHandler(linkService, statsService)
+	ctx := handlers.WithAPIKey(context.Background(), domain.APIKey{Id: services.BootstrapKeyID})
+
+	generate := func(body string) (int, domain.Link) {
+		response, err := generateHandler.CreateShortLink(ctx, events.APIGatewayV2HTTPRequest{Body: body})
+		require.NoError(t, err)
+		var link domain.Link
+		if response.StatusCode == http.StatusCreated {
+			require.NoError(t, json.Unmarshal([]byte(response.Body), &link))
+		}
+		return response.StatusCode, link
+	}
+
+	tests := []struct {
+		name         string
+		body         string
+		status       int
+		cacheControl string
+	}{
+		{name: "default", body: `{"long": "https://example.com/options/default"}`, status: http.StatusFound, cacheControl: "no-store"},
+		{name: "permanent cached", body: `{"long": "https://example.com/options/cached", "redirect_status": 308, "cache_max_age": 3600}`, status: http.StatusPermanentRedirect, cacheControl: "public, max-age=3600"},
+		{name: "moved", body: `{"long": "https://example.com/options/moved", "redirect_status": 301}`, status: http.StatusMovedPermanently, cacheControl: "no-store"},
+		{name: "temporary", body: `{"long": "https://example.com/options/temporary", "redirect_status": 307}`, status: http.StatusTemporaryRedirect, cacheControl: "no-store"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, link := generate(tt.body)
+			require.Equal(t, http.StatusCreated, status)
+
+			// The first redirect reads the link, the second one the cache
+			assert.Eventually(t, func() bool {
+				_, err := mockCache.Get(context.Background(), link.Id)
+				return err == nil
+			}, time.Second, 10*time.Millisecond)
+			mockCache.Delete(context.Background(), link.Id)
+			for i := 0; i < 2; i++ {
+				response, err := redirectHandler.Redirect(context.Background(), events.APIGatewayV2HTTPRequest{RawPath: "/" + link.Id})
+				require.NoError(t, err)
+				assert.Equal(t, tt.status, response.StatusCode)
+				assert.Equal(t, link.OriginalURL, response.Headers["Location"])
+				assert.Equal(t, tt.cacheControl, response.Headers["Cache-Control"])
+				assert.Eventually(t, func() bool {
+					_, err := mockCache.Get(context.Background(), link.Id)
+					return err == nil
+				}, time.Second, 10*time.Millisecond)
+			}
+		})
+	}
+
+	for _, body := range []string{
+		`{"long": "https://example.com/options/bad", "redirect_status": 303}`,
+		`{"long": "https://example.com/options/bad", "redirect_status": 200}`,
+		`{"long": "https://example.com/options/bad", "cache_max_age": -1}`,
+		`{"long": "https://example.com/options/bad", "cache_max_age": 99999999999}`,
+	} {
+		status, _ := generate(body)
+		assert.Equal(t, http.StatusBadRequest, status, body)
+	}
+}
+
+func TestExtractVisitorFromRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected domain.Visitor
+	}{
+		{
+			name:     "iphone instagram",
+			headers:  map[string]string{"user-agent": "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Mobile/15E148 Instagram 300.0", "accept-language": "de-DE,de;q=0.9,en;q=0.8"},
+			expected: domain.Visitor{Platform: domain.PlatformInstagram, OS: domain.OSiOS, Device: domain.DeviceMobile, Language: "de-de"},
+		},
+		{
+			name:     "android",
+			headers:  map[string]string{"user-agent": "Mozilla/5.0 (Linux; Android 14; Pixel 8) Chrome/120.0 Mobile Safari/537.36", "accept-language": "fr;q=0.5, es"},
+			expected: domain.Visitor{OS: domain.OSAndroid, Device: domain.DeviceMobile, Language: "es"},
+		},
+		{
+			name:     "mac desktop",
+			headers:  map[string]string{"user-agent": "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) Safari/605.1.15", "referer": "https://twitter.com/someone"},
+			expected: domain.Visitor{Platform: domain.PlatformTwitter, OS: domain.OSMacOS, Device: domain.DeviceDesktop},
+		},
+		{
+			name:     "windows",
+			headers:  map[string]string{"user-agent": "Mozilla/5.0 (Windows NT 10.0; Win64; x64)", "accept-language": "*, en-GB;q=0.7, bogus;q=x"},
+			expected: domain.Visitor{OS: domain.OSWindows, Device: domain.DeviceDesktop, Language: "en-gb"},
+		},
+		{
+			name:     "no headers",
+			expected: domain.Visitor{Device: domain.DeviceDesktop},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, handlers.ExtractVisitorFromRequest(events.APIGatewayV2HTTPRequest{Headers: tt.headers}))
+		})
+	}
+}
+
+func TestRedirectTargeting(t *testing.T) {
+	mockCache := mock.NewImprovedMockCache()
+	linkService := services.NewLinkService(mock.NewMockLinkRepo(), mockCache, mock.NewMockHistoryRepo())
+	statsService := services.NewStatsService(mock.NewMockStatsRepo(), mock.NewMockCounterRepo(), mockCache)
+	policyService := NewTestPolicyService(domain.PolicyRule{Id: "1", Type: domain.PolicyRuleDomain, Pattern: "evil.com", Action: domain.PolicyDeny})
+	generateHandler := handlers.NewGenerateLinkFunctionHandler(linkService, statsService, policyService)
+	redirectHandler := handlers.NewRedirectFunctionHandler(linkService, statsService)
+	ctx := handlers.WithAPIKey(context.Background(), domain.APIKey{Id: services.BootstrapKeyID})
+
+	generate := func(body string) events.APIGatewayProxyResponse {
+		response, err := generateHandler.CreateShortLink(ctx, events.APIGatewayV2HTTPRequest{Body: body})
+		require.NoError(t, err)
+		return response
+	}
+
+	response := generate(`{"long": "https://example.com/app", "targets": [
+		{"os": "ios", "url": "https://apps.apple.com/app/id123"},
+		{"os": "android", "url": "https://play.google.com/store/apps/details?id=com.example"},
+		{"platform": "Instagram", "device": "desktop", "url": "https://example.com/app/instagram"},
+		{"language": "de", "url": "https://example.com/app/de"}
+	]}`)
+	require.Equal(t, http.StatusCreated, response.StatusCode, response.Body)
+	var link domain.Link
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &link))
+	require.Len(t, link.Targets, 4)
+
+	visits := []struct {
+		name     string
+		headers  map[string]string
+		expected string
+	}{
+		{name: "ios", headers: map[string]string{"user-agent": "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)", "accept-language": "de"}, expected: "https://apps.apple.com/app/id123"},
+		{name: "android", headers: map[string]string{"user-agent": "Mozilla/5.0 (Linux; Android 14) Mobile"}, expected: "https://play.google.com/store/apps/details?id=com.example"},
+		{name: "instagram desktop", headers: map[string]string{"user-agent": "Mozilla/5.0 (Windows NT 10.0)", "referer": "https://www.instagram.com/"}, expected: "https://example.com/app/instagram"},
+		{name: "german", headers: map[string]string{"user-agent": "Mozilla/5.0 (X11; Linux x86_64)", "accept-language": "de-AT,en;q=0.5"}, expected: "https://example.com/app/de"},
+		{name: "fallback", headers: map[string]string{"user-agent": "curl/8.0"}, expected: "https://example.com/app"},
+	}
+	// Once from the link and once from the cache
+	for i := 0; i < 2; i++ {
+		for _, visit := range visits {
+			response, err := redirectHandler.Redirect(context.Background(), events.APIGatewayV2HTTPRequest{RawPath: "/" + link.Id, Headers: visit.headers})
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusFound, response.StatusCode, visit.name)
+			assert.Equal(t, visit.expected, response.Headers["Location"], visit.name)
+			assert.NotEmpty(t, response.Headers["Vary"], visit.name)
+		}
+		assert.Eventually(t, func() bool {
+			_, err := mockCache.Get(context.Background(), link.Id)
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	for _, body := range []string{
+		`{"long": "https://example.com/app", "targets": [{"url": "https://example.com/app/all"}]}`,
+		`{"long": "https://example.com/app", "targets": [{"os": "beos", "url": "https://example.com/app/beos"}]}`,
+		`{"long": "https://example.com/app", "targets": [{"platform": "myspace", "url": "https://example.com/app/myspace"}]}`,
+		`{"long": "https://example.com/app", "targets": [{"device": "watch", "url": "https://example.com/app/watch"}]}`,
+		`{"long": "https://example.com/app", "targets": [{"language": "en_US", "url": "https://example.com/app/en"}]}`,
+		`{"long": "https://example.com/app", "targets": [{"os": "ios", "url": "not a url"}]}`,
+	} {
+		response := generate(body)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode, body)
+	}
+	response = generate(`{"long": "https://example.com/app", "targets": [{"os": "ios", "url": "https://evil.com/app"}]}`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Contains(t, response.Body, "blocked_domain")
+}
+
+func TestRedirectVariants(t *testing.T) {
+	mockCache := mock.NewImprovedMockCache()
+	linkService := services.NewLinkService(mock.NewMockLinkRepo(), mockCache, mock.NewMockHistoryRepo())
+	statsService := services.NewStatsService(mock.NewMockStatsRepo(), mock.NewMockCounterRepo(), mockCache)
+	generateHandler := handlers.NewGenerateLinkFunctionHandler(linkService, statsService, NewTestPolicyService())
+	redirectHandler := handlers.NewRedirectFunctionHandler(linkService, statsService)
+	ctx := handlers.WithAPIKey(context.Background(), domain.APIKey{Id: services.BootstrapKeyID})
+
+	generate := func(body string) events.APIGatewayProxyResponse {
+		response, err := generateHandler.CreateShortLink(ctx, events.APIGatewayV2HTTPRequest{Body: body})
+		require.NoError(t, err)
+		return response
+	}
+	visit := func(id string, sourceIP string, cookies ...string) events.APIGatewayProxyResponse {
+		req := events.APIGatewayV2HTTPRequest{RawPath: "/" + id, Cookies: cookies, Headers: map[string]string{"user-agent": "Mozilla/5.0 (X11; Linux x86_64)"}}
+		req.RequestContext.HTTP.SourceIP = sourceIP
+		response, err := redirectHandler.Redirect(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusFound, response.StatusCode)
+		return response
+	}
+
+	response := generate(`{"long": "https://example.com/landing", "variants": [
+		{"url": "https://example.com/landing/a"},
+		{"name": "bold", "url": "https://example.com/landing/bold", "weight": 3}
+	], "targets": [{"os": "ios", "url": "https://example.com/landing/ios"}]}`)
+	require.Equal(t, http.StatusCreated, response.StatusCode, response.Body)
+	var link domain.Link
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &link))
+	assert.Equal(t, []domain.Variant{
+		{Name: "a", URL: "https://example.com/landing/a", Weight: 1},
+		{Name: "bold", URL: "https://example.com/landing/bold", Weight: 3},
+	}, link.Variants)
+
+	// The cookie wins over the IP address, an unknown variant in it is ignored
+	response = visit(link.Id, "192.0.2.1", "variant=a")
+	assert.Equal(t, "https://example.com/landing/a", response.Headers["Location"])
+	assert.Contains(t, response.Headers["Set-Cookie"], "variant=a")
+	assert.Contains(t, response.Headers["Set-Cookie"], "Path=/"+link.Id)
+	assert.Contains(t, response.Headers["Vary"], "Cookie")
+	response = visit(link.Id, "192.0.2.1", "variant=bold")
+	assert.Equal(t, "https://example.com/landing/bold", response.Headers["Location"])
+
+	// Without the cookie each IP address sticks to its variant, spread by weight
+	served := map[string]int64{}
+	for i := 0; i < 400; i++ {
+		ip := fmt.Sprintf("198.51.%d.%d", i/200, i%200)
+		first := visit(link.Id, ip, "variant=gone").Headers["Location"]
+		assert.Equal(t, first, visit(link.Id, ip).Headers["Location"], ip)
+		served[first] += 2
+	}
+	assert.InDelta(t, 600, served["https://example.com/landing/bold"], 150)
+	assert.Equal(t, int64(800), served["https://example.com/landing/a"]+served["https://example.com/landing/bold"])
+
+	// Targeted visitors aren't part of the split
+	req := events.APIGatewayV2HTTPRequest{RawPath: "/" + link.Id, Headers: map[string]string{"user-agent": "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)"}}
+	response, err := redirectHandler.Redirect(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/landing/ios", response.Headers["Location"])
+	assert.Empty(t, response.Headers["Set-Cookie"])
+
+	// Clicks are counted per variant, 402 split visits and the targeted one
+	var summary domain.ClickSummary
+	assert.Eventually(t, func() bool {
+		summary, err = statsService.GetClickSummary(context.Background(), link.Id, domain.GranularityDay, time.Time{}, time.Time{})
+		return err == nil && summary.Total == 803
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, map[string]int64{"a": served["https://example.com/landing/a"] + 1, "bold": served["https://example.com/landing/bold"] + 1}, summary.Variants)
+
+	for _, body := range []string{
+		`{"long": "https://example.com/landing", "variants": [{"url": "https://example.com/landing/a"}]}`,
+		`{"long": "https://example.com/landing", "variants": [{"name": "x", "url": "https://example.com/landing/a"}, {"name": "x", "url": "https://example.com/landing/b"}]}`,
+		`{"long": "https://example.com/landing", "variants": [{"name": "a b", "url": "https://example.com/landing/a"}, {"url": "https://example.com/landing/b"}]}`,
+		`{"long": "https://example.com/landing", "variants": [{"url": "https://example.com/landing/a", "weight": -1}, {"url": "https://example.com/landing/b"}]}`,
+		`{"long": "https://example.com/landing", "variants": [{"url": "https://example.com/landing/a"}, {"url": "nope"}]}`,
+	} {
+		response := generate(body)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode, body)
+	}
+}
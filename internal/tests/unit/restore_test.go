@@ -0,0 +1,215 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/tests/mock"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalLinkRepositoriesSoftDelete(t *testing.T) {
+	ctx := context.Background()
+
+	for _, repos := range newLocalRepositories(t) {
+		t.Run(repos.name, func(t *testing.T) {
+			require.NoError(t, repos.links.Create(ctx, domain.Link{Id: "soft1", OwnerID: "team", OriginalURL: "https://example.com/soft"}))
+
+			deletedAt := time.Now()
+			err := repos.links.SoftDelete(ctx, "soft1", "other", deletedAt, "key1")
+			assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+			require.NoError(t, repos.links.SoftDelete(ctx, "soft1", "team", deletedAt, "key1"))
+			err = repos.links.SoftDelete(ctx, "soft1", "team", deletedAt, "key1")
+			assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+
+			// Tombstoned links keep their id
+			link, err := repos.links.Get(ctx, "soft1")
+			require.NoError(t, err)
+			require.True(t, link.IsDeleted())
+			assert.Equal(t, "key1", link.DeletedBy)
+			err = repos.links.Create(ctx, domain.Link{Id: "soft1", OwnerID: "team"})
+			assert.True(t, errors.Is(err, domain.ErrConflict), "got %v", err)
+
+			// Deleted before the restore window started
+			err = repos.links.Restore(ctx, "soft1", "team", deletedAt.Add(time.Minute))
+			assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+			err = repos.links.Restore(ctx, "soft1", "other", deletedAt.Add(-time.Hour))
+			assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+
+			require.NoError(t, repos.links.Restore(ctx, "soft1", "team", deletedAt.Add(-time.Hour)))
+			link, err = repos.links.Get(ctx, "soft1")
+			require.NoError(t, err)
+			assert.False(t, link.IsDeleted())
+			assert.Empty(t, link.DeletedBy)
+
+			err = repos.links.Restore(ctx, "soft1", "team", deletedAt.Add(-time.Hour))
+			assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+		})
+	}
+}
+
+func TestDeleteAndRestoreLink(t *testing.T) {
+	ctx := context.Background()
+	mockCache := mock.NewImprovedMockCache()
+	linkRepo := mock.NewMockLinkRepo()
+	linkService := services.NewLinkService(linkRepo, mockCache, mock.NewMockHistoryRepo())
+
+	require.NoError(t, mockCache.Set(ctx, "testid1", "https://example.com/link1"))
+	require.NoError(t, linkService.Delete(ctx, "testid1", "", "key1"))
+
+	// The cached destination is gone along with the link
+	_, err := linkService.GetOriginalURL(ctx, "testid1")
+	assert.True(t, errors.Is(err, domain.ErrDeleted), "got %v", err)
+	err = linkService.Delete(ctx, "testid1", "", "key1")
+	assert.True(t, errors.Is(err, domain.ErrDeleted), "got %v", err)
+	_, err = linkService.Update(ctx, "testid1", "", "https://example.com/new", 0, "key1")
+	assert.True(t, errors.Is(err, domain.ErrDeleted), "got %v", err)
+
+	// Past the restore window
+	_, err = linkService.Restore(ctx, "testid1", "", time.Now().Add(time.Minute), "key1")
+	assert.True(t, errors.Is(err, domain.ErrExpired), "got %v", err)
+	_, err = linkService.Restore(ctx, "testid2", "", time.Now().Add(-time.Hour), "key1")
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+
+	link, err := linkService.Restore(ctx, "testid1", "", time.Now().Add(-time.Hour), "key2")
+	require.NoError(t, err)
+	assert.False(t, link.IsDeleted())
+
+	url, err := linkService.GetOriginalURL(ctx, "testid1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/link1", *url)
+
+	revisions, err := linkService.History(ctx, "testid1", "")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, domain.RevisionDeleted, revisions[0].Action)
+	assert.Equal(t, domain.RevisionRestored, revisions[1].Action)
+	assert.Equal(t, "key2", revisions[1].ChangedBy)
+	assert.Equal(t, "https://example.com/link1", revisions[1].OriginalURL)
+}
+
+func TestPurgeDeletedLinks(t *testing.T) {
+	ctx := context.Background()
+	mockCache := mock.NewImprovedMockCache()
+	linkRepo := repository.NewMemoryLinkRepository()
+	statsRepo := repository.NewMemoryStatsRepository()
+	linkService := services.NewLinkService(linkRepo, mockCache, repository.NewMemoryLinkHistoryRepository())
+	counterRepo := repository.NewMemoryCounterRepository()
+	statsService := services.NewStatsService(statsRepo, counterRepo, mockCache)
+
+	for _, id := range []string{"old", "recent", "live"} {
+		require.NoError(t, linkRepo.Create(ctx, domain.Link{Id: id, OriginalURL: "https://example.com/" + id}))
+		for _, statsID := range []string{id + "-a", id + "-b"} {
+			require.NoError(t, statsService.Create(ctx, domain.Stats{Id: statsID, LinkID: id, CreatedAt: time.Now()}))
+		}
+	}
+	require.NoError(t, linkRepo.SoftDelete(ctx, "old", "", time.Now().Add(-48*time.Hour), "key1"))
+	require.NoError(t, linkRepo.SoftDelete(ctx, "recent", "", time.Now().Add(-time.Hour), "key1"))
+
+	purge := handlers.NewPurgeFunctionHandler(linkService, statsService, 24*time.Hour)
+	result, err := purge.Purge(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, handlers.PurgeResult{PurgedLinks: 1, DeletedStats: 2}, result)
+
+	_, err = linkRepo.Get(ctx, "old")
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+	stats, err := statsRepo.GetStatsByLinkID(ctx, "old")
+	require.NoError(t, err)
+	assert.Empty(t, stats)
+	buckets, err := counterRepo.GetBuckets(ctx, "old", domain.GranularityDay, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, buckets)
+
+	// Links within the restore window and live links keep their stats
+	for _, id := range []string{"recent", "live"} {
+		_, err := linkRepo.Get(ctx, id)
+		assert.NoError(t, err)
+		stats, err := statsRepo.GetStatsByLinkID(ctx, id)
+		require.NoError(t, err)
+		assert.Len(t, stats, 2)
+		buckets, err := counterRepo.GetBuckets(ctx, id, domain.GranularityDay, time.Time{}, time.Time{})
+		require.NoError(t, err)
+		assert.Len(t, buckets, 1)
+	}
+
+	// Live links are never purged
+	live, err := linkService.Get(ctx, "live")
+	require.NoError(t, err)
+	assert.Error(t, linkService.Purge(ctx, live))
+}
+
+func TestRestoreLinkUnit(t *testing.T) {
+	linkService := services.NewLinkService(mock.NewMockLinkRepo(), mock.NewImprovedMockCache(), mock.NewMockHistoryRepo())
+	apiHandler := handlers.NewDeleteFunctionHandler(linkService, time.Hour)
+	ctx := handlers.WithAPIKey(context.Background(), domain.APIKey{Id: services.BootstrapKeyID})
+
+	tests := []struct {
+		name               string
+		method             string
+		id                 string
+		expectedStatusCode int
+	}{
+		{name: "not deleted", method: http.MethodPost, id: "testid1", expectedStatusCode: http.StatusNotFound},
+		{name: "delete", method: http.MethodDelete, id: "testid1", expectedStatusCode: http.StatusNoContent},
+		{name: "restore", method: http.MethodPost, id: "testid1", expectedStatusCode: http.StatusOK},
+		{name: "restore again", method: http.MethodPost, id: "testid1", expectedStatusCode: http.StatusNotFound},
+		{name: "unknown link", method: http.MethodPost, id: "nonexistentid", expectedStatusCode: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodGet, id: "testid1", expectedStatusCode: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayV2HTTPRequest{PathParameters: map[string]string{"id": tt.id}}
+			request.RequestContext.HTTP.Method = tt.method
+			response, err := apiHandler.Handle(ctx, request)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, response.StatusCode, response.Body)
+		})
+	}
+}
+
+func TestServerDeleteAndRestore(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/delete/testid2", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, err = client.Get(srv.URL + "/t/testid2")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusGone, resp.StatusCode)
+
+	req, _ = http.NewRequest(http.MethodPost, srv.URL+"/links/testid2/restore", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var link domain.Link
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&link))
+	assert.Nil(t, link.DeletedAt)
+
+	resp, err = client.Get(srv.URL + "/t/testid2")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+}
@@ -0,0 +1,141 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/cache"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/queue"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/server"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/tests/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// testAdminKey is the bootstrap admin key of the test server
+const testAdminKey = "test-admin-key"
+
+func newTestServer() *httptest.Server {
+	// Zero limits, the rate limiter is tested separately
+	return newLimitedTestServer(domain.RateLimit{}, domain.RateLimit{})
+}
+
+// newLimitedTestServer returns a test server limiting link creation and bulk requests
+func newLimitedTestServer(createLimit domain.RateLimit, bulkLimit domain.RateLimit) *httptest.Server {
+	mockCache := mock.NewImprovedMockCache()
+	linkService := services.NewLinkService(mock.NewMockLinkRepo(), mockCache, mock.NewMockHistoryRepo())
+	statsService := services.NewStatsService(mock.NewMockStatsRepo(), mock.NewMockCounterRepo(), mockCache)
+	apiKeyService := services.NewAPIKeyService(repository.NewMemoryAPIKeyRepository(), testAdminKey)
+
+	bulkQueue := queue.NewLocalBulkQueue()
+	bulkHandler := handlers.NewBulkGenerateFunctionHandler(linkService, NewTestPolicyService(), services.NewBulkJobService(repository.NewMemoryBulkJobRepository(), bulkQueue, time.Hour))
+	go bulkQueue.Run(context.Background(), bulkHandler.Process)
+
+	exportQueue := queue.NewLocalExportQueue()
+	exportHandler := handlers.NewExportFunctionHandler(linkService, statsService, services.NewExportService(mock.NewMockObjectStore(), exportQueue, time.Hour, time.Minute))
+	go exportQueue.Run(context.Background(), exportHandler.Process)
+
+	router := server.NewAPIRouter(server.Handlers{
+		Generate: handlers.NewGenerateLinkFunctionHandler(linkService, statsService, NewTestPolicyService()),
+		Bulk:     bulkHandler,
+		Import:   handlers.NewImportFunctionHandler(linkService, NewTestPolicyService()),
+		Redirect: handlers.NewRedirectFunctionHandler(linkService, statsService),
+		Stats:    handlers.NewStatsFunctionHandler(linkService, statsService, ""),
+		Export:   exportHandler,
+		Update:   handlers.NewUpdateLinkFunctionHandler(linkService, NewTestPolicyService()),
+		History:  handlers.NewHistoryFunctionHandler(linkService),
+		Delete:   handlers.NewDeleteFunctionHandler(linkService, 30*24*time.Hour),
+		APIKeys:  handlers.NewAPIKeyFunctionHandler(apiKeyService),
+		Auth:     handlers.NewAuthenticator(apiKeyService),
+
+		RateLimiter: handlers.NewRateLimiter(services.NewRateLimitService(cache.NewMemoryRateLimiter())),
+		CreateLimit: createLimit,
+		BulkLimit:   bulkLimit,
+	})
+	return httptest.NewServer(router)
+}
+
+func TestServerGenerateAndRedirect(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPut, srv.URL+"/generate", strings.NewReader(`{"long": "https://example.com/from-server"}`))
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var link domain.Link
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&link))
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err = client.Get(srv.URL + "/t/" + link.Id)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+	assert.Equal(t, "https://example.com/from-server", resp.Header.Get("Location"))
+}
+
+func TestServerBulkRateLimit(t *testing.T) {
+	srv := newLimitedTestServer(domain.RateLimit{Requests: 1, Window: time.Minute}, domain.RateLimit{Requests: 1, Window: time.Hour})
+	defer srv.Close()
+
+	send := func(method string, path string, body string) int {
+		req, _ := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+testAdminKey)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Bulk requests have their own bucket, a bulk request doesn't use up single creations or the other way round
+	assert.Equal(t, http.StatusOK, send(http.MethodPost, "/generate/bulk", `["https://example.com/bulk/1", "https://example.com/bulk/2"]`))
+	assert.Equal(t, http.StatusTooManyRequests, send(http.MethodPost, "/generate/bulk", `["https://example.com/bulk/3"]`))
+	// Imports share the bulk bucket
+	assert.Equal(t, http.StatusTooManyRequests, send(http.MethodPost, "/import", `[]`))
+	assert.Equal(t, http.StatusCreated, send(http.MethodPut, "/generate", `{"long": "https://example.com/single"}`))
+	assert.Equal(t, http.StatusTooManyRequests, send(http.MethodPut, "/generate", `{"long": "https://example.com/single"}`))
+}
+
+func TestServerRouting(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	tests := []struct {
+		method             string
+		path               string
+		expectedStatusCode int
+	}{
+		{method: http.MethodGet, path: "/stats", expectedStatusCode: http.StatusOK},
+		{method: http.MethodGet, path: "/stats/testid2", expectedStatusCode: http.StatusOK},
+		{method: http.MethodGet, path: "/stats/nonexistentid", expectedStatusCode: http.StatusNotFound},
+		{method: http.MethodDelete, path: "/delete/testid1", expectedStatusCode: http.StatusNoContent},
+		{method: http.MethodGet, path: "/t/nonexistentid", expectedStatusCode: http.StatusNotFound},
+		{method: http.MethodPost, path: "/stats", expectedStatusCode: http.StatusMethodNotAllowed},
+		{method: http.MethodGet, path: "/unknown", expectedStatusCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, srv.URL+tt.path, nil)
+			req.Header.Set("X-API-Key", testAdminKey)
+			resp, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+		})
+	}
+}
@@ -3,26 +3,31 @@ package unit
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/cache"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/repository"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
 	"github.com/dheeraj-vp/golang-url-shortener/internal/tests/mock"
 	"github.com/aws/aws-lambda-go/events"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStatsTest(t *testing.T) {
 	mockStatsRepo := mock.NewMockStatsRepo()
 	cache := cache.NewRedisCache("localhost:6379", "", 0)
-	statsService := services.NewStatsService(mockStatsRepo, cache)
+	statsService := services.NewStatsService(mockStatsRepo, mock.NewMockCounterRepo(), cache)
 
 	mockLinkRepo := mock.NewMockLinkRepo()
-	linkService := services.NewLinkService(mockLinkRepo, cache)
+	linkService := services.NewLinkService(mockLinkRepo, cache, mock.NewMockHistoryRepo())
 
-	apiHander := handlers.NewStatsFunctionHandler(linkService, statsService)
+	apiHander := handlers.NewStatsFunctionHandler(linkService, statsService, "")
 
 	t.Run("Stats Unit Test", func(t *testing.T) {
 		request := events.APIGatewayV2HTTPRequest{
@@ -34,12 +39,191 @@ func TestStatsTest(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		var links []domain.Link
-		err = json.Unmarshal([]byte(response.Body), &links)
+		var page handlers.StatsPage
+		err = json.Unmarshal([]byte(response.Body), &page)
 
 		assert.Nil(t, err)
 		assert.Equal(t, response.StatusCode, 200)
-		assert.Equal(t, len(links), 3)
+		assert.Equal(t, len(page.Links), 3)
+		assert.Empty(t, page.NextCursor)
 	})
 
 }
+
+func TestLinkStatsTimeRange(t *testing.T) {
+	mockCache := mock.NewImprovedMockCache()
+	statsService := services.NewStatsService(mock.NewMockStatsRepo(), mock.NewMockCounterRepo(), mockCache)
+	linkService := services.NewLinkService(mock.NewMockLinkRepo(), mockCache, mock.NewMockHistoryRepo())
+	apiHandler := handlers.NewStatsFunctionHandler(linkService, statsService, "")
+
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, stat := range []domain.Stats{
+		{Id: "s1", LinkID: "testid1", Platform: domain.PlatformTwitter, CreatedAt: day.Add(-time.Hour)},
+		{Id: "s2", LinkID: "testid1", Platform: domain.PlatformTwitter, CreatedAt: day.Add(time.Hour)},
+		{Id: "s3", LinkID: "testid1", Platform: domain.PlatformInstagram, CreatedAt: day.Add(25 * time.Hour)},
+		{Id: "s4", LinkID: "testid2", Platform: domain.PlatformTwitter, CreatedAt: day.Add(time.Hour)},
+	} {
+		assert.NoError(t, statsService.Create(context.Background(), stat))
+	}
+
+	tests := []struct {
+		name               string
+		query              map[string]string
+		expectedStatusCode int
+		expectedClicks     int64
+		expectedBuckets    int
+	}{
+		{name: "no range", query: nil, expectedStatusCode: 200, expectedClicks: 3, expectedBuckets: 3},
+		{name: "from only", query: map[string]string{"from": "2024-03-01T00:00:00Z"}, expectedStatusCode: 200, expectedClicks: 2, expectedBuckets: 2},
+		{name: "from and to", query: map[string]string{"from": "2024-03-01T00:00:00Z", "to": "2024-03-02T00:00:00Z"}, expectedStatusCode: 200, expectedClicks: 1, expectedBuckets: 1},
+		// Counters can't split a bucket, a range ending inside one counts all of its clicks
+		{name: "to inside a bucket", query: map[string]string{"from": "2024-03-01T00:00:00Z", "to": "2024-03-02T00:30:00Z"}, expectedStatusCode: 200, expectedClicks: 2, expectedBuckets: 2},
+		{name: "hourly", query: map[string]string{"granularity": "hour", "from": "2024-03-01T00:00:00Z"}, expectedStatusCode: 200, expectedClicks: 2, expectedBuckets: 2},
+		{name: "invalid granularity", query: map[string]string{"granularity": "week"}, expectedStatusCode: 400},
+		{name: "invalid from", query: map[string]string{"from": "yesterday"}, expectedStatusCode: 400},
+		{name: "from after to", query: map[string]string{"from": "2024-03-02T00:00:00Z", "to": "2024-03-01T00:00:00Z"}, expectedStatusCode: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayV2HTTPRequest{
+				PathParameters:        map[string]string{"id": "testid1"},
+				QueryStringParameters: tt.query,
+			}
+
+			response, err := apiHandler.GetLinkStats(context.Background(), request)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, response.StatusCode)
+
+			if tt.expectedStatusCode == 200 {
+				var link domain.Link
+				assert.NoError(t, json.Unmarshal([]byte(response.Body), &link))
+				assert.Equal(t, "testid1", link.Id)
+				assert.Equal(t, "https://example.com/link1", link.OriginalURL)
+				assert.Equal(t, tt.expectedClicks, link.Clicks.Total)
+				assert.Len(t, link.Clicks.Series, tt.expectedBuckets)
+			}
+		})
+	}
+}
+
+func TestLinkStatsUnknownLink(t *testing.T) {
+	mockCache := mock.NewImprovedMockCache()
+	statsService := services.NewStatsService(mock.NewMockStatsRepo(), mock.NewMockCounterRepo(), mockCache)
+	linkService := services.NewLinkService(mock.NewMockLinkRepo(), mockCache, mock.NewMockHistoryRepo())
+	apiHandler := handlers.NewStatsFunctionHandler(linkService, statsService, "")
+
+	response, err := apiHandler.GetLinkStats(context.Background(), events.APIGatewayV2HTTPRequest{
+		PathParameters: map[string]string{"id": "missing"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 404, response.StatusCode)
+}
+
+func TestStatsListsClickTotals(t *testing.T) {
+	mockCache := mock.NewImprovedMockCache()
+	statsService := services.NewStatsService(mock.NewMockStatsRepo(), mock.NewMockCounterRepo(), mockCache)
+	linkService := services.NewLinkService(mock.NewMockLinkRepo(), mockCache, mock.NewMockHistoryRepo())
+	apiHandler := handlers.NewStatsFunctionHandler(linkService, statsService, "")
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, statsService.Create(context.Background(), domain.Stats{
+			Id:        "click" + string(rune('a'+i)),
+			LinkID:    "testid2",
+			Platform:  domain.PlatformYouTube,
+			CreatedAt: time.Now(),
+		}))
+	}
+
+	response, err := apiHandler.Stats(context.Background(), events.APIGatewayV2HTTPRequest{RawPath: "/stats"})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	var page handlers.StatsPage
+	assert.NoError(t, json.Unmarshal([]byte(response.Body), &page))
+	assert.Len(t, page.Links, 3)
+	for _, link := range page.Links {
+		if link.Id == "testid2" {
+			assert.Equal(t, int64(3), link.Clicks.Total)
+			assert.Equal(t, int64(3), link.Clicks.Platforms["YouTube"])
+			assert.Nil(t, link.Clicks.Series)
+		} else {
+			assert.Equal(t, int64(0), link.Clicks.Total)
+		}
+	}
+}
+
+func TestStatsPagination(t *testing.T) {
+	mockCache := mock.NewImprovedMockCache()
+	statsService := services.NewStatsService(mock.NewMockStatsRepo(), mock.NewMockCounterRepo(), mockCache)
+	linkService := services.NewLinkService(mock.NewMockLinkRepo(), mockCache, mock.NewMockHistoryRepo())
+	apiHandler := handlers.NewStatsFunctionHandler(linkService, statsService, "")
+
+	getPage := func(query map[string]string) (events.APIGatewayProxyResponse, handlers.StatsPage) {
+		response, err := apiHandler.Stats(context.Background(), events.APIGatewayV2HTTPRequest{QueryStringParameters: query})
+		assert.NoError(t, err)
+
+		var page handlers.StatsPage
+		if response.StatusCode == 200 {
+			assert.NoError(t, json.Unmarshal([]byte(response.Body), &page))
+		}
+		return response, page
+	}
+
+	response, first := getPage(map[string]string{"limit": "2"})
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Len(t, first.Links, 2)
+	assert.NotEmpty(t, first.NextCursor)
+
+	response, second := getPage(map[string]string{"limit": "2", "cursor": first.NextCursor})
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Len(t, second.Links, 1)
+	assert.Equal(t, "testid3", second.Links[0].Id)
+	assert.Empty(t, second.NextCursor)
+
+	// Cursors are signed, a forged start key is rejected
+	tampered := strings.Replace(first.NextCursor, first.NextCursor[:1], "X", 1)
+	response, _ = getPage(map[string]string{"cursor": tampered})
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Equal(t, "Invalid cursor", response.Body)
+
+	response, _ = getPage(map[string]string{"limit": "0"})
+	assert.Equal(t, 400, response.StatusCode)
+	response, _ = getPage(map[string]string{"limit": "1000"})
+	assert.Equal(t, 400, response.StatusCode)
+}
+
+func TestLinkStatsKeyConditionBounds(t *testing.T) {
+	cest := time.FixedZone("CEST", 2*60*60)
+	est := time.FixedZone("EST", -5*60*60)
+	from := time.Date(2024, 3, 1, 5, 0, 0, 0, est) // 10:00:00Z
+	to := time.Date(2024, 3, 1, 12, 0, 1, 0, cest) // 10:00:01Z
+
+	_, values := repository.LinkStatsKeyCondition("link1", from, to)
+	lower := values[":from"].(*ddbtypes.AttributeValueMemberS).Value
+	upper := values[":to"].(*ddbtypes.AttributeValueMemberS).Value
+
+	tests := []struct {
+		name      string
+		createdAt time.Time
+		matched   bool
+	}{
+		{name: "just before", createdAt: time.Date(2024, 3, 1, 11, 59, 59, 999000000, cest), matched: false},
+		{name: "from", createdAt: from, matched: true},
+		{name: "fraction after from", createdAt: time.Date(2024, 3, 1, 10, 0, 0, 500000000, time.UTC), matched: true},
+		{name: "fraction in another zone", createdAt: time.Date(2024, 3, 1, 12, 0, 0, 750000000, cest), matched: true},
+		{name: "to", createdAt: to, matched: true},
+		// The second of to is covered whole, the rest is filtered out after the query
+		{name: "fraction after to", createdAt: time.Date(2024, 3, 1, 10, 0, 1, 500000000, time.UTC), matched: true},
+		{name: "second after to", createdAt: time.Date(2024, 3, 1, 12, 0, 2, 0, cest), matched: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := repository.MarshalStats(domain.Stats{Id: "1", LinkID: "link1", CreatedAt: tt.createdAt})
+			require.NoError(t, err)
+			createdAt := item["created_at"].(*ddbtypes.AttributeValueMemberS).Value
+			assert.True(t, strings.HasSuffix(createdAt, "Z"), createdAt)
+			assert.Equal(t, tt.matched, lower <= createdAt && createdAt <= upper, "%s BETWEEN %s AND %s", createdAt, lower, upper)
+		})
+	}
+}
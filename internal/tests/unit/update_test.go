@@ -0,0 +1,121 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/services"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/tests/mock"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalLinkRepositoriesUpdate(t *testing.T) {
+	ctx := context.Background()
+
+	for _, repos := range newLocalRepositories(t) {
+		t.Run(repos.name, func(t *testing.T) {
+			link := domain.Link{Id: "edit1", OwnerID: "team", OriginalURL: "https://example.com/typo", Version: 1}
+			require.NoError(t, repos.links.Create(ctx, link))
+			require.NoError(t, repos.links.IncrementClicks(ctx, "edit1"))
+
+			updated := link
+			updated.OriginalURL = "https://example.com/fixed"
+			updated.PreviousURL = link.OriginalURL
+			updated.Version = 2
+			require.NoError(t, repos.links.Update(ctx, updated, 1))
+
+			got, err := repos.links.Get(ctx, "edit1")
+			require.NoError(t, err)
+			assert.Equal(t, "https://example.com/fixed", got.OriginalURL)
+			assert.Equal(t, "https://example.com/typo", got.PreviousURL)
+			assert.Equal(t, int64(2), got.Version)
+			assert.Equal(t, int64(1), got.ClickCount, "updates must not reset click counts")
+
+			// A second writer that read version 1 loses
+			err = repos.links.Update(ctx, updated, 1)
+			assert.True(t, errors.Is(err, domain.ErrVersionConflict), "got %v", err)
+
+			// Another workspace's link doesn't exist for the caller
+			updated.OwnerID = "other"
+			err = repos.links.Update(ctx, updated, 2)
+			assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+
+			updated.Id = "missing"
+			err = repos.links.Update(ctx, updated, 0)
+			assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+		})
+	}
+}
+
+func TestUpdateLinkUnit(t *testing.T) {
+	mockCache := mock.NewImprovedMockCache()
+	linkService := services.NewLinkService(mock.NewMockLinkRepo(), mockCache, mock.NewMockHistoryRepo())
+	apiHandler := handlers.NewUpdateLinkFunctionHandler(linkService, NewTestPolicyService())
+	ctx := handlers.WithAPIKey(context.Background(), domain.APIKey{Id: services.BootstrapKeyID})
+
+	require.NoError(t, mockCache.Set(ctx, "testid1", "https://example.com/link1"))
+
+	tests := []struct {
+		name               string
+		id                 string
+		body               string
+		expectedStatusCode int
+	}{
+		{name: "missing version", id: "testid1", body: `{"long": "https://example.com/new"}`, expectedStatusCode: http.StatusBadRequest},
+		{name: "invalid destination", id: "testid1", body: `{"long": "http://localhost/admin", "version": 0}`, expectedStatusCode: http.StatusBadRequest},
+		{name: "unknown link", id: "nonexistentid", body: `{"long": "https://example.com/new", "version": 0}`, expectedStatusCode: http.StatusNotFound},
+		{name: "update", id: "testid1", body: `{"long": "https://example.com/new", "version": 0}`, expectedStatusCode: http.StatusOK},
+		{name: "stale version", id: "testid1", body: `{"long": "https://example.com/newer", "version": 0}`, expectedStatusCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayV2HTTPRequest{Body: tt.body, PathParameters: map[string]string{"id": tt.id}}
+			response, err := apiHandler.Update(ctx, request)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, response.StatusCode, response.Body)
+		})
+	}
+
+	link, err := linkService.Get(ctx, "testid1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/new", link.OriginalURL)
+	assert.Equal(t, "https://example.com/link1", link.PreviousURL)
+	assert.Equal(t, int64(1), link.Version)
+	assert.Equal(t, services.BootstrapKeyID, link.UpdatedBy)
+	require.NotNil(t, link.UpdatedAt)
+	assert.WithinDuration(t, time.Now(), *link.UpdatedAt, time.Minute)
+
+	// The old destination must not be served from the cache
+	_, err = mockCache.Get(ctx, "testid1")
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "got %v", err)
+	url, err := linkService.GetOriginalURL(ctx, "testid1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/new", *url)
+}
+
+func TestServerUpdateLink(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPatch, srv.URL+"/links/testid2", strings.NewReader(`{"long": "https://example.com/moved", "version": 0}`))
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var link domain.Link
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&link))
+	assert.Equal(t, "https://example.com/moved", link.OriginalURL)
+	assert.Equal(t, int64(1), link.Version)
+}
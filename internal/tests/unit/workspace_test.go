@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dheeraj-vp/golang-url-shortener/internal/adapters/handlers"
+	"github.com/dheeraj-vp/golang-url-shortener/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalLinkRepositoriesByOwner(t *testing.T) {
+	ctx := context.Background()
+
+	for _, repos := range newLocalRepositories(t) {
+		t.Run(repos.name, func(t *testing.T) {
+			for i, owner := range []string{"team-a", "team-b", "team-a", ""} {
+				require.NoError(t, repos.links.Create(ctx, domain.Link{Id: fmt.Sprintf("owned%d", i), OwnerID: owner, OriginalURL: "https://example.com/owned"}))
+			}
+
+			links, _, err := repos.links.AllByOwner(ctx, "team-a", 10, "")
+			assert.NoError(t, err)
+			assert.Len(t, links, 2)
+			for _, link := range links {
+				assert.Equal(t, "team-a", link.OwnerID)
+			}
+
+			legacy, _, err := repos.links.AllByOwner(ctx, "", 10, "")
+			assert.NoError(t, err)
+			assert.Len(t, legacy, 1)
+
+			err = repos.links.Delete(ctx, "owned0", "team-b")
+			assert.True(t, errors.Is(err, domain.ErrNotFound))
+			assert.NoError(t, repos.links.Delete(ctx, "owned0", "team-a"))
+		})
+	}
+}
+
+func TestServerWorkspaceIsolation(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	do := func(method string, path string, token string, body string) *http.Response {
+		req, _ := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	createKey := func(workspace string) string {
+		resp := do(http.MethodPost, "/admin/keys", testAdminKey, `{"name": "`+workspace+`", "workspace": "`+workspace+`", "scopes": ["create", "read-stats", "delete"]}`)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var created handlers.CreateAPIKeyResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		return created.Token
+	}
+	listLinks := func(token string, query string) handlers.StatsPage {
+		resp := do(http.MethodGet, "/stats"+query, token, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var page handlers.StatsPage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		return page
+	}
+
+	teamA, teamB := createKey("team-a"), createKey("team-b")
+	for i := 0; i < 2; i++ {
+		require.Equal(t, http.StatusCreated, do(http.MethodPut, "/generate", teamA, `{"long": "https://example.com/team-a"}`).StatusCode)
+	}
+
+	page := listLinks(teamA, "?limit=1")
+	require.Len(t, page.Links, 1)
+	assert.Equal(t, "team-a", page.Links[0].OwnerID)
+	require.NotEmpty(t, page.NextCursor)
+	linkID := page.Links[0].Id
+
+	assert.Empty(t, listLinks(teamB, "").Links)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodGet, "/stats?cursor="+page.NextCursor, teamB, "").StatusCode)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/stats/"+linkID, teamB, "").StatusCode)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/delete/"+linkID, teamB, "").StatusCode)
+
+	// The bootstrap key works on the links created before workspaces
+	assert.Len(t, listLinks(testAdminKey, "").Links, 3)
+
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/stats/"+linkID, teamA, "").StatusCode)
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/delete/"+linkID, teamA, "").StatusCode)
+
+	// Deleted links stay listed with their stats until they are purged
+	links := listLinks(teamA, "").Links
+	require.Len(t, links, 2)
+	deleted := 0
+	for _, link := range links {
+		if link.IsDeleted() {
+			deleted++
+		}
+	}
+	assert.Equal(t, 1, deleted)
+}